@@ -0,0 +1,50 @@
+package httpbinding
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestAuthorizationPlaceholder(t *testing.T) {
+	encoder, err := NewEncoder("/", "", http.Header{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	encoder.SetAuthorizationPlaceholder("AWS4-HMAC-SHA256")
+	encoder.SetHeader("X-Amz-Date").String("20200101T000000Z")
+
+	req, err := encoder.Encode(&http.Request{URL: &url.URL{}})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if e, a := "AWS4-HMAC-SHA256 smithy-authorization-placeholder", req.Header.Get("Authorization"); e != a {
+		t.Errorf("expect %q placeholder, got %q", e, a)
+	}
+	if !IsAuthorizationPlaceholder(req.Header) {
+		t.Errorf("expect placeholder")
+	}
+
+	// signer overwrites the placeholder
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential=AKID/20200101/us-west-2/svc/aws4_request")
+
+	if IsAuthorizationPlaceholder(req.Header) {
+		t.Errorf("expect placeholder to be replaced")
+	}
+	RemoveAuthorizationPlaceholder(req.Header)
+	if e, a := "AWS4-HMAC-SHA256 Credential=AKID/20200101/us-west-2/svc/aws4_request", req.Header.Get("Authorization"); e != a {
+		t.Errorf("expect %q signed value, got %q", e, a)
+	}
+}
+
+func TestRemoveAuthorizationPlaceholder(t *testing.T) {
+	header := http.Header{}
+	SetAuthorizationPlaceholder(header, "Bearer")
+
+	RemoveAuthorizationPlaceholder(header)
+	if v, ok := header["Authorization"]; ok {
+		t.Errorf("expect placeholder removed, got %v", v)
+	}
+}
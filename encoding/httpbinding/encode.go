@@ -116,6 +116,13 @@ func (e *Encoder) AddQuery(key string) QueryValue {
 	return NewQueryValue(e.query, key, true)
 }
 
+// QueryParams returns a QueryParams used for encoding a map bound to the
+// full query string. Must be used after all explicitly bound query keys are
+// encoded, for those keys to take precedence.
+func (e *Encoder) QueryParams() QueryParams {
+	return NewQueryParams(e.query)
+}
+
 // HasQuery returns if a query with the key specified exists with one or
 // more values.
 func (e *Encoder) HasQuery(key string) bool {
@@ -7,6 +7,9 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
+
+	smithytime "github.com/aws/smithy-go/time"
 )
 
 // Headers is used to encode header keys using a provided prefix
@@ -115,8 +118,35 @@ func (h HeaderValue) BigDecimal(v *big.Float) {
 	h.modifyHeader(v.Text('e', -1))
 }
 
-// Blob encodes the value v as a base64 header string value
+// Blob encodes the value v as a base64 header string value, using the
+// standard base64 encoding with padding. An empty v encodes as an empty
+// header value.
 func (h HeaderValue) Blob(v []byte) {
 	encodeToString := base64.StdEncoding.EncodeToString(v)
 	h.modifyHeader(encodeToString)
 }
+
+// Smithy timestamp formats, of the timestampFormat trait, supported by
+// TimeList.
+const (
+	TimestampFormatDateTime     = smithytime.DateTimeFormatName
+	TimestampFormatHTTPDate     = smithytime.HTTPDateFormatName
+	TimestampFormatEpochSeconds = smithytime.EpochSecondsFormatName
+)
+
+// TimeList encodes the list of timestamps as a single header value, with each
+// timestamp formatted in the Smithy timestamp format, and joined with ", ".
+// Returns an error if the format is not supported.
+func (h HeaderValue) TimeList(ts []time.Time, format string) error {
+	values := make([]string, len(ts))
+	for i, t := range ts {
+		v, err := smithytime.FormatTimestamp(t, format)
+		if err != nil {
+			return err
+		}
+		values[i] = v
+	}
+	h.modifyHeader(strings.Join(values, ", "))
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package httpbinding
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	smithytime "github.com/aws/smithy-go/time"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	bigIntType    = reflect.TypeOf((*big.Int)(nil))
+	bigFloatType  = reflect.TypeOf((*big.Float)(nil))
+	byteSliceType = reflect.TypeOf([]byte(nil))
+)
+
+// SetHeaderFields sets the headers bound to the fields of the structure v.
+// bindings maps the name of each bound field to its header name. Nested
+// fields are named by their path of field names joined with ".", (e.g.
+// "Config.Region"). Fields that are nil, or nested in a nil structure
+// pointer, are skipped.
+//
+// Field values are encoded as with the HeaderValue methods. Timestamps are
+// encoded as http-date, and lists as a repeated header value per element. A
+// map field with string keys is bound with its header name as a prefix,
+// setting a header per map entry named prefix+key, as with Headers.
+//
+// Returns an error if v is not a structure, or pointer to one, a bound field
+// does not exist, or its type cannot be encoded as a header.
+func (e *Encoder) SetHeaderFields(v interface{}, bindings map[string]string) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("header fields value must be a structure, got %T", v)
+	}
+
+	fields := make([]string, 0, len(bindings))
+	for field := range bindings {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		fv, ok, err := lookupField(rv, field)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		header := bindings[field]
+		if fv.Kind() == reflect.Map {
+			err = encodePrefixHeaders(e.Headers(header), fv)
+		} else {
+			err = encodeHeaderValue(e.SetHeader(header), fv)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to encode field %s as header, %w", field, err)
+		}
+	}
+
+	return nil
+}
+
+// lookupField returns the value of the field at the dot-separated path.
+// Returns false if the field, or a structure it is nested in, is nil.
+func lookupField(rv reflect.Value, path string) (reflect.Value, bool, error) {
+	for _, name := range strings.Split(path, ".") {
+		for rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return rv, false, nil
+			}
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct {
+			return rv, false, fmt.Errorf("header field %s is not nested in a structure", path)
+		}
+
+		rv = rv.FieldByName(name)
+		if !rv.IsValid() {
+			return rv, false, fmt.Errorf("header field %s not found", path)
+		}
+	}
+
+	if isNilValue(rv) {
+		return rv, false, nil
+	}
+	return rv, true, nil
+}
+
+func isNilValue(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
+
+func encodePrefixHeaders(h Headers, rv reflect.Value) error {
+	if rv.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("prefix headers map key must be a string, got %v", rv.Type().Key())
+	}
+
+	for _, key := range rv.MapKeys() {
+		entry := rv.MapIndex(key)
+		if isNilValue(entry) {
+			continue
+		}
+		if err := encodeHeaderValue(h.SetHeader(key.String()), entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeHeaderValue(hv HeaderValue, rv reflect.Value) error {
+	for rv.Kind() == reflect.Interface {
+		rv = rv.Elem()
+	}
+
+	switch rv.Type() {
+	case bigIntType:
+		hv.BigInteger(rv.Interface().(*big.Int))
+		return nil
+	case bigFloatType:
+		hv.BigDecimal(rv.Interface().(*big.Float))
+		return nil
+	case byteSliceType:
+		hv.Blob(rv.Bytes())
+		return nil
+	}
+
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+
+	if rv.Type() == timeType {
+		hv.String(smithytime.FormatHTTPDate(rv.Interface().(time.Time)))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		hv.String(rv.String())
+	case reflect.Bool:
+		hv.Boolean(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		hv.Long(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		hv.String(strconv.FormatUint(rv.Uint(), 10))
+	case reflect.Float32:
+		hv.Float(float32(rv.Float()))
+	case reflect.Float64:
+		hv.Double(rv.Float())
+	case reflect.Slice, reflect.Array:
+		if !hv.append {
+			delete(hv.header, hv.key)
+			hv.append = true
+		}
+		for i := 0; i < rv.Len(); i++ {
+			elem := rv.Index(i)
+			if isNilValue(elem) {
+				continue
+			}
+			if k := elem.Kind(); (k == reflect.Slice || k == reflect.Array) && elem.Type() != byteSliceType {
+				return fmt.Errorf("nested list cannot be encoded as header")
+			}
+			if err := encodeHeaderValue(hv, elem); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported header value type %v", rv.Type())
+	}
+
+	return nil
+}
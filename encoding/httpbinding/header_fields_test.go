@@ -0,0 +1,181 @@
+package httpbinding
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type headerFieldsConfig struct {
+	Region *string
+	Retry  *int32
+}
+
+type headerFieldsInput struct {
+	RequestID *string
+	Count     *int64
+	Enabled   *bool
+	Ratio     *float64
+	Checksum  []byte
+	Tags      []string
+	Since     *time.Time
+	Meta      map[string]string
+	Config    *headerFieldsConfig
+	Ignored   *string
+}
+
+var headerFieldsBindings = map[string]string{
+	"RequestID":     "x-request-id",
+	"Count":         "x-count",
+	"Enabled":       "x-enabled",
+	"Ratio":         "x-ratio",
+	"Checksum":      "x-checksum",
+	"Tags":          "x-tags",
+	"Since":         "x-since",
+	"Meta":          "x-meta-",
+	"Config.Region": "x-config-region",
+	"Config.Retry":  "x-config-retry",
+}
+
+func TestEncoderSetHeaderFields(t *testing.T) {
+	ptrString := func(v string) *string { return &v }
+	ptrInt32 := func(v int32) *int32 { return &v }
+	ptrInt64 := func(v int64) *int64 { return &v }
+	ptrBool := func(v bool) *bool { return &v }
+	ptrFloat64 := func(v float64) *float64 { return &v }
+	ptrTime := func(v time.Time) *time.Time { return &v }
+
+	cases := map[string]struct {
+		Input    interface{}
+		Header   http.Header
+		Expected http.Header
+	}{
+		"all fields set": {
+			Input: &headerFieldsInput{
+				RequestID: ptrString("abc123"),
+				Count:     ptrInt64(42),
+				Enabled:   ptrBool(true),
+				Ratio:     ptrFloat64(0.5),
+				Checksum:  []byte("baz"),
+				Tags:      []string{"a", "b"},
+				Since:     ptrTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)),
+				Meta:      map[string]string{"foo": "bar"},
+				Config: &headerFieldsConfig{
+					Region: ptrString("us-west-2"),
+					Retry:  ptrInt32(3),
+				},
+				Ignored: ptrString("ignored"),
+			},
+			Expected: http.Header{
+				"x-request-id":    {"abc123"},
+				"x-count":         {"42"},
+				"x-enabled":       {"true"},
+				"x-ratio":         {"0.5"},
+				"x-checksum":      {"YmF6"},
+				"x-tags":          {"a", "b"},
+				"x-since":         {"Thu, 02 Jan 2020 03:04:05 GMT"},
+				"x-meta-foo":      {"bar"},
+				"x-config-region": {"us-west-2"},
+				"x-config-retry":  {"3"},
+			},
+		},
+		"only set fields": {
+			Input: headerFieldsInput{
+				Count: ptrInt64(0),
+				Config: &headerFieldsConfig{
+					Region: ptrString("us-west-2"),
+				},
+			},
+			Expected: http.Header{
+				"x-count":         {"0"},
+				"x-config-region": {"us-west-2"},
+			},
+		},
+		"nil nested structure": {
+			Input: &headerFieldsInput{
+				RequestID: ptrString("abc123"),
+			},
+			Expected: http.Header{
+				"x-request-id": {"abc123"},
+			},
+		},
+		"no fields set": {
+			Input:    &headerFieldsInput{},
+			Expected: http.Header{},
+		},
+		"replaces existing values": {
+			Input: &headerFieldsInput{
+				RequestID: ptrString("abc123"),
+				Tags:      []string{"a"},
+			},
+			Header: http.Header{
+				"x-request-id": {"old"},
+				"x-tags":       {"old1", "old2"},
+			},
+			Expected: http.Header{
+				"x-request-id": {"abc123"},
+				"x-tags":       {"a"},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			header := c.Header
+			if header == nil {
+				header = http.Header{}
+			}
+
+			encoder, err := NewEncoder("/", "", header)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if err := encoder.SetHeaderFields(c.Input, headerFieldsBindings); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.Expected, encoder.header; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v headers, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestEncoderSetHeaderFields_Errors(t *testing.T) {
+	cases := map[string]struct {
+		Input    interface{}
+		Bindings map[string]string
+	}{
+		"not a structure": {
+			Input:    "foo",
+			Bindings: map[string]string{"Foo": "x-foo"},
+		},
+		"unknown field": {
+			Input:    &headerFieldsInput{},
+			Bindings: map[string]string{"Unknown": "x-unknown"},
+		},
+		"not nested in a structure": {
+			Input:    &headerFieldsInput{Tags: []string{"a"}},
+			Bindings: map[string]string{"Tags.Foo": "x-foo"},
+		},
+		"unsupported type": {
+			Input:    &struct{ Config headerFieldsConfig }{},
+			Bindings: map[string]string{"Config": "x-config"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			encoder, err := NewEncoder("/", "", http.Header{})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if err := encoder.SetHeaderFields(c.Input, c.Bindings); err == nil {
+				t.Fatalf("expect error, got none")
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestHeaderValue(t *testing.T) {
@@ -25,6 +26,20 @@ func TestHeaderValue(t *testing.T) {
 				expectedKeyName: {"YmF6"},
 			},
 		},
+		"set blob standard encoding": {
+			header: http.Header{},
+			args:   []interface{}{[]byte{0xfb, 0xff, 0xfe, 0x00}},
+			expected: map[string][]string{
+				expectedKeyName: {"+//+AA=="},
+			},
+		},
+		"set empty blob": {
+			header: http.Header{expectedKeyName: []string{"foobar"}},
+			args:   []interface{}{[]byte{}},
+			expected: map[string][]string{
+				expectedKeyName: {""},
+			},
+		},
 		"set boolean": {
 			header: http.Header{expectedKeyName: []string{"foobar"}},
 			args:   []interface{}{true},
@@ -263,6 +278,61 @@ func TestHeaders(t *testing.T) {
 	}
 }
 
+func TestHeaderValue_TimeList(t *testing.T) {
+	ts := []time.Time{
+		time.Date(2023, 6, 1, 12, 30, 45, 0, time.UTC),
+		time.Date(2023, 6, 2, 8, 0, 0, 500000000, time.UTC),
+	}
+
+	cases := map[string]struct {
+		format    string
+		expected  http.Header
+		expectErr bool
+	}{
+		"date-time": {
+			format: TimestampFormatDateTime,
+			expected: http.Header{
+				"X-Times": {"2023-06-01T12:30:45Z, 2023-06-02T08:00:00.5Z"},
+			},
+		},
+		"http-date": {
+			format: TimestampFormatHTTPDate,
+			expected: http.Header{
+				"X-Times": {"Thu, 01 Jun 2023 12:30:45 GMT, Fri, 02 Jun 2023 08:00:00 GMT"},
+			},
+		},
+		"epoch-seconds": {
+			format: TimestampFormatEpochSeconds,
+			expected: http.Header{
+				"X-Times": {"1685622645, 1685692800.5"},
+			},
+		},
+		"unsupported format": {
+			format:    "unix",
+			expected:  http.Header{},
+			expectErr: true,
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			err := newHeaderValue(header, "X-Times", false).TimeList(ts, tt.format)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected error, got none")
+				}
+			} else if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if e, a := tt.expected, header; !reflect.DeepEqual(e, a) {
+				t.Errorf("expected %v, got %v", e, a)
+			}
+		})
+	}
+}
+
 func setHeader(hv HeaderValue, args []interface{}) error {
 	value := args[0]
 
@@ -2,6 +2,7 @@ package httpbinding
 
 import (
 	"encoding/base64"
+	"fmt"
 	"math"
 	"math/big"
 	"net/url"
@@ -49,6 +50,29 @@ func (qv QueryValue) String(v string) {
 	qv.updateKey(v)
 }
 
+// StringList encodes each element of v as a repeated query string value,
+// (e.g. key=a&key=b&key=c). Existing values of the query key are replaced,
+// unless the QueryValue appends.
+func (qv QueryValue) StringList(v []string) {
+	if !qv.append {
+		qv.query.Del(qv.key)
+	}
+	for _, s := range v {
+		qv.query.Add(qv.key, s)
+	}
+}
+
+// StringerList encodes each element of v as a repeated query string value of
+// the element's String method, as with StringList. Allows a list of enum
+// values to be encoded as their string values.
+func (qv QueryValue) StringerList(v []fmt.Stringer) {
+	values := make([]string, len(v))
+	for i, s := range v {
+		values[i] = s.String()
+	}
+	qv.StringList(values)
+}
+
 // Byte encodes v as a query string value
 func (qv QueryValue) Byte(v int8) {
 	qv.Long(int64(v))
@@ -105,3 +129,46 @@ func (qv QueryValue) BigDecimal(v *big.Float) {
 	}
 	qv.updateKey(v.Text('e', -1))
 }
+
+// QueryParams is used to encode a map bound to the full query string, with
+// each map entry encoded as its own query key value pair. Keys that are
+// already bound explicitly in the query are skipped, since explicitly bound
+// query values take precedence over loose query params.
+type QueryParams struct {
+	query url.Values
+}
+
+// NewQueryParams creates a new QueryParams which enables encoding a map of
+// query key values into the given url.Values.
+func NewQueryParams(query url.Values) QueryParams {
+	return QueryParams{
+		query: query,
+	}
+}
+
+func (qp QueryParams) isBound(key string) bool {
+	return len(qp.query[key]) != 0
+}
+
+// StringMap encodes each entry of v as a query key value pair.
+func (qp QueryParams) StringMap(v map[string]string) {
+	for key, value := range v {
+		if qp.isBound(key) {
+			continue
+		}
+		qp.query.Set(key, value)
+	}
+}
+
+// StringListMap encodes each value of each entry of v as a query key value
+// pair.
+func (qp QueryParams) StringListMap(v map[string][]string) {
+	for key, values := range v {
+		if qp.isBound(key) {
+			continue
+		}
+		for _, value := range values {
+			qp.query.Add(key, value)
+		}
+	}
+}
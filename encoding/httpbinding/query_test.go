@@ -3,6 +3,7 @@ package httpbinding
 import (
 	"fmt"
 	"math/big"
+	"net/http"
 	"net/url"
 	"reflect"
 	"testing"
@@ -232,3 +233,137 @@ func setQueryValue(qv QueryValue, args []interface{}) error {
 		return fmt.Errorf("unhandled query value type")
 	}
 }
+
+func TestQueryParams(t *testing.T) {
+	cases := map[string]struct {
+		values        url.Values
+		stringMap     map[string]string
+		stringListMap map[string][]string
+		expected      string
+	}{
+		"string map": {
+			values: url.Values{},
+			stringMap: map[string]string{
+				"foo":        "bar",
+				"with space": "a&b=c",
+				"unicode":    "ü",
+			},
+			expected: "foo=bar&unicode=%C3%BC&with+space=a%26b%3Dc",
+		},
+		"string list map": {
+			values: url.Values{},
+			stringListMap: map[string][]string{
+				"foo": {"bar", "baz"},
+				"qux": {"a/b"},
+			},
+			expected: "foo=bar&foo=baz&qux=a%2Fb",
+		},
+		"skips explicitly bound": {
+			values: url.Values{"foo": {"explicit"}},
+			stringMap: map[string]string{
+				"foo": "loose",
+				"bar": "loose",
+			},
+			stringListMap: map[string][]string{
+				"foo": {"loose"},
+			},
+			expected: "bar=loose&foo=explicit",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			qp := NewQueryParams(c.values)
+			if c.stringMap != nil {
+				qp.StringMap(c.stringMap)
+			}
+			if c.stringListMap != nil {
+				qp.StringListMap(c.stringListMap)
+			}
+
+			if e, a := c.expected, c.values.Encode(); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+type mockQueryEnum string
+
+func (e mockQueryEnum) String() string { return string(e) }
+
+func TestQueryValue_StringList(t *testing.T) {
+	const queryKey = "status"
+
+	enums := []fmt.Stringer{
+		mockQueryEnum("PENDING"),
+		mockQueryEnum("RUNNING"),
+		mockQueryEnum("FAILED"),
+	}
+
+	cases := map[string]struct {
+		values   url.Values
+		append   bool
+		encode   func(QueryValue)
+		expected url.Values
+	}{
+		"set enum list": {
+			values: url.Values{queryKey: {"STOPPED"}},
+			encode: func(qv QueryValue) { qv.StringerList(enums) },
+			expected: url.Values{
+				queryKey: {"PENDING", "RUNNING", "FAILED"},
+			},
+		},
+		"add enum list": {
+			values: url.Values{queryKey: {"STOPPED"}},
+			append: true,
+			encode: func(qv QueryValue) { qv.StringerList(enums) },
+			expected: url.Values{
+				queryKey: {"STOPPED", "PENDING", "RUNNING", "FAILED"},
+			},
+		},
+		"set string list": {
+			values: url.Values{"other": {"value"}},
+			encode: func(qv QueryValue) { qv.StringList([]string{"a", "b", "c"}) },
+			expected: url.Values{
+				"other":  {"value"},
+				queryKey: {"a", "b", "c"},
+			},
+		},
+		"set empty list": {
+			values:   url.Values{queryKey: {"STOPPED"}},
+			encode:   func(qv QueryValue) { qv.StringList(nil) },
+			expected: url.Values{},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			c.encode(NewQueryValue(c.values, queryKey, c.append))
+
+			if e, a := c.expected, c.values; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestEncoder_QueryStringerList(t *testing.T) {
+	encoder, err := NewEncoder("/", "", http.Header{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	encoder.SetQuery("status").StringerList([]fmt.Stringer{
+		mockQueryEnum("PENDING"),
+		mockQueryEnum("RUNNING"),
+		mockQueryEnum("FAILED"),
+	})
+
+	req, err := encoder.Encode(&http.Request{URL: &url.URL{}})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "status=PENDING&status=RUNNING&status=FAILED", req.URL.RawQuery; e != a {
+		t.Errorf("expect %v query, got %v", e, a)
+	}
+}
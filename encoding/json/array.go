@@ -9,6 +9,8 @@ type Array struct {
 	w          *bytes.Buffer
 	writeComma bool
 	scratch    *[]byte
+	sortKeys   bool
+	flusher    *streamFlusher
 }
 
 func newArray(w *bytes.Buffer, scratch *[]byte) *Array {
@@ -20,16 +22,21 @@ func newArray(w *bytes.Buffer, scratch *[]byte) *Array {
 // Returns a Value type that is used to encode
 // the array element.
 func (a *Array) Value() Value {
+	a.flusher.maybeFlush()
 	if a.writeComma {
 		a.w.WriteRune(comma)
 	} else {
 		a.writeComma = true
 	}
 
-	return newValue(a.w, a.scratch)
+	v := newValue(a.w, a.scratch)
+	v.sortKeys = a.sortKeys
+	v.flusher = a.flusher
+	return v
 }
 
 // Close encodes the end of the JSON Array
 func (a *Array) Close() {
 	a.w.WriteRune(rightBracket)
+	a.flusher.maybeFlush()
 }
@@ -0,0 +1,60 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxBigIntExponent is the largest magnitude of a number's exponent accepted
+// when decoding a big integer, bounding the size of the decoded value.
+const maxBigIntExponent = 10000
+
+// DecodeBigInt decodes the JSON number token into a big integer, preserving
+// the exact value of integers that exceed the range of int64 and uint64. The
+// token must be a json.Number, returned by a json.Decoder with UseNumber
+// enabled, since the float64 token returned otherwise may have lost
+// precision.
+//
+// Numbers in scientific notation, or with a fractional part, that have an
+// integral value, (e.g. 1.5E3), are accepted. Returns an error if the number
+// is not integral.
+func DecodeBigInt(token json.Token) (*big.Int, error) {
+	number, ok := token.(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("expect JSON number token for big integer, got %T, decoder must UseNumber", token)
+	}
+
+	i, err := parseBigInt(number.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid big integer %q, %w", number, err)
+	}
+	return i, nil
+}
+
+func parseBigInt(s string) (*big.Int, error) {
+	if i, ok := new(big.Int).SetString(s, 10); ok {
+		return i, nil
+	}
+
+	if idx := strings.IndexAny(s, "eE"); idx >= 0 {
+		exp, err := strconv.Atoi(s[idx+1:])
+		if err != nil {
+			return nil, strconv.ErrSyntax
+		}
+		if exp > maxBigIntExponent || exp < -maxBigIntExponent {
+			return nil, strconv.ErrRange
+		}
+	}
+
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, strconv.ErrSyntax
+	}
+	if !r.IsInt() {
+		return nil, fmt.Errorf("number is not an integer")
+	}
+	return new(big.Int).Set(r.Num()), nil
+}
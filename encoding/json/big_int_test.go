@@ -0,0 +1,100 @@
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func mustBigInt(s string) *big.Int {
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid big integer " + s)
+	}
+	return i
+}
+
+func TestDecodeBigInt(t *testing.T) {
+	cases := map[string]struct {
+		Token       json.Token
+		Expect      *big.Int
+		ExpectErr   bool
+		ExpectRange bool
+	}{
+		"40 digit integer": {
+			Token:  json.Number("1234567890123456789012345678901234567890"),
+			Expect: mustBigInt("1234567890123456789012345678901234567890"),
+		},
+		"negative 40 digit integer": {
+			Token:  json.Number("-9876543210987654321098765432109876543210"),
+			Expect: mustBigInt("-9876543210987654321098765432109876543210"),
+		},
+		"int64 range": {
+			Token:  json.Number("42"),
+			Expect: big.NewInt(42),
+		},
+		"scientific notation": {
+			Token:  json.Number("1.5E40"),
+			Expect: mustBigInt("15000000000000000000000000000000000000000"),
+		},
+		"fractional": {
+			Token:     json.Number("1.5"),
+			ExpectErr: true,
+		},
+		"exponent too large": {
+			Token:       json.Number("1e100000"),
+			ExpectErr:   true,
+			ExpectRange: true,
+		},
+		"float64 token": {
+			Token:     float64(42),
+			ExpectErr: true,
+		},
+		"string token": {
+			Token:     "42",
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			i, err := DecodeBigInt(c.Token)
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				if e, a := c.ExpectRange, errors.Is(err, strconv.ErrRange); e != a {
+					t.Errorf("expect range error %v, got %v, %v", e, a, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.Expect, i; e.Cmp(a) != 0 {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestDecodeBigInt_Decoder(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader(`1234567890123456789012345678901234567890`))
+	decoder.UseNumber()
+
+	token, err := decoder.Token()
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	i, err := DecodeBigInt(token)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "1234567890123456789012345678901234567890", i.String(); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
@@ -1,12 +1,48 @@
 package json
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 )
 
+// utf8BOM is the UTF-8 encoding of the byte order mark.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// NewDecoder returns a JSON decoder reading from r, that tolerates a UTF-8
+// byte order mark (BOM) and whitespace preceding the first JSON token. Some
+// services prefix JSON documents with a BOM, which the standard library
+// decoder rejects.
+func NewDecoder(r io.Reader) *json.Decoder {
+	return json.NewDecoder(skipLeadingBOM(r))
+}
+
+// skipLeadingBOM returns a reader that skips any UTF-8 BOM and whitespace
+// read before the first non-whitespace byte of r.
+func skipLeadingBOM(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	for {
+		// Peek errors are surfaced by the decoder's reads of br.
+		b, _ := br.Peek(len(utf8BOM))
+		if len(b) == 0 {
+			break
+		}
+		if bytes.HasPrefix(b, utf8BOM) {
+			_, _ = br.Discard(len(utf8BOM))
+			continue
+		}
+		switch b[0] {
+		case ' ', '\t', '\n', '\r':
+			_, _ = br.Discard(1)
+			continue
+		}
+		break
+	}
+	return br
+}
+
 // DiscardUnknownField discards unknown fields from a decoder body.
 // This function is useful while deserializing a JSON body with additional
 // unknown information that should be discarded.
@@ -3,6 +3,7 @@ package json
 import (
 	"bytes"
 	"encoding/json"
+	"reflect"
 	"testing"
 
 	smithytesting "github.com/aws/smithy-go/testing"
@@ -63,3 +64,45 @@ func TestCollectUnknownField(t *testing.T) {
 		})
 	}
 }
+
+func TestNewDecoder(t *testing.T) {
+	const doc = `{"foo": "bar", "baz": [1, 2, 3]}`
+
+	cases := map[string]struct {
+		Input     string
+		ExpectErr bool
+	}{
+		"no BOM":                {Input: doc},
+		"BOM":                   {Input: "\xEF\xBB\xBF" + doc},
+		"BOM and whitespace":    {Input: "\xEF\xBB\xBF \r\n\t" + doc},
+		"whitespace before BOM": {Input: "\n\xEF\xBB\xBF" + doc},
+		"leading whitespace":    {Input: " \n\n " + doc},
+		"partial BOM":           {Input: "\xEF\xBB" + doc, ExpectErr: true},
+		"empty":                 {Input: "", ExpectErr: true},
+		"BOM only":              {Input: "\xEF\xBB\xBF", ExpectErr: true},
+	}
+
+	var expect map[string]interface{}
+	if err := json.Unmarshal([]byte(doc), &expect); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var actual map[string]interface{}
+			err := NewDecoder(bytes.NewReader([]byte(c.Input))).Decode(&actual)
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if !reflect.DeepEqual(expect, actual) {
+				t.Errorf("expect %v, got %v", expect, actual)
+			}
+		})
+	}
+}
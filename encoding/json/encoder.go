@@ -11,12 +11,30 @@ type Encoder struct {
 	Value
 }
 
+// EncoderOptions is the set of options for the JSON encoder.
+type EncoderOptions struct {
+	// SortKeys sorts the members of all JSON objects encoded, including
+	// nested objects, by key, instead of encoding members in the order they
+	// were added. Enables deterministic encoding, (e.g. for canonical request
+	// bodies that are signed). Members with the same key retain the order
+	// they were added in.
+	SortKeys bool
+}
+
 // NewEncoder returns a new JSON encoder
-func NewEncoder() *Encoder {
+func NewEncoder(optFns ...func(*EncoderOptions)) *Encoder {
+	var options EncoderOptions
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
 	writer := bytes.NewBuffer(nil)
 	scratch := make([]byte, 64)
 
-	return &Encoder{w: writer, Value: newValue(writer, &scratch)}
+	value := newValue(writer, &scratch)
+	value.sortKeys = options.SortKeys
+
+	return &Encoder{w: writer, Value: value}
 }
 
 // String returns the String output of the JSON encoder
@@ -0,0 +1,63 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// OffsetRange is the range of byte offsets of a value within a JSON document.
+// Start is the offset of the value's first byte, and End is the offset
+// immediately after the value's last byte.
+type OffsetRange struct {
+	Start int64
+	End   int64
+}
+
+// DecodeObjectWithOffsets decodes a JSON object from the decoder, returning
+// the raw value of each top-level member, and the byte offset range of each
+// member's value. Offsets are relative to the start of the decoder's input.
+// Allows precise error reporting of where in a document a member appeared.
+//
+// If a member appears multiple times the last occurrence is returned. Returns
+// an error if the next value of the decoder is not a JSON object.
+func DecodeObjectWithOffsets(decoder *json.Decoder) (map[string]json.RawMessage, map[string]OffsetRange, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, nil, fmt.Errorf("expect JSON object, got %v", token)
+	}
+
+	values := map[string]json.RawMessage{}
+	offsets := map[string]OffsetRange{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("expect string key, got %T", token)
+		}
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		end := decoder.InputOffset()
+
+		values[key] = value
+		offsets[key] = OffsetRange{
+			Start: end - int64(len(value)),
+			End:   end,
+		}
+	}
+
+	// Consume the closing delimiter. decoder.Token validates it matches.
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+
+	return values, offsets, nil
+}
@@ -0,0 +1,60 @@
+package json
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeObjectWithOffsets(t *testing.T) {
+	const doc = `{"foo": "bar", "baz":[1, 2],
+  "qux" : {"a": null}}`
+
+	values, offsets, err := DecodeObjectWithOffsets(json.NewDecoder(strings.NewReader(doc)))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	expectValues := map[string]json.RawMessage{
+		"foo": json.RawMessage(`"bar"`),
+		"baz": json.RawMessage(`[1, 2]`),
+		"qux": json.RawMessage(`{"a": null}`),
+	}
+	if e, a := expectValues, values; !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %s values, got %s", e, a)
+	}
+
+	expectOffsets := map[string]OffsetRange{
+		"foo": {Start: 8, End: 13},
+		"baz": {Start: 21, End: 27},
+		"qux": {Start: 39, End: 50},
+	}
+	if e, a := expectOffsets, offsets; !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v offsets, got %v", e, a)
+	}
+
+	for key, r := range offsets {
+		if e, a := string(values[key]), doc[r.Start:r.End]; e != a {
+			t.Errorf("expect %s offsets to select %s, got %s", key, e, a)
+		}
+	}
+}
+
+func TestDecodeObjectWithOffsets_NotObject(t *testing.T) {
+	cases := map[string]string{
+		"array":    `[1, 2]`,
+		"string":   `"foo"`,
+		"empty":    ``,
+		"unclosed": `{"foo": 1`,
+	}
+
+	for name, doc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := DecodeObjectWithOffsets(json.NewDecoder(strings.NewReader(doc)))
+			if err == nil {
+				t.Fatalf("expect error, got none")
+			}
+		})
+	}
+}
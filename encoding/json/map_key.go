@@ -0,0 +1,94 @@
+package json
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// DecodeMapKey coerces a JSON object key string into the value pointed to by
+// target. JSON object keys are always strings, this helper allows map members
+// with non-string keys to be decoded.
+//
+// Supported target types are pointers to string, bool, the signed and
+// unsigned integer types, and floating point types. Returns an error if the
+// key cannot be parsed as the target type, or overflows the target type. The
+// target is not modified if an error is returned.
+func DecodeMapKey(key string, target interface{}) error {
+	var err error
+	switch v := target.(type) {
+	case *string:
+		*v = key
+	case *bool:
+		var b bool
+		if b, err = strconv.ParseBool(key); err == nil {
+			*v = b
+		}
+	case *int:
+		var n int64
+		if n, err = strconv.ParseInt(key, 10, strconv.IntSize); err == nil {
+			*v = int(n)
+		}
+	case *int8:
+		var n int64
+		if n, err = strconv.ParseInt(key, 10, 8); err == nil {
+			*v = int8(n)
+		}
+	case *int16:
+		var n int64
+		if n, err = strconv.ParseInt(key, 10, 16); err == nil {
+			*v = int16(n)
+		}
+	case *int32:
+		var n int64
+		if n, err = strconv.ParseInt(key, 10, 32); err == nil {
+			*v = int32(n)
+		}
+	case *int64:
+		var n int64
+		if n, err = strconv.ParseInt(key, 10, 64); err == nil {
+			*v = n
+		}
+	case *uint:
+		var n uint64
+		if n, err = strconv.ParseUint(key, 10, strconv.IntSize); err == nil {
+			*v = uint(n)
+		}
+	case *uint8:
+		var n uint64
+		if n, err = strconv.ParseUint(key, 10, 8); err == nil {
+			*v = uint8(n)
+		}
+	case *uint16:
+		var n uint64
+		if n, err = strconv.ParseUint(key, 10, 16); err == nil {
+			*v = uint16(n)
+		}
+	case *uint32:
+		var n uint64
+		if n, err = strconv.ParseUint(key, 10, 32); err == nil {
+			*v = uint32(n)
+		}
+	case *uint64:
+		var n uint64
+		if n, err = strconv.ParseUint(key, 10, 64); err == nil {
+			*v = n
+		}
+	case *float32:
+		var n float64
+		if n, err = strconv.ParseFloat(key, 32); err == nil {
+			*v = float32(n)
+		}
+	case *float64:
+		var n float64
+		if n, err = strconv.ParseFloat(key, 64); err == nil {
+			*v = n
+		}
+	default:
+		return fmt.Errorf("unsupported map key target type %T", target)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid map key %q for %T, %w", key, target, err)
+	}
+
+	return nil
+}
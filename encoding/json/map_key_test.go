@@ -0,0 +1,133 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeMapKey(t *testing.T) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(`{"1": "one", "-2": "minus two", "30": "thirty"}`)))
+
+	if _, err := decoder.Token(); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	actual := map[int]string{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+
+		var key int
+		if err := DecodeMapKey(token.(string), &key); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+
+		var value string
+		if err := decoder.Decode(&value); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		actual[key] = value
+	}
+
+	expect := map[int]string{1: "one", -2: "minus two", 30: "thirty"}
+	if !reflect.DeepEqual(expect, actual) {
+		t.Errorf("expect %v, got %v", expect, actual)
+	}
+}
+
+func TestDecodeMapKey_Types(t *testing.T) {
+	cases := map[string]struct {
+		Key       string
+		Target    interface{}
+		Expect    interface{}
+		ExpectErr string
+	}{
+		"string": {
+			Key: "abc", Target: new(string), Expect: "abc",
+		},
+		"bool": {
+			Key: "true", Target: new(bool), Expect: true,
+		},
+		"int8": {
+			Key: "-128", Target: new(int8), Expect: int8(-128),
+		},
+		"uint64": {
+			Key: "18446744073709551615", Target: new(uint64), Expect: uint64(18446744073709551615),
+		},
+		"float64": {
+			Key: "1.5", Target: new(float64), Expect: 1.5,
+		},
+		"invalid int": {
+			Key: "abc", Target: new(int), ExpectErr: `invalid map key "abc"`,
+		},
+		"overflow int8": {
+			Key: "128", Target: new(int8), ExpectErr: "value out of range",
+		},
+		"negative uint": {
+			Key: "-1", Target: new(uint), ExpectErr: `invalid map key "-1"`,
+		},
+		"unsupported type": {
+			Key: "1", Target: new(struct{}), ExpectErr: "unsupported map key target type",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := DecodeMapKey(c.Key, c.Target)
+			if len(c.ExpectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				if e, a := c.ExpectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expect %q in error, got %q", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.Expect, reflect.ValueOf(c.Target).Elem().Interface(); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestDecodeMapKey_ErrorLeavesTarget(t *testing.T) {
+	i8 := int8(7)
+	if err := DecodeMapKey("128", &i8); err == nil {
+		t.Fatalf("expect error, got none")
+	}
+	if e, a := int8(7), i8; e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+
+	u := uint(7)
+	if err := DecodeMapKey("-1", &u); err == nil {
+		t.Fatalf("expect error, got none")
+	}
+	if e, a := uint(7), u; e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+
+	f32 := float32(7)
+	if err := DecodeMapKey("1e39", &f32); err == nil {
+		t.Fatalf("expect error, got none")
+	}
+	if e, a := float32(7), f32; e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+
+	b := true
+	if err := DecodeMapKey("maybe", &b); err == nil {
+		t.Fatalf("expect error, got none")
+	}
+	if !b {
+		t.Errorf("expect bool target unmodified")
+	}
+}
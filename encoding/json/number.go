@@ -0,0 +1,150 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DecodeLenientNumber decodes the JSON number into the value pointed to by
+// target, accepting forms produced by non-strict JSON encoders. A leading plus
+// sign, (e.g. +5), is accepted, and integer targets accept numbers in
+// scientific notation, or with a fractional part, that have an integral value,
+// (e.g. 1.0E3).
+//
+// Supported target types are pointers to the signed and unsigned integer
+// types, floating point types, and big.Int. A big.Int target preserves the
+// exact value of integers that overflow the other integer types. Returns an
+// error if the number cannot be parsed, is not integral for an integer
+// target, or overflows the target type. Overflow errors wrap
+// strconv.ErrRange. The target is not modified if an error is returned.
+func DecodeLenientNumber(number string, target interface{}) error {
+	s := number
+	if strings.HasPrefix(s, "+") {
+		s = s[1:]
+		if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+			return fmt.Errorf("invalid number %q for %T, %w", number, target, strconv.ErrSyntax)
+		}
+	}
+
+	var err error
+	switch v := target.(type) {
+	case *int:
+		var n int64
+		if n, err = parseLenientInt(s, strconv.IntSize); err == nil {
+			*v = int(n)
+		}
+	case *int8:
+		var n int64
+		if n, err = parseLenientInt(s, 8); err == nil {
+			*v = int8(n)
+		}
+	case *int16:
+		var n int64
+		if n, err = parseLenientInt(s, 16); err == nil {
+			*v = int16(n)
+		}
+	case *int32:
+		var n int64
+		if n, err = parseLenientInt(s, 32); err == nil {
+			*v = int32(n)
+		}
+	case *int64:
+		var n int64
+		if n, err = parseLenientInt(s, 64); err == nil {
+			*v = n
+		}
+	case *uint:
+		var n uint64
+		if n, err = parseLenientUint(s, strconv.IntSize); err == nil {
+			*v = uint(n)
+		}
+	case *uint8:
+		var n uint64
+		if n, err = parseLenientUint(s, 8); err == nil {
+			*v = uint8(n)
+		}
+	case *uint16:
+		var n uint64
+		if n, err = parseLenientUint(s, 16); err == nil {
+			*v = uint16(n)
+		}
+	case *uint32:
+		var n uint64
+		if n, err = parseLenientUint(s, 32); err == nil {
+			*v = uint32(n)
+		}
+	case *uint64:
+		var n uint64
+		if n, err = parseLenientUint(s, 64); err == nil {
+			*v = n
+		}
+	case *float32:
+		var n float64
+		if n, err = strconv.ParseFloat(s, 32); err == nil {
+			*v = float32(n)
+		}
+	case *float64:
+		var n float64
+		if n, err = strconv.ParseFloat(s, 64); err == nil {
+			*v = n
+		}
+	case *big.Int:
+		var n *big.Int
+		if n, err = parseBigInt(s); err == nil {
+			v.Set(n)
+		}
+	default:
+		return fmt.Errorf("unsupported number target type %T", target)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid number %q for %T, %w", number, target, err)
+	}
+
+	return nil
+}
+
+func parseLenientInt(s string, bitSize int) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, bitSize)
+	if !errors.Is(err, strconv.ErrSyntax) {
+		return n, err
+	}
+
+	i, err := parseBigInt(s)
+	if err != nil {
+		return 0, err
+	}
+	if !i.IsInt64() {
+		return 0, strconv.ErrRange
+	}
+
+	n = i.Int64()
+	min := int64(-1) << uint(bitSize-1)
+	if n < min || n > -(min+1) {
+		return 0, strconv.ErrRange
+	}
+	return n, nil
+}
+
+func parseLenientUint(s string, bitSize int) (uint64, error) {
+	n, err := strconv.ParseUint(s, 10, bitSize)
+	if !errors.Is(err, strconv.ErrSyntax) {
+		return n, err
+	}
+
+	i, err := parseBigInt(s)
+	if err != nil {
+		return 0, err
+	}
+	if !i.IsUint64() {
+		return 0, strconv.ErrRange
+	}
+
+	n = i.Uint64()
+	if bitSize < 64 && n >= uint64(1)<<uint(bitSize) {
+		return 0, strconv.ErrRange
+	}
+	return n, nil
+}
@@ -0,0 +1,141 @@
+package json
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/aws/smithy-go/ptr"
+)
+
+func TestDecodeLenientNumber(t *testing.T) {
+	cases := map[string]struct {
+		Number      string
+		Target      interface{}
+		Expect      interface{}
+		ExpectErr   bool
+		ExpectRange bool
+	}{
+		"leading plus int": {
+			Number: "+5", Target: new(int32), Expect: int32(5),
+		},
+		"leading plus uint": {
+			Number: "+5", Target: new(uint8), Expect: uint8(5),
+		},
+		"leading plus float": {
+			Number: "+5.5", Target: new(float64), Expect: 5.5,
+		},
+		"scientific notation int": {
+			Number: "1.0E3", Target: new(int64), Expect: int64(1000),
+		},
+		"scientific notation negative int": {
+			Number: "-2.5e1", Target: new(int), Expect: -25,
+		},
+		"scientific notation uint": {
+			Number: "+1.0E3", Target: new(uint16), Expect: uint16(1000),
+		},
+		"scientific notation float": {
+			Number: "1.0E3", Target: new(float32), Expect: float32(1000),
+		},
+		"plain int": {
+			Number: "-128", Target: new(int8), Expect: int8(-128),
+		},
+		"overflow int8": {
+			Number: "128", Target: new(int8), ExpectErr: true, ExpectRange: true,
+		},
+		"overflow int8 scientific notation": {
+			Number: "1.28e2", Target: new(int8), ExpectErr: true, ExpectRange: true,
+		},
+		"min int64 scientific notation": {
+			Number: "-9.223372036854775808e18", Target: new(int64), Expect: int64(math.MinInt64),
+		},
+		"max uint64 scientific notation": {
+			Number: "1.8446744073709551615e19", Target: new(uint64), Expect: uint64(math.MaxUint64),
+		},
+		"overflow int64 scientific notation": {
+			Number: "9.223372036854775808e18", Target: new(int64), ExpectErr: true, ExpectRange: true,
+		},
+		"overflow int64 large exponent": {
+			Number: "1e400", Target: new(int64), ExpectErr: true, ExpectRange: true,
+		},
+		"overflow float32": {
+			Number: "1e39", Target: new(float32), ExpectErr: true, ExpectRange: true,
+		},
+		"negative uint": {
+			Number: "-1.0e1", Target: new(uint32), ExpectErr: true, ExpectRange: true,
+		},
+		"fractional int": {
+			Number: "1.5", Target: new(int), ExpectErr: true,
+		},
+		"double sign": {
+			Number: "+-5", Target: new(int), ExpectErr: true,
+		},
+		"not a number": {
+			Number: "abc", Target: new(int), ExpectErr: true,
+		},
+		"big integer": {
+			Number: "+1234567890123456789012345678901234567890", Target: new(big.Int),
+			Expect: *mustBigInt("1234567890123456789012345678901234567890"),
+		},
+		"unsupported target": {
+			Number: "1", Target: new(string), ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := DecodeLenientNumber(c.Number, c.Target)
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				if e, a := c.ExpectRange, errors.Is(err, strconv.ErrRange); e != a {
+					t.Errorf("expect range error %v, got %v, %v", e, a, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.Expect, reflect.ValueOf(c.Target).Elem().Interface(); !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestDecodeLenientNumber_ErrorLeavesTarget(t *testing.T) {
+	cases := map[string]struct {
+		Number string
+		Target interface{}
+		Expect interface{}
+	}{
+		"overflow int8": {
+			Number: "1000", Target: ptr.Int8(7), Expect: int8(7),
+		},
+		"not a number int64": {
+			Number: "abc", Target: ptr.Int64(7), Expect: int64(7),
+		},
+		"fractional uint": {
+			Number: "1.5", Target: ptr.Uint(7), Expect: uint(7),
+		},
+		"overflow float32": {
+			Number: "1e39", Target: ptr.Float32(7), Expect: float32(7),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err := DecodeLenientNumber(c.Number, c.Target); err == nil {
+				t.Fatalf("expect error, got none")
+			}
+			if e, a := c.Expect, reflect.ValueOf(c.Target).Elem().Interface(); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package json
 
 import (
 	"bytes"
+	"sort"
 )
 
 // Object represents the encoding of a JSON Object type
@@ -9,6 +10,19 @@ type Object struct {
 	w          *bytes.Buffer
 	writeComma bool
 	scratch    *[]byte
+
+	// sortKeys buffers the object's members, encoding them sorted by key
+	// when the object is closed.
+	sortKeys bool
+	members  []objectMember
+
+	flusher *streamFlusher
+}
+
+// objectMember is a buffered member of an Object encoding sorted keys.
+type objectMember struct {
+	key   string
+	value *bytes.Buffer
 }
 
 func newObject(w *bytes.Buffer, scratch *[]byte) *Object {
@@ -25,16 +39,44 @@ func (o *Object) writeKey(key string) {
 // Returns a Value encoder that should be used to encode
 // a JSON value type.
 func (o *Object) Key(name string) Value {
+	o.flusher.maybeFlush()
+	if o.sortKeys {
+		member := objectMember{key: name, value: bytes.NewBuffer(nil)}
+		o.members = append(o.members, member)
+
+		v := newValue(member.value, o.scratch)
+		v.sortKeys = true
+		v.flusher = o.flusher
+		return v
+	}
+
 	if o.writeComma {
 		o.w.WriteRune(comma)
 	} else {
 		o.writeComma = true
 	}
 	o.writeKey(name)
-	return newValue(o.w, o.scratch)
+	v := newValue(o.w, o.scratch)
+	v.flusher = o.flusher
+	return v
 }
 
 // Close encodes the end of the JSON Object
 func (o *Object) Close() {
+	if o.sortKeys {
+		sort.SliceStable(o.members, func(i, j int) bool {
+			return o.members[i].key < o.members[j].key
+		})
+		for i, member := range o.members {
+			if i != 0 {
+				o.w.WriteRune(comma)
+			}
+			o.writeKey(member.key)
+			o.w.Write(member.value.Bytes())
+		}
+		o.members = nil
+	}
+
 	o.w.WriteRune(rightBrace)
+	o.flusher.maybeFlush()
 }
@@ -32,3 +32,86 @@ func TestObjectKey_escaped(t *testing.T) {
 		t.Errorf("expected %+q, but got %+q", e, a)
 	}
 }
+
+func TestObject_SortKeys(t *testing.T) {
+	cases := map[string]struct {
+		Encode func(*Encoder)
+		Expect string
+	}{
+		"sorted": {
+			Encode: func(e *Encoder) {
+				o := e.Object()
+				o.Key("zeta").Integer(1)
+				o.Key("alpha").String("a")
+				o.Key("mid").Boolean(true)
+				o.Close()
+			},
+			Expect: `{"alpha":"a","mid":true,"zeta":1}`,
+		},
+		"nested objects": {
+			Encode: func(e *Encoder) {
+				o := e.Object()
+				inner := o.Key("b").Object()
+				inner.Key("y").Integer(2)
+				inner.Key("x").Integer(1)
+				inner.Close()
+				o.Key("a").Null()
+				o.Close()
+			},
+			Expect: `{"a":null,"b":{"x":1,"y":2}}`,
+		},
+		"objects within arrays": {
+			Encode: func(e *Encoder) {
+				o := e.Object()
+				a := o.Key("list").Array()
+				elem := a.Value().Object()
+				elem.Key("d").String("4")
+				elem.Key("c").String("3")
+				elem.Close()
+				a.Value().String("s")
+				a.Close()
+				o.Key("k").Integer(0)
+				o.Close()
+			},
+			Expect: `{"k":0,"list":[{"c":"3","d":"4"},"s"]}`,
+		},
+		"escaped keys": {
+			Encode: func(e *Encoder) {
+				o := e.Object()
+				o.Key("b\"").String("2")
+				o.Key("a").String("1")
+				o.Close()
+			},
+			Expect: `{"a":"1","b\"":"2"}`,
+		},
+		"duplicate keys": {
+			Encode: func(e *Encoder) {
+				o := e.Object()
+				o.Key("b").Integer(1)
+				o.Key("a").Integer(2)
+				o.Key("b").Integer(3)
+				o.Close()
+			},
+			Expect: `{"a":2,"b":1,"b":3}`,
+		},
+		"empty object": {
+			Encode: func(e *Encoder) {
+				e.Object().Close()
+			},
+			Expect: `{}`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			encoder := NewEncoder(func(o *EncoderOptions) {
+				o.SortKeys = true
+			})
+			c.Encode(encoder)
+
+			if e, a := c.Expect, encoder.String(); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
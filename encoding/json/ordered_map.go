@@ -0,0 +1,70 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// OrderedMapEntry is a single member of a JSON object decoded by
+// DecodeOrderedMap. Value is the raw JSON of the member's value, which can be
+// further decoded by the caller.
+type OrderedMapEntry struct {
+	Key   string
+	Value json.RawMessage
+}
+
+// OrderedMap is the members of a JSON object in the order they appeared in
+// the document.
+type OrderedMap []OrderedMapEntry
+
+// Keys returns the keys of the map's members in order.
+func (m OrderedMap) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for _, e := range m {
+		keys = append(keys, e.Key)
+	}
+	return keys
+}
+
+// DecodeOrderedMap decodes a JSON object from the decoder, returning its
+// members in document order instead of as a Go map. Allows callers to re-emit
+// an object's members in the order they were received.
+//
+// Returns a nil OrderedMap if the next value of the decoder is JSON null, and
+// an error if it is not a JSON object. Duplicate keys are retained.
+func DecodeOrderedMap(decoder *json.Decoder) (OrderedMap, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("expect JSON object, got %v", token)
+	}
+
+	m := OrderedMap{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("expect JSON object key, got %v", token)
+		}
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+		m = append(m, OrderedMapEntry{Key: key, Value: value})
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,80 @@
+package json
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeOrderedMap(t *testing.T) {
+	cases := map[string]struct {
+		Input     string
+		Expect    OrderedMap
+		ExpectErr bool
+	}{
+		"document order": {
+			Input: `{"zeta": 1, "alpha": "a", "mid": {"b": 2, "a": 1}, "beta": [1, 2], "nil": null}`,
+			Expect: OrderedMap{
+				{Key: "zeta", Value: json.RawMessage(`1`)},
+				{Key: "alpha", Value: json.RawMessage(`"a"`)},
+				{Key: "mid", Value: json.RawMessage(`{"b": 2, "a": 1}`)},
+				{Key: "beta", Value: json.RawMessage(`[1, 2]`)},
+				{Key: "nil", Value: json.RawMessage(`null`)},
+			},
+		},
+		"duplicate keys": {
+			Input: `{"a": 1, "b": 2, "a": 3}`,
+			Expect: OrderedMap{
+				{Key: "a", Value: json.RawMessage(`1`)},
+				{Key: "b", Value: json.RawMessage(`2`)},
+				{Key: "a", Value: json.RawMessage(`3`)},
+			},
+		},
+		"empty object": {
+			Input:  `{}`,
+			Expect: OrderedMap{},
+		},
+		"null": {
+			Input: `null`,
+		},
+		"not an object": {
+			Input:     `["a"]`,
+			ExpectErr: true,
+		},
+		"truncated": {
+			Input:     `{"a": 1,`,
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := DecodeOrderedMap(NewDecoder(strings.NewReader(c.Input)))
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.Expect, actual; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestOrderedMap_Keys(t *testing.T) {
+	m, err := DecodeOrderedMap(NewDecoder(strings.NewReader(`{"c": 1, "a": 2, "b": 3}`)))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if e, a := []string{"c", "a", "b"}, m.Keys(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v keys, got %v", e, a)
+	}
+}
@@ -0,0 +1,85 @@
+package json
+
+import (
+	"bytes"
+	"io"
+)
+
+// defaultStreamFlushThreshold is the number of buffered bytes after which
+// the StreamEncoder writes the buffered output to its writer.
+const defaultStreamFlushThreshold = 4 * 1024
+
+// StreamEncoder is a JSON encoder that writes its output to an io.Writer
+// incrementally as JSON objects and arrays are built, instead of buffering
+// the full document in memory. The output is identical to the Encoder's.
+//
+// Output is written when an object member or array element is added, or an
+// object or array is closed, once enough output has been buffered. Flush must
+// be called after the value has been encoded to write the remaining output.
+// With the SortKeys option, members of each object are buffered until the
+// object is closed.
+type StreamEncoder struct {
+	Value
+
+	flusher *streamFlusher
+}
+
+// NewStreamEncoder returns a new JSON encoder that writes its output to w.
+func NewStreamEncoder(w io.Writer, optFns ...func(*EncoderOptions)) *StreamEncoder {
+	var options EncoderOptions
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	scratch := make([]byte, 64)
+	flusher := &streamFlusher{
+		w:         w,
+		buf:       buf,
+		threshold: defaultStreamFlushThreshold,
+	}
+
+	value := newValue(buf, &scratch)
+	value.sortKeys = options.SortKeys
+	value.flusher = flusher
+
+	return &StreamEncoder{Value: value, flusher: flusher}
+}
+
+// Flush writes any buffered output to the writer. Returns the first error
+// that occurred writing to the writer. No further output is written after an
+// error occurs.
+func (e *StreamEncoder) Flush() error {
+	return e.flusher.flush()
+}
+
+// streamFlusher writes the buffered output of a StreamEncoder to its writer.
+type streamFlusher struct {
+	w         io.Writer
+	buf       *bytes.Buffer
+	threshold int
+	err       error
+}
+
+// maybeFlush writes the buffered output if it has reached the flush
+// threshold. Safe to call on a nil streamFlusher, for encoders that do not
+// stream.
+func (f *streamFlusher) maybeFlush() {
+	if f == nil || f.buf.Len() < f.threshold {
+		return
+	}
+	f.flush()
+}
+
+func (f *streamFlusher) flush() error {
+	if f.err != nil {
+		f.buf.Reset()
+		return f.err
+	}
+
+	if _, err := f.buf.WriteTo(f.w); err != nil {
+		f.err = err
+		f.buf.Reset()
+	}
+	return f.err
+}
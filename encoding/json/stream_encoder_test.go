@@ -0,0 +1,133 @@
+package json_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/encoding/json"
+)
+
+type recordingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func encodeStreamTestValue(v json.Value, items int) {
+	object := v.Object()
+	object.Key("zeta").String("last")
+	object.Key("alpha").Long(1024)
+
+	list := object.Key("items").Array()
+	for i := 0; i < items; i++ {
+		item := list.Value().Object()
+		item.Key("name").String(fmt.Sprintf("item-%d", i))
+		item.Key("id").Integer(int32(i))
+		item.Close()
+	}
+	list.Close()
+
+	object.Key("mid").Boolean(true)
+	object.Close()
+}
+
+func TestStreamEncoder(t *testing.T) {
+	cases := map[string]struct {
+		Items   int
+		Options func(*json.EncoderOptions)
+	}{
+		"small": {
+			Items: 2,
+		},
+		"large": {
+			Items: 1000,
+		},
+		"large sorted keys": {
+			Items: 1000,
+			Options: func(o *json.EncoderOptions) {
+				o.SortKeys = true
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var optFns []func(*json.EncoderOptions)
+			if c.Options != nil {
+				optFns = append(optFns, c.Options)
+			}
+
+			expect := json.NewEncoder(optFns...)
+			encodeStreamTestValue(expect.Value, c.Items)
+
+			var w recordingWriter
+			encoder := json.NewStreamEncoder(&w, optFns...)
+			encodeStreamTestValue(encoder.Value, c.Items)
+			if err := encoder.Flush(); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := expect.Bytes(), w.Bytes(); !bytes.Equal(e, a) {
+				t.Errorf("expect %s, got %s", e, a)
+			}
+		})
+	}
+}
+
+func TestStreamEncoder_IncrementalWrites(t *testing.T) {
+	var w recordingWriter
+	encoder := json.NewStreamEncoder(&w)
+
+	list := encoder.Array()
+	for i := 0; i < 1000; i++ {
+		list.Value().String(strings.Repeat("a", 64))
+	}
+
+	if w.writes < 2 {
+		t.Errorf("expect multiple writes before flush, got %v", w.writes)
+	}
+	if w.Len() == 0 {
+		t.Errorf("expect output written before flush")
+	}
+
+	list.Close()
+	if err := encoder.Flush(); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if e, a := 1000*67+1, w.Len(); e != a {
+		t.Errorf("expect %v bytes, got %v", e, a)
+	}
+}
+
+func TestStreamEncoder_WriteError(t *testing.T) {
+	encoder := json.NewStreamEncoder(failingWriter{err: fmt.Errorf("write failed")})
+
+	list := encoder.Array()
+	for i := 0; i < 1000; i++ {
+		list.Value().String(strings.Repeat("a", 64))
+	}
+	list.Close()
+
+	err := encoder.Flush()
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+	if e, a := "write failed", err.Error(); e != a {
+		t.Errorf("expect %v error, got %v", e, a)
+	}
+}
@@ -0,0 +1,29 @@
+package json
+
+import (
+	"strings"
+	"time"
+
+	smithytime "github.com/aws/smithy-go/time"
+)
+
+// DecodeDateTime parses a JSON date-time timestamp string, (RFC3339 section
+// 5.6). In addition to the formats supported by smithytime.ParseDateTime, a
+// comma fractional seconds separator, (e.g. 2020-01-01T00:00:00,500Z), emitted
+// by some servers is accepted, as permitted by ISO 8601.
+func DecodeDateTime(value string) (time.Time, error) {
+	return smithytime.ParseDateTime(normalizeFractionalSeparator(value))
+}
+
+// normalizeFractionalSeparator replaces a comma separating the seconds and
+// fractional seconds of the timestamp with a dot.
+func normalizeFractionalSeparator(value string) string {
+	// The comma separator follows the "hh:mm:ss" time of the timestamp.
+	const timeLen = len("T15:04:05")
+
+	i := strings.IndexAny(value, "Tt")
+	if i < 0 || len(value) <= i+timeLen || value[i+timeLen] != ',' {
+		return value
+	}
+	return value[:i+timeLen] + "." + value[i+timeLen+1:]
+}
@@ -0,0 +1,57 @@
+package json
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecodeDateTime(t *testing.T) {
+	cases := map[string]struct {
+		Value     string
+		Expect    time.Time
+		ExpectErr bool
+	}{
+		"dot separator": {
+			Value:  "2020-01-01T00:00:00.500Z",
+			Expect: time.Date(2020, 1, 1, 0, 0, 0, 500*int(time.Millisecond), time.UTC),
+		},
+		"comma separator": {
+			Value:  "2020-01-01T00:00:00,500Z",
+			Expect: time.Date(2020, 1, 1, 0, 0, 0, 500*int(time.Millisecond), time.UTC),
+		},
+		"comma separator with offset": {
+			Value:  "2020-01-01T02:00:00,25+02:00",
+			Expect: time.Date(2020, 1, 1, 0, 0, 0, 250*int(time.Millisecond), time.UTC),
+		},
+		"no fractional seconds": {
+			Value:  "2020-01-01T00:00:00Z",
+			Expect: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		"comma elsewhere": {
+			Value:     "2020-01-01,T00:00:00Z",
+			ExpectErr: true,
+		},
+		"invalid": {
+			Value:     "not a timestamp",
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := DecodeDateTime(c.Value)
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if !c.Expect.Equal(actual) {
+				t.Errorf("expect %v, got %v", c.Expect, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ObjectMemberDecoder decodes the value of the object member with the key
+// from the decoder. Returns false, without reading the member's value, if the
+// key is not a member known to the decoder.
+type ObjectMemberDecoder func(decoder *json.Decoder, key string) (known bool, err error)
+
+// DecodeObjectUnknownFields decodes a JSON object from the decoder, invoking
+// decode for each of the object's members. The values of members not known
+// to decode are discarded, and the keys of those members returned in the
+// order they appeared.
+//
+// Allows strict clients to observe fields unknown to the target decoder,
+// applying a warn-or-error policy for unexpected fields instead of silently
+// discarding them. Returns an error if the next value of the decoder is not a
+// JSON object.
+func DecodeObjectUnknownFields(decoder *json.Decoder, decode ObjectMemberDecoder) (unknown []string, err error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("expect JSON object, got %v", token)
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("expect JSON object key, got %v", token)
+		}
+
+		known, err := decode(decoder, key)
+		if err != nil {
+			return nil, err
+		}
+		if known {
+			continue
+		}
+
+		unknown = append(unknown, key)
+		if err := DiscardUnknownField(decoder); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	return unknown, nil
+}
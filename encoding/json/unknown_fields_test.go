@@ -0,0 +1,77 @@
+package json
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeObjectUnknownFields(t *testing.T) {
+	type target struct {
+		Name  string
+		Count int
+	}
+
+	cases := map[string]struct {
+		Input         string
+		Expect        target
+		ExpectUnknown []string
+		ExpectErr     bool
+	}{
+		"no unknown fields": {
+			Input:  `{"name": "foo", "count": 3}`,
+			Expect: target{Name: "foo", Count: 3},
+		},
+		"unknown fields": {
+			Input:         `{"name": "foo", "extra": {"nested": [1, 2]}, "count": 3, "other": null}`,
+			Expect:        target{Name: "foo", Count: 3},
+			ExpectUnknown: []string{"extra", "other"},
+		},
+		"not an object": {
+			Input:     `["name"]`,
+			ExpectErr: true,
+		},
+		"invalid member value": {
+			Input:     `{"count": "three"}`,
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var actual target
+			decoder := json.NewDecoder(strings.NewReader(c.Input))
+
+			unknown, err := DecodeObjectUnknownFields(decoder, func(decoder *json.Decoder, key string) (bool, error) {
+				switch key {
+				case "name":
+					return true, decoder.Decode(&actual.Name)
+				case "count":
+					return true, decoder.Decode(&actual.Count)
+				default:
+					return false, nil
+				}
+			})
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.Expect, actual; e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+			if e, a := c.ExpectUnknown, unknown; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v unknown fields, got %v", e, a)
+			}
+			if decoder.More() {
+				t.Errorf("expect object to be fully consumed")
+			}
+		})
+	}
+}
@@ -12,8 +12,10 @@ import (
 // Value represents a JSON Value type
 // JSON Value types: Object, Array, String, Number, Boolean, and Null
 type Value struct {
-	w       *bytes.Buffer
-	scratch *[]byte
+	w        *bytes.Buffer
+	scratch  *[]byte
+	sortKeys bool
+	flusher  *streamFlusher
 }
 
 // newValue returns a new Value encoder
@@ -86,12 +88,18 @@ func (jv Value) Write(v []byte) {
 
 // Array returns a new Array encoder
 func (jv Value) Array() *Array {
-	return newArray(jv.w, jv.scratch)
+	a := newArray(jv.w, jv.scratch)
+	a.sortKeys = jv.sortKeys
+	a.flusher = jv.flusher
+	return a
 }
 
 // Object returns a new Object encoder
 func (jv Value) Object() *Object {
-	return newObject(jv.w, jv.scratch)
+	o := newObject(jv.w, jv.scratch)
+	o.sortKeys = jv.sortKeys
+	o.flusher = jv.flusher
+	return o
 }
 
 // Null encodes a null JSON value
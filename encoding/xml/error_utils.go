@@ -9,8 +9,9 @@ import (
 // ErrorComponents represents the error response fields
 // that will be deserialized from an xml error response body
 type ErrorComponents struct {
-	Code    string
-	Message string
+	Code      string
+	Message   string
+	RequestID string
 }
 
 // GetErrorResponseComponents returns the error fields from an xml error response body
@@ -21,8 +22,9 @@ func GetErrorResponseComponents(r io.Reader, noErrorWrapping bool) (ErrorCompone
 			return ErrorComponents{}, fmt.Errorf("error while deserializing xml error response: %w", err)
 		}
 		return ErrorComponents{
-			Code:    errResponse.Code,
-			Message: errResponse.Message,
+			Code:      errResponse.Code,
+			Message:   errResponse.Message,
+			RequestID: errResponse.RequestID,
 		}, nil
 	}
 
@@ -31,21 +33,74 @@ func GetErrorResponseComponents(r io.Reader, noErrorWrapping bool) (ErrorCompone
 		return ErrorComponents{}, fmt.Errorf("error while deserializing xml error response: %w", err)
 	}
 	return ErrorComponents{
-		Code:    errResponse.Code,
-		Message: errResponse.Message,
+		Code:      errResponse.Code,
+		Message:   errResponse.Message,
+		RequestID: errResponse.RequestID,
 	}, nil
 }
 
 // noWrappedErrorResponse represents the error response body with
 // no internal <Error></Error wrapping
 type noWrappedErrorResponse struct {
-	Code    string `xml:"Code"`
-	Message string `xml:"Message"`
+	Code      string `xml:"Code"`
+	Message   string `xml:"Message"`
+	RequestID string `xml:"RequestId"`
 }
 
 // wrappedErrorResponse represents the error response body
 // wrapped within <Error>...</Error>
 type wrappedErrorResponse struct {
-	Code    string `xml:"Error>Code"`
-	Message string `xml:"Error>Message"`
+	Code      string `xml:"Error>Code"`
+	Message   string `xml:"Error>Message"`
+	RequestID string `xml:"RequestId"`
+}
+
+// DecodeErrorResponse decodes the error code, message, and request ID from an
+// XML error response document. The error members may be wrapped within an
+// <Error> element, which in turn may be wrapped within an <ErrorResponse>
+// element. The request ID may be found within either the <Error> element, or
+// the <ErrorResponse> wrapper. Elements other than the error members are
+// skipped.
+//
+// An empty document returns empty ErrorComponents without error.
+func DecodeErrorResponse(decoder *xml.Decoder) (ErrorComponents, error) {
+	var ec ErrorComponents
+	for {
+		t, err := decoder.Token()
+		if err == io.EOF {
+			return ec, nil
+		}
+		if err != nil {
+			return ErrorComponents{}, fmt.Errorf("error while deserializing xml error response: %w", err)
+		}
+
+		start, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		var v *string
+		switch start.Name.Local {
+		case "ErrorResponse", "Error":
+			// descend into the wrapping element
+			continue
+		case "Code":
+			v = &ec.Code
+		case "Message":
+			v = &ec.Message
+		case "RequestId", "RequestID":
+			v = &ec.RequestID
+		}
+
+		if v == nil {
+			if err := decoder.Skip(); err != nil {
+				return ErrorComponents{}, fmt.Errorf("error while deserializing xml error response: %w", err)
+			}
+			continue
+		}
+
+		if err := decoder.DecodeElement(v, &start); err != nil {
+			return ErrorComponents{}, fmt.Errorf("error while deserializing xml error response: %w", err)
+		}
+	}
 }
@@ -2,6 +2,7 @@ package xml
 
 import (
 	"bytes"
+	"encoding/xml"
 	"io"
 	"strings"
 	"testing"
@@ -60,3 +61,68 @@ func TestGetResponseErrorCode(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeErrorResponse(t *testing.T) {
+	cases := map[string]struct {
+		errorResponse string
+		expect        ErrorComponents
+	}{
+		"wrapped": {
+			errorResponse: `<ErrorResponse>
+    <Error>
+        <Type>Sender</Type>
+        <Code>InvalidGreeting</Code>
+        <Message>Hi</Message>
+        <AnotherSetting>setting</AnotherSetting>
+    </Error>
+    <RequestId>foo-id</RequestId>
+</ErrorResponse>`,
+			expect: ErrorComponents{
+				Code:      "InvalidGreeting",
+				Message:   "Hi",
+				RequestID: "foo-id",
+			},
+		},
+		"unwrapped": {
+			errorResponse: `<Error>
+    <Code>NoSuchKey</Code>
+    <Message>The resource you requested does not exist</Message>
+    <Resource>/mybucket/myfoto.jpg</Resource>
+    <RequestId>4442587FB7D0A2F9</RequestId>
+</Error>`,
+			expect: ErrorComponents{
+				Code:      "NoSuchKey",
+				Message:   "The resource you requested does not exist",
+				RequestID: "4442587FB7D0A2F9",
+			},
+		},
+		"no request id": {
+			errorResponse: `<Error><Code>InvalidGreeting</Code><Message>Hi</Message></Error>`,
+			expect: ErrorComponents{
+				Code:    "InvalidGreeting",
+				Message: "Hi",
+			},
+		},
+		"no response body": {},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			decoder := xml.NewDecoder(strings.NewReader(c.errorResponse))
+			ec, err := DecodeErrorResponse(decoder)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if e, a := c.expect, ec; e != a {
+				t.Errorf("expected %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestDecodeErrorResponse_Malformed(t *testing.T) {
+	decoder := xml.NewDecoder(strings.NewReader(`<Error><Code>InvalidGreeting</Error>`))
+	if _, err := DecodeErrorResponse(decoder); err == nil {
+		t.Fatalf("expected error, got none")
+	}
+}
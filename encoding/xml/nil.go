@@ -0,0 +1,81 @@
+package xml
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// XSINamespace is the XML Schema instance namespace, whose nil attribute,
+// (e.g. xsi:nil="true"), marks an element as an explicit null.
+const XSINamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+const (
+	xsiPrefix  = "xsi"
+	xsiNilAttr = "nil"
+)
+
+// NilMemberElement encodes the member element as an explicit null, written
+// as an empty element with the xsi:nil attribute, (e.g. <Foo
+// xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"/>). The
+// xsi namespace is declared on the element, unless the element already
+// declares it.
+//
+// The element is written in full, and no Value needs to be closed.
+func (xv Value) NilMemberElement(element StartElement) {
+	element = element.Copy()
+
+	var declared bool
+	for _, attr := range element.Attr {
+		if attr.Name.Space == "xmlns" && attr.Name.Local == xsiPrefix {
+			declared = true
+			break
+		}
+	}
+	if !declared {
+		element.Attr = append(element.Attr, NewNamespaceAttribute(xsiPrefix, XSINamespace))
+	}
+	element.Attr = append(element.Attr, Attr{
+		Name:  Name{Space: xsiPrefix, Local: xsiNilAttr},
+		Value: "true",
+	})
+
+	writeEmptyElement(xv.w, element)
+}
+
+// NillableMemberElement does member element encoding for a nillable member.
+// If v is nil, or a nil pointer, map, or slice, the member is encoded as an
+// explicit null with NilMemberElement. Otherwise the start element tag is
+// written and fn is called with the member's Value to encode v. fn must close
+// the Value.
+func (xv Value) NillableMemberElement(element StartElement, v interface{}, fn func(Value)) {
+	if isNilValue(v) {
+		xv.NilMemberElement(element)
+		return
+	}
+	fn(xv.MemberElement(element))
+}
+
+// IsNilElement returns if the start element is marked as an explicit null
+// with the xsi:nil attribute, (e.g. xsi:nil="true").
+func IsNilElement(el xml.StartElement) bool {
+	for _, attr := range el.Attr {
+		if attr.Name.Local != xsiNilAttr {
+			continue
+		}
+		if attr.Name.Space != XSINamespace && attr.Name.Space != xsiPrefix {
+			continue
+		}
+
+		switch strings.TrimSpace(attr.Value) {
+		case "true", "1":
+			return true
+		}
+	}
+	return false
+}
+
+// IsNil returns if the node decoder's start element is marked as an explicit
+// null with the xsi:nil attribute.
+func (d NodeDecoder) IsNil() bool {
+	return IsNilElement(d.StartEl)
+}
@@ -0,0 +1,161 @@
+package xml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+)
+
+func TestNillableMemberElement(t *testing.T) {
+	str := func(v string) *string { return &v }
+
+	cases := map[string]struct {
+		Value    *string
+		Expected string
+	}{
+		"nil member": {
+			Expected: `<root><foo xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"/></root>`,
+		},
+		"set member": {
+			Value:    str("bar"),
+			Expected: `<root><foo>bar</foo></root>`,
+		},
+		"empty member": {
+			Value:    str(""),
+			Expected: `<root><foo></foo></root>`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := bytes.NewBuffer(nil)
+			encoder := NewEncoder(b)
+
+			root := encoder.RootElement(StartElement{Name: Name{Local: "root"}})
+			root.NillableMemberElement(StartElement{Name: Name{Local: "foo"}}, c.Value, func(v Value) {
+				v.String(*c.Value)
+			})
+			root.Close()
+
+			if e, a := c.Expected, encoder.String(); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestNilMemberElement_DeclaredNamespace(t *testing.T) {
+	b := bytes.NewBuffer(nil)
+	encoder := NewEncoder(b)
+
+	root := encoder.RootElement(StartElement{Name: Name{Local: "root"}})
+	root.NilMemberElement(StartElement{
+		Name: Name{Local: "foo"},
+		Attr: []Attr{NewNamespaceAttribute("xsi", XSINamespace)},
+	})
+	root.Close()
+
+	expect := `<root><foo xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:nil="true"/></root>`
+	if e, a := expect, encoder.String(); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
+
+func TestIsNilElement_RoundTrip(t *testing.T) {
+	str := func(v string) *string { return &v }
+
+	type input struct {
+		Foo *string
+		Bar *string
+	}
+
+	b := bytes.NewBuffer(nil)
+	encoder := NewEncoder(b)
+	in := input{Bar: str("")}
+
+	root := encoder.RootElement(StartElement{Name: Name{Local: "root"}})
+	root.NillableMemberElement(StartElement{Name: Name{Local: "Foo"}}, in.Foo, func(v Value) {
+		v.String(*in.Foo)
+	})
+	root.NillableMemberElement(StartElement{Name: Name{Local: "Bar"}}, in.Bar, func(v Value) {
+		v.String(*in.Bar)
+	})
+	root.Close()
+
+	decoder := xml.NewDecoder(bytes.NewReader(encoder.Bytes()))
+	rootEl, err := FetchRootElement(decoder)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	var out input
+	rootDecoder := WrapNodeDecoder(decoder, rootEl)
+	for {
+		el, done, err := rootDecoder.Token()
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if done {
+			break
+		}
+
+		memberDecoder := WrapNodeDecoder(decoder, el)
+		v, err := memberDecoder.Value()
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if e, a := el.Name.Local == "Foo", IsNilElement(el); e != a {
+			t.Errorf("expect %v nil %v, got %v", el.Name.Local, e, a)
+		}
+
+		var member *string
+		if v != nil {
+			member = str(string(v))
+		}
+		switch el.Name.Local {
+		case "Foo":
+			out.Foo = member
+		case "Bar":
+			out.Bar = member
+		}
+	}
+
+	if out.Foo != nil {
+		t.Errorf("expect Foo to be nil, got %v", *out.Foo)
+	}
+	if out.Bar == nil || *out.Bar != "" {
+		t.Errorf("expect Bar to be empty, got %v", out.Bar)
+	}
+}
+
+func TestIsNilElement(t *testing.T) {
+	cases := map[string]struct {
+		Attr   []xml.Attr
+		Expect bool
+	}{
+		"resolved namespace": {
+			Attr:   []xml.Attr{{Name: xml.Name{Space: XSINamespace, Local: "nil"}, Value: "true"}},
+			Expect: true,
+		},
+		"prefix": {
+			Attr:   []xml.Attr{{Name: xml.Name{Space: "xsi", Local: "nil"}, Value: "1"}},
+			Expect: true,
+		},
+		"false": {
+			Attr: []xml.Attr{{Name: xml.Name{Space: XSINamespace, Local: "nil"}, Value: "false"}},
+		},
+		"other namespace": {
+			Attr: []xml.Attr{{Name: xml.Name{Space: "http://example.com", Local: "nil"}, Value: "true"}},
+		},
+		"no attributes": {},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			el := xml.StartElement{Name: xml.Name{Local: "foo"}, Attr: c.Attr}
+			if e, a := c.Expect, IsNilElement(el); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package xml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DecodeError is returned by the PositionDecoder when reading a token fails,
+// providing the element path and input offset the error occurred at.
+type DecodeError struct {
+	// Path is the path of the elements open when the error occurred,
+	// (e.g. /Root/Child).
+	Path string
+
+	// Offset is the input byte offset of the decoder when the error occurred.
+	Offset int64
+
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("xml decode error at %s, offset %d, %v", e.Path, e.Offset, e.Err)
+}
+
+// Unwrap returns the underlying decode error.
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// PositionDecoder is a XML decoder wrapper that tracks the path of the
+// elements currently open in the document. Errors reading tokens are returned
+// as a DecodeError with the element path and input offset of the error.
+type PositionDecoder struct {
+	Decoder *xml.Decoder
+
+	path []string
+}
+
+// WrapPositionDecoder returns an initialized PositionDecoder reading tokens
+// from the decoder. The decoder's tokens must only be read through the
+// PositionDecoder for the tracked path to be accurate.
+func WrapPositionDecoder(decoder *xml.Decoder) *PositionDecoder {
+	return &PositionDecoder{
+		Decoder: decoder,
+	}
+}
+
+// Token returns the next XML token of the decoder, updating the tracked
+// element path. Returns io.EOF at the end of the input, and a DecodeError for
+// any other error.
+func (d *PositionDecoder) Token() (xml.Token, error) {
+	t, err := d.Decoder.Token()
+	if err == io.EOF {
+		return nil, err
+	}
+	if err != nil {
+		return nil, &DecodeError{
+			Path:   d.Path(),
+			Offset: d.Decoder.InputOffset(),
+			Err:    err,
+		}
+	}
+
+	switch el := t.(type) {
+	case xml.StartElement:
+		d.path = append(d.path, el.Name.Local)
+	case xml.EndElement:
+		if len(d.path) != 0 {
+			d.path = d.path[:len(d.path)-1]
+		}
+	}
+
+	return t, nil
+}
+
+// Skip reads tokens until it has consumed the end element matching the most
+// recent start element already read, keeping the tracked element path.
+func (d *PositionDecoder) Skip() error {
+	depth := len(d.path)
+	for len(d.path) >= depth {
+		if _, err := d.Token(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Path returns the path of the elements currently open, (e.g. /Root/Child).
+// Returns / if no element is open.
+func (d *PositionDecoder) Path() string {
+	return "/" + strings.Join(d.path, "/")
+}
+
+// InputOffset returns the input byte offset of the decoder, see
+// xml.Decoder.InputOffset.
+func (d *PositionDecoder) InputOffset() int64 {
+	return d.Decoder.InputOffset()
+}
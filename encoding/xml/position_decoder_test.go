@@ -0,0 +1,103 @@
+package xml
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestPositionDecoder_DecodeError(t *testing.T) {
+	cases := map[string]struct {
+		Input        string
+		ExpectPath   string
+		ExpectOffset int64
+	}{
+		"mismatched nested end element": {
+			Input:        `<Root><Child><Grand>abc</Child></Root>`,
+			ExpectPath:   "/Root/Child/Grand",
+			ExpectOffset: 31,
+		},
+		"malformed nested element": {
+			Input:        `<Root><Child><Grand attr=></Grand></Child></Root>`,
+			ExpectPath:   "/Root/Child",
+			ExpectOffset: 26,
+		},
+		"unclosed element": {
+			Input:        `<Root><Child>abc`,
+			ExpectPath:   "/Root/Child",
+			ExpectOffset: 16,
+		},
+		"sibling elements": {
+			Input:        `<Root><A></A><B><C></B></Root>`,
+			ExpectPath:   "/Root/B/C",
+			ExpectOffset: 23,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := WrapPositionDecoder(xml.NewDecoder(strings.NewReader(c.Input)))
+
+			var err error
+			for err == nil {
+				_, err = d.Token()
+			}
+
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Fatalf("expect decode error, got %v", err)
+			}
+			if e, a := c.ExpectPath, decodeErr.Path; e != a {
+				t.Errorf("expect %v path, got %v", e, a)
+			}
+			if e, a := c.ExpectOffset, decodeErr.Offset; e != a {
+				t.Errorf("expect %v offset, got %v", e, a)
+			}
+			var syntaxErr *xml.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Errorf("expect wrapped syntax error, got %v", err)
+			}
+			if e, a := "error at "+c.ExpectPath, err.Error(); !strings.Contains(a, e) {
+				t.Errorf("expect %q in error, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestPositionDecoder_Path(t *testing.T) {
+	d := WrapPositionDecoder(xml.NewDecoder(strings.NewReader(
+		`<Root><Skipped><Nested>abc</Nested></Skipped><Child>def</Child></Root>`)))
+
+	var paths []string
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		paths = append(paths, d.Path())
+		if start.Name.Local == "Skipped" {
+			if err := d.Skip(); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			paths = append(paths, d.Path())
+		}
+	}
+
+	expect := []string{"/Root", "/Root/Skipped", "/Root", "/Root/Child"}
+	if e, a := strings.Join(expect, ","), strings.Join(paths, ","); e != a {
+		t.Errorf("expect %v paths, got %v", e, a)
+	}
+	if e, a := "/", d.Path(); e != a {
+		t.Errorf("expect %v path at end of document, got %v", e, a)
+	}
+}
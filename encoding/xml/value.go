@@ -4,9 +4,12 @@ import (
 	"encoding/base64"
 	"fmt"
 	"math/big"
+	"reflect"
 	"strconv"
+	"time"
 
 	"github.com/aws/smithy-go/encoding"
+	smithytime "github.com/aws/smithy-go/time"
 )
 
 // Value represents an XML Value type
@@ -40,6 +43,16 @@ func newValue(w writer, scratch *[]byte, startElement StartElement) Value {
 // writeStartElement takes in a start element and writes it.
 // It handles namespace, attributes in start element.
 func writeStartElement(w writer, el StartElement) error {
+	return writeElementTag(w, el, false)
+}
+
+// writeEmptyElement takes in a start element and writes it as a self-closing
+// empty element tag.
+func writeEmptyElement(w writer, el StartElement) error {
+	return writeElementTag(w, el, true)
+}
+
+func writeElementTag(w writer, el StartElement, empty bool) error {
 	if el.isZero() {
 		return fmt.Errorf("xml start element cannot be nil")
 	}
@@ -56,6 +69,9 @@ func writeStartElement(w writer, el StartElement) error {
 		writeAttribute(w, &attr)
 	}
 
+	if empty {
+		w.WriteRune(forwardSlash)
+	}
 	w.WriteRune(rightAngleBracket)
 	return nil
 }
@@ -189,6 +205,22 @@ func (xv Value) BigDecimal(v *big.Float) {
 	xv.Close()
 }
 
+// Time encodes v as a XML string in the Smithy timestamp format, (e.g.
+// smithytime.DateTimeFormatName). Returns an error, without writing a value,
+// if the format is not supported.
+// It will auto close the parent xml element tag.
+func (xv Value) Time(v time.Time, format string) error {
+	defer xv.Close()
+
+	s, err := smithytime.FormatTimestamp(v, format)
+	if err != nil {
+		return err
+	}
+	escapeString(xv.w, s)
+
+	return nil
+}
+
 // Write writes v directly to the xml document
 // if escapeXMLText is set to true, write will escape text.
 // It will auto close the parent xml element tag.
@@ -213,6 +245,36 @@ func (xv Value) MemberElement(element StartElement) Value {
 	return newValue(xv.w, xv.scratch, element)
 }
 
+// OptionalMemberElement does member element encoding for an optional member.
+// If v is nil, or a nil pointer, map, or slice, the member is omitted and no
+// element tags are written. Otherwise the start element tag is written and fn
+// is called with the member's Value to encode v. fn must close the Value.
+//
+// Generated serializers should use OptionalMemberElement for optional
+// members, so that unset members are omitted instead of encoded as an empty
+// element.
+func (xv Value) OptionalMemberElement(element StartElement, v interface{}, fn func(Value)) {
+	if isNilValue(v) {
+		return
+	}
+	fn(xv.MemberElement(element))
+}
+
+// isNilValue returns if v is nil, or a nil pointer, map, or slice.
+func isNilValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
+
 // FlattenedElement returns flattened element encoding. It returns a Value.
 // This method should be used for flattened shapes.
 //
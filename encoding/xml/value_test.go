@@ -7,6 +7,9 @@ import (
 	"math/big"
 	"strconv"
 	"testing"
+	"time"
+
+	smithytime "github.com/aws/smithy-go/time"
 )
 
 var (
@@ -210,3 +213,117 @@ func TestWrappedValueWithNameSpaceAndAttributes(t *testing.T) {
 		t.Errorf("expected %+q, but got %+q", e, a)
 	}
 }
+
+func TestOptionalMemberElement(t *testing.T) {
+	type input struct {
+		Required string
+		Optional *string
+		List     []string
+	}
+
+	str := func(v string) *string { return &v }
+
+	cases := map[string]struct {
+		input    input
+		expected string
+	}{
+		"nil optional member": {
+			input:    input{Required: "foo"},
+			expected: `<root><required>foo</required></root>`,
+		},
+		"set optional member": {
+			input:    input{Required: "foo", Optional: str("bar")},
+			expected: `<root><required>foo</required><optional>bar</optional></root>`,
+		},
+		"empty optional member": {
+			input:    input{Required: "foo", Optional: str("")},
+			expected: `<root><required>foo</required><optional></optional></root>`,
+		},
+		"set list member": {
+			input:    input{Required: "foo", List: []string{"a"}},
+			expected: `<root><required>foo</required><list><member>a</member></list></root>`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			buffer := bytes.NewBuffer(nil)
+			scratch := make([]byte, 64)
+
+			func() {
+				root := StartElement{Name: Name{Local: "root"}}
+				object := newValue(buffer, &scratch, root)
+				defer object.Close()
+
+				object.MemberElement(StartElement{Name: Name{Local: "required"}}).String(c.input.Required)
+
+				object.OptionalMemberElement(StartElement{Name: Name{Local: "optional"}}, c.input.Optional,
+					func(v Value) {
+						v.String(*c.input.Optional)
+					})
+
+				object.OptionalMemberElement(StartElement{Name: Name{Local: "list"}}, c.input.List,
+					func(v Value) {
+						defer v.Close()
+						a := v.Array()
+						for _, m := range c.input.List {
+							a.Member().String(m)
+						}
+					})
+			}()
+
+			if e, a := []byte(c.expected), buffer.Bytes(); bytes.Compare(e, a) != 0 {
+				t.Errorf("expected %+q, but got %+q", e, a)
+			}
+		})
+	}
+}
+
+func TestValue_Time(t *testing.T) {
+	ts := time.Date(2023, 6, 1, 12, 30, 45, 123000000, time.UTC)
+
+	cases := map[string]struct {
+		format    string
+		expected  string
+		expectErr bool
+	}{
+		"date-time": {
+			format:   smithytime.DateTimeFormatName,
+			expected: `<root>2023-06-01T12:30:45.123Z</root>`,
+		},
+		"http-date": {
+			format:   smithytime.HTTPDateFormatName,
+			expected: `<root>Thu, 01 Jun 2023 12:30:45 GMT</root>`,
+		},
+		"epoch-seconds": {
+			format:   smithytime.EpochSecondsFormatName,
+			expected: `<root>1685622645.123</root>`,
+		},
+		"unsupported format": {
+			format:    "unix",
+			expected:  `<root></root>`,
+			expectErr: true,
+		},
+	}
+
+	for name, tt := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := bytes.NewBuffer(nil)
+			scratch := make([]byte, 64)
+			root := StartElement{Name: Name{Local: "root"}}
+
+			err := newValue(b, &scratch, root).Time(ts, tt.format)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected error, got none")
+				}
+			} else if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if e, a := []byte(tt.expected), b.Bytes(); bytes.Compare(e, a) != 0 {
+				t.Errorf("expected %+q, but got %+q", e, a)
+			}
+		})
+	}
+}
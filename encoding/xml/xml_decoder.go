@@ -103,6 +103,9 @@ func (d NodeDecoder) GetElement(name string) (t xml.StartElement, err error) {
 // Value provides an abstraction to retrieve char data value within an xml element.
 // The method will return an error if it encounters a nested xml element instead of char data.
 // This method should only be used to retrieve simple type or blob shape values as []byte.
+//
+// A nil value is returned for an empty element marked as an explicit null
+// with the xsi:nil attribute, distinguishing it from an empty value.
 func (d NodeDecoder) Value() (c []byte, err error) {
 	t, e := d.Decoder.Token()
 	if e != nil {
@@ -116,6 +119,9 @@ func (d NodeDecoder) Value() (c []byte, err error) {
 		c = ev.Copy()
 	case xml.EndElement: // end tag or self-closing
 		if ev == endElement {
+			if d.IsNil() {
+				return nil, err
+			}
 			return []byte{}, err
 		}
 		return c, fmt.Errorf("expected value for %v element, got %T type %v instead", d.StartEl.Name.Local, t, t)
@@ -0,0 +1,123 @@
+package smithy
+
+import (
+	"context"
+	"errors"
+)
+
+// Category provides the coarse classification of an error, for use by retry
+// and circuit breaker logic.
+type Category int
+
+// Category enumeration values
+const (
+	CategoryUnknown Category = iota
+	CategoryTransient
+	CategoryPermanent
+)
+
+func (c Category) String() string {
+	switch c {
+	case CategoryTransient:
+		return "transient"
+	case CategoryPermanent:
+		return "permanent"
+	default:
+		return "unknown"
+	}
+}
+
+// throttleErrorCodes is the set of API error codes that indicate the request
+// was throttled.
+var throttleErrorCodes = map[string]struct{}{
+	"Throttling":                             {},
+	"ThrottlingException":                    {},
+	"ThrottledException":                     {},
+	"RequestThrottledException":              {},
+	"TooManyRequestsException":               {},
+	"ProvisionedThroughputExceededException": {},
+	"TransactionInProgressException":         {},
+	"RequestLimitExceeded":                   {},
+	"BandwidthLimitExceeded":                 {},
+	"LimitExceededException":                 {},
+	"RequestThrottled":                       {},
+	"SlowDown":                               {},
+	"PriorRequestNotComplete":                {},
+	"EC2ThrottledException":                  {},
+}
+
+// ErrorCategory returns the category of the error. The error chain is
+// inspected in the following order, with the first match determining the
+// category.
+//
+//   - An error implementing RetryableError() bool is transient if true, and
+//     permanent if false.
+//   - Timeout errors, (e.g. Timeout() bool, or context.DeadlineExceeded) are
+//     transient.
+//   - Canceled errors, (e.g. CanceledError() bool, or context.Canceled) are
+//     permanent.
+//   - Connection errors, (e.g. ConnectionError() bool) are transient.
+//   - APIError with a throttling error code are transient.
+//   - Errors with an HTTP status code, (e.g. HTTPStatusCode() int) are
+//     transient if the status code is 429, or 5xx, and permanent if 4xx.
+//   - APIError with a server fault are transient, and client fault are
+//     permanent.
+//
+// Errors not matching any of these are unknown. A nil error is unknown.
+func ErrorCategory(err error) Category {
+	if err == nil {
+		return CategoryUnknown
+	}
+
+	var retryable interface{ RetryableError() bool }
+	if errors.As(err, &retryable) {
+		if retryable.RetryableError() {
+			return CategoryTransient
+		}
+		return CategoryPermanent
+	}
+
+	var timeout interface{ Timeout() bool }
+	if (errors.As(err, &timeout) && timeout.Timeout()) || errors.Is(err, context.DeadlineExceeded) {
+		return CategoryTransient
+	}
+
+	var canceled interface{ CanceledError() bool }
+	if (errors.As(err, &canceled) && canceled.CanceledError()) || errors.Is(err, context.Canceled) {
+		return CategoryPermanent
+	}
+
+	var conn interface{ ConnectionError() bool }
+	if errors.As(err, &conn) && conn.ConnectionError() {
+		return CategoryTransient
+	}
+
+	var apiErr APIError
+	isAPIErr := errors.As(err, &apiErr)
+	if isAPIErr {
+		if _, ok := throttleErrorCodes[apiErr.ErrorCode()]; ok {
+			return CategoryTransient
+		}
+	}
+
+	var status interface{ HTTPStatusCode() int }
+	if errors.As(err, &status) {
+		switch code := status.HTTPStatusCode(); {
+		case code == 429 || (code >= 500 && code < 600):
+			return CategoryTransient
+		case code >= 400 && code < 500:
+			return CategoryPermanent
+		}
+	}
+
+	if isAPIErr {
+		switch apiErr.ErrorFault() {
+		case FaultServer:
+			return CategoryTransient
+		case FaultClient:
+			return CategoryPermanent
+		}
+	}
+
+	return CategoryUnknown
+}
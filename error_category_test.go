@@ -0,0 +1,91 @@
+package smithy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+type mockRetryableError struct{ retryable bool }
+
+func (e mockRetryableError) RetryableError() bool { return e.retryable }
+func (e mockRetryableError) Error() string        { return "retryable error" }
+
+type mockConnectionError struct{}
+
+func (mockConnectionError) ConnectionError() bool { return true }
+func (mockConnectionError) Error() string         { return "connection error" }
+
+type mockStatusCodeError struct{ statusCode int }
+
+func (e mockStatusCodeError) HTTPStatusCode() int { return e.statusCode }
+func (e mockStatusCodeError) Error() string       { return fmt.Sprintf("status code %d", e.statusCode) }
+
+func TestErrorCategory(t *testing.T) {
+	cases := map[string]struct {
+		Err    error
+		Expect Category
+	}{
+		"nil": {
+			Expect: CategoryUnknown,
+		},
+		"unknown": {
+			Err:    fmt.Errorf("some error"),
+			Expect: CategoryUnknown,
+		},
+		"retryable": {
+			Err:    fmt.Errorf("wrapped, %w", mockRetryableError{retryable: true}),
+			Expect: CategoryTransient,
+		},
+		"not retryable": {
+			Err:    &OperationError{Err: mockRetryableError{retryable: false}},
+			Expect: CategoryPermanent,
+		},
+		"throttling": {
+			Err: &OperationError{Err: &GenericAPIError{
+				Code: "ThrottlingException", Fault: FaultClient,
+			}},
+			Expect: CategoryTransient,
+		},
+		"timeout": {
+			Err:    &TimeoutError{Err: context.DeadlineExceeded},
+			Expect: CategoryTransient,
+		},
+		"canceled": {
+			Err:    &CanceledError{Err: context.Canceled},
+			Expect: CategoryPermanent,
+		},
+		"connection": {
+			Err:    fmt.Errorf("send failed, %w", mockConnectionError{}),
+			Expect: CategoryTransient,
+		},
+		"5xx status": {
+			Err:    mockStatusCodeError{statusCode: 503},
+			Expect: CategoryTransient,
+		},
+		"429 status": {
+			Err:    mockStatusCodeError{statusCode: 429},
+			Expect: CategoryTransient,
+		},
+		"4xx status": {
+			Err:    mockStatusCodeError{statusCode: 404},
+			Expect: CategoryPermanent,
+		},
+		"server fault": {
+			Err:    &GenericAPIError{Code: "InternalFailure", Fault: FaultServer},
+			Expect: CategoryTransient,
+		},
+		"client fault": {
+			Err:    &GenericAPIError{Code: "ValidationException", Fault: FaultClient},
+			Expect: CategoryPermanent,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if e, a := c.Expect, ErrorCategory(c.Err); e != a {
+				t.Errorf("expect %v category, got %v", e, a)
+			}
+		})
+	}
+}
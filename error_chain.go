@@ -0,0 +1,64 @@
+package smithy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorChain returns the chain of errors wrapped by err, starting with err
+// itself, and followed by each error returned by successive calls to Unwrap.
+// Returns nil if err is nil.
+func ErrorChain(err error) []error {
+	var chain []error
+	for err != nil {
+		chain = append(chain, err)
+		err = errors.Unwrap(err)
+	}
+	return chain
+}
+
+// FormatErrorChain returns a multi-line rendering of the chain of errors
+// wrapped by err, from outermost to innermost, for logging. Each line
+// includes the error's position in the chain, type, and message. The code,
+// and fault of API errors, and the request ID of errors that have one, are
+// included when available.
+//
+// Returns an empty string if err is nil.
+func FormatErrorChain(err error) string {
+	var sb strings.Builder
+	for i, e := range ErrorChain(err) {
+		if i != 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "%s[%d] %T: %v", strings.Repeat("  ", i), i, e, e)
+
+		var attrs []string
+		if apiErr, ok := e.(APIError); ok {
+			attrs = append(attrs,
+				"code="+apiErr.ErrorCode(),
+				"fault="+apiErr.ErrorFault().String(),
+			)
+		}
+		if id := errorRequestID(e); len(id) != 0 {
+			attrs = append(attrs, "request id="+id)
+		}
+		if len(attrs) != 0 {
+			fmt.Fprintf(&sb, " (%s)", strings.Join(attrs, ", "))
+		}
+	}
+	return sb.String()
+}
+
+// errorRequestID returns the service request ID of the error if the error
+// provides one.
+func errorRequestID(err error) string {
+	switch e := err.(type) {
+	case interface{ ServiceRequestID() string }:
+		return e.ServiceRequestID()
+	case interface{ RequestID() string }:
+		return e.RequestID()
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,61 @@
+package smithy
+
+import (
+	"fmt"
+	"testing"
+)
+
+type mockRequestIDError struct {
+	Err error
+	ID  string
+}
+
+func (e *mockRequestIDError) Error() string {
+	return fmt.Sprintf("request failed, %v", e.Err)
+}
+func (e *mockRequestIDError) Unwrap() error            { return e.Err }
+func (e *mockRequestIDError) ServiceRequestID() string { return e.ID }
+
+func TestErrorChain(t *testing.T) {
+	apiErr := &GenericAPIError{
+		Code:    "NoSuchThing",
+		Message: "thing does not exist",
+		Fault:   FaultClient,
+	}
+	responseErr := &mockRequestIDError{Err: apiErr, ID: "abc123"}
+	opErr := &OperationError{
+		ServiceID:     "Things",
+		OperationName: "GetThing",
+		Err:           responseErr,
+	}
+
+	chain := ErrorChain(opErr)
+	expect := []error{opErr, responseErr, apiErr}
+	if e, a := len(expect), len(chain); e != a {
+		t.Fatalf("expect %v errors in chain, got %v", e, a)
+	}
+	for i := range expect {
+		if e, a := expect[i], chain[i]; e != a {
+			t.Errorf("expect %d error %v, got %v", i, e, a)
+		}
+	}
+
+	expectFormat := "[0] *smithy.OperationError: operation error Things: GetThing, " +
+		"request failed, api error NoSuchThing: thing does not exist\n" +
+		"  [1] *smithy.mockRequestIDError: request failed, " +
+		"api error NoSuchThing: thing does not exist (request id=abc123)\n" +
+		"    [2] *smithy.GenericAPIError: api error NoSuchThing: thing does not exist " +
+		"(code=NoSuchThing, fault=client)"
+	if e, a := expectFormat, FormatErrorChain(opErr); e != a {
+		t.Errorf("expect format\n%s\ngot\n%s", e, a)
+	}
+}
+
+func TestErrorChain_Nil(t *testing.T) {
+	if chain := ErrorChain(nil); chain != nil {
+		t.Errorf("expect nil chain, got %v", chain)
+	}
+	if e, a := "", FormatErrorChain(nil); e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+}
@@ -0,0 +1,23 @@
+package smithy
+
+import "errors"
+
+// ErrorCodeMatcher returns a predicate reporting if an error's chain includes
+// an APIError with any of the error codes. The predicate can be used in tests,
+// and retry configuration to match a set of error codes in a single call.
+// The predicate returns false for a nil error.
+func ErrorCodeMatcher(codes ...string) func(error) bool {
+	set := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		set[code] = struct{}{}
+	}
+
+	return func(err error) bool {
+		var apiErr APIError
+		if !errors.As(err, &apiErr) {
+			return false
+		}
+		_, ok := set[apiErr.ErrorCode()]
+		return ok
+	}
+}
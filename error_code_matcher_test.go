@@ -0,0 +1,55 @@
+package smithy
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestErrorCodeMatcher(t *testing.T) {
+	matcher := ErrorCodeMatcher("ThrottlingException", "RequestTimeout")
+
+	cases := map[string]struct {
+		Err    error
+		Expect bool
+	}{
+		"matching code": {
+			Err:    &GenericAPIError{Code: "ThrottlingException"},
+			Expect: true,
+		},
+		"other matching code": {
+			Err:    &GenericAPIError{Code: "RequestTimeout"},
+			Expect: true,
+		},
+		"wrapped matching code": {
+			Err: &OperationError{
+				OperationName: "Op",
+				Err:           fmt.Errorf("wrapped, %w", &GenericAPIError{Code: "RequestTimeout"}),
+			},
+			Expect: true,
+		},
+		"other code": {
+			Err: &GenericAPIError{Code: "ValidationException"},
+		},
+		"code differs in case": {
+			Err: &GenericAPIError{Code: "throttlingexception"},
+		},
+		"not API error": {
+			Err: fmt.Errorf("ThrottlingException"),
+		},
+		"nil": {},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if e, a := c.Expect, matcher(c.Err); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestErrorCodeMatcher_NoCodes(t *testing.T) {
+	if ErrorCodeMatcher()(&GenericAPIError{Code: "ThrottlingException"}) {
+		t.Errorf("expect no match")
+	}
+}
@@ -0,0 +1,46 @@
+package smithy
+
+import "fmt"
+
+// EndpointResolutionError is the error returned when resolving the endpoint
+// of an operation's request fails, distinguishing the failure from a failure
+// sending the request. The error wraps the resolver's error.
+type EndpointResolutionError struct {
+	// ServiceID is the name of the service the endpoint was resolved for, if
+	// known.
+	ServiceID string
+
+	// Region is the region the endpoint was resolved for, if known.
+	Region string
+
+	Err error
+}
+
+// NewEndpointResolutionError returns the endpoint resolver's error wrapped in
+// an EndpointResolutionError decorated with the service, and region the
+// endpoint was resolved for. Returns nil if err is nil.
+func NewEndpointResolutionError(service, region string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &EndpointResolutionError{
+		ServiceID: service,
+		Region:    region,
+		Err:       err,
+	}
+}
+
+// Unwrap returns the endpoint resolver's error.
+func (e *EndpointResolutionError) Unwrap() error { return e.Err }
+
+func (e *EndpointResolutionError) Error() string {
+	msg := "endpoint resolution failed"
+	if len(e.ServiceID) != 0 {
+		msg += fmt.Sprintf(" for service %s", e.ServiceID)
+	}
+	if len(e.Region) != 0 {
+		msg += fmt.Sprintf(" in region %s", e.Region)
+	}
+	return fmt.Sprintf("%s, %v", msg, e.Err)
+}
@@ -0,0 +1,61 @@
+package smithy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewEndpointResolutionError(t *testing.T) {
+	cause := errors.New("no partition found")
+
+	cases := map[string]struct {
+		Service, Region string
+		Expect          string
+	}{
+		"service and region": {
+			Service: "S3",
+			Region:  "us-west-2",
+			Expect:  "endpoint resolution failed for service S3 in region us-west-2, no partition found",
+		},
+		"service only": {
+			Service: "S3",
+			Expect:  "endpoint resolution failed for service S3, no partition found",
+		},
+		"region only": {
+			Region: "us-west-2",
+			Expect: "endpoint resolution failed in region us-west-2, no partition found",
+		},
+		"no context": {
+			Expect: "endpoint resolution failed, no partition found",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := WrapAPIError(NewEndpointResolutionError(c.Service, c.Region, cause), "GetObject", c.Service, "")
+
+			var resolveErr *EndpointResolutionError
+			if !errors.As(err, &resolveErr) {
+				t.Fatalf("expect EndpointResolutionError, got %T", err)
+			}
+			if e, a := c.Service, resolveErr.ServiceID; e != a {
+				t.Errorf("expect %v service, got %v", e, a)
+			}
+			if e, a := c.Region, resolveErr.Region; e != a {
+				t.Errorf("expect %v region, got %v", e, a)
+			}
+			if !errors.Is(err, cause) {
+				t.Errorf("expect wrapped cause, got %v", err)
+			}
+			if e, a := c.Expect, resolveErr.Error(); e != a {
+				t.Errorf("expect %q error, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestNewEndpointResolutionError_Nil(t *testing.T) {
+	if err := NewEndpointResolutionError("S3", "us-west-2", nil); err != nil {
+		t.Errorf("expect nil error, got %v", err)
+	}
+}
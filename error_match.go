@@ -0,0 +1,33 @@
+package smithy
+
+// AnyErrorMatch returns a predicate reporting if any of the predicates match
+// the error. The predicates are called in order, stopping at the first match.
+// The predicate returns false if no predicates are provided.
+//
+// AnyErrorMatch and AllErrorMatch can be composed to build retryable error
+// rules, (e.g. a throttling error code, or any connection error).
+func AnyErrorMatch(preds ...func(error) bool) func(error) bool {
+	return func(err error) bool {
+		for _, pred := range preds {
+			if pred(err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AllErrorMatch returns a predicate reporting if all of the predicates match
+// the error. The predicates are called in order, stopping at the first
+// predicate that does not match. The predicate returns true if no predicates
+// are provided.
+func AllErrorMatch(preds ...func(error) bool) func(error) bool {
+	return func(err error) bool {
+		for _, pred := range preds {
+			if !pred(err) {
+				return false
+			}
+		}
+		return true
+	}
+}
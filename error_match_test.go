@@ -0,0 +1,108 @@
+package smithy
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestErrorMatchCombinators(t *testing.T) {
+	var called []string
+	pred := func(name string, result bool) func(error) bool {
+		return func(error) bool {
+			called = append(called, name)
+			return result
+		}
+	}
+
+	cases := map[string]struct {
+		Match        func(error) bool
+		Expect       bool
+		ExpectCalled []string
+	}{
+		"any short circuits on match": {
+			Match:        AnyErrorMatch(pred("a", false), pred("b", true), pred("c", true)),
+			Expect:       true,
+			ExpectCalled: []string{"a", "b"},
+		},
+		"any no match": {
+			Match:        AnyErrorMatch(pred("a", false), pred("b", false)),
+			ExpectCalled: []string{"a", "b"},
+		},
+		"any empty": {
+			Match: AnyErrorMatch(),
+		},
+		"all short circuits on mismatch": {
+			Match:        AllErrorMatch(pred("a", true), pred("b", false), pred("c", true)),
+			ExpectCalled: []string{"a", "b"},
+		},
+		"all match": {
+			Match:        AllErrorMatch(pred("a", true), pred("b", true)),
+			Expect:       true,
+			ExpectCalled: []string{"a", "b"},
+		},
+		"all empty": {
+			Match:  AllErrorMatch(),
+			Expect: true,
+		},
+		"composed": {
+			Match: AnyErrorMatch(
+				AllErrorMatch(pred("a", true), pred("b", false)),
+				AllErrorMatch(pred("c", true), pred("d", true)),
+				pred("e", true),
+			),
+			Expect:       true,
+			ExpectCalled: []string{"a", "b", "c", "d"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			called = nil
+
+			if e, a := c.Expect, c.Match(fmt.Errorf("some error")); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+			if e, a := c.ExpectCalled, called; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v called, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestErrorMatchCombinators_ErrorCodes(t *testing.T) {
+	retryable := AnyErrorMatch(
+		ErrorCodeMatcher("ThrottlingException"),
+		AllErrorMatch(IsServiceFailure, func(err error) bool {
+			return !ErrorCodeMatcher("InternalFailure")(err)
+		}),
+	)
+
+	cases := map[string]struct {
+		Err    error
+		Expect bool
+	}{
+		"throttled": {
+			Err:    &GenericAPIError{Code: "ThrottlingException", Fault: FaultClient},
+			Expect: true,
+		},
+		"server fault": {
+			Err:    &GenericAPIError{Code: "ServiceUnavailable", Fault: FaultServer},
+			Expect: true,
+		},
+		"excluded server fault": {
+			Err: &GenericAPIError{Code: "InternalFailure", Fault: FaultServer},
+		},
+		"client fault": {
+			Err: &GenericAPIError{Code: "ValidationException", Fault: FaultClient},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if e, a := c.Expect, retryable(c.Err); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package smithy
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RedactedValue is the value that sensitive content in the message of a
+// RedactingError is replaced with.
+const RedactedValue = "[REDACTED]"
+
+// RedactingError wraps an error, masking sensitive content, (e.g. secrets
+// echoed from the request), in the error's message. Only the message is
+// masked; the wrapped error is unmodified, and remains available to
+// errors.As, errors.Is, and Unwrap.
+type RedactingError struct {
+	Err error
+
+	// Patterns are the regular expressions whose matches are masked.
+	Patterns []*regexp.Regexp
+
+	// Substrings are the literal values that are masked.
+	Substrings []string
+}
+
+// NewRedactingError returns the error wrapped in a RedactingError masking
+// each of the substrings in the error's message. Returns nil if err is nil.
+func NewRedactingError(err error, substrings ...string) error {
+	if err == nil {
+		return nil
+	}
+	return &RedactingError{Err: err, Substrings: substrings}
+}
+
+// Error returns the wrapped error's message with sensitive content masked.
+func (e *RedactingError) Error() string {
+	if e.Err == nil {
+		return ""
+	}
+
+	msg := e.Err.Error()
+	for _, s := range e.Substrings {
+		if len(s) == 0 {
+			continue
+		}
+		msg = strings.ReplaceAll(msg, s, RedactedValue)
+	}
+	for _, p := range e.Patterns {
+		msg = p.ReplaceAllLiteralString(msg, RedactedValue)
+	}
+	return msg
+}
+
+// Unwrap returns the wrapped, unmasked, error.
+func (e *RedactingError) Unwrap() error { return e.Err }
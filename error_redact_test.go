@@ -0,0 +1,85 @@
+package smithy
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+)
+
+func TestRedactingError(t *testing.T) {
+	cause := &GenericAPIError{
+		Code:    "InvalidToken",
+		Message: "token abc123 rejected for key AKIDEXAMPLE123456789",
+	}
+
+	cases := map[string]struct {
+		Err    *RedactingError
+		Expect string
+	}{
+		"substrings": {
+			Err: &RedactingError{
+				Err:        cause,
+				Substrings: []string{"abc123", ""},
+			},
+			Expect: "api error InvalidToken: token [REDACTED] rejected for key AKIDEXAMPLE123456789",
+		},
+		"patterns": {
+			Err: &RedactingError{
+				Err:      cause,
+				Patterns: []*regexp.Regexp{regexp.MustCompile(`AKID[A-Z0-9]{16}`)},
+			},
+			Expect: "api error InvalidToken: token abc123 rejected for key [REDACTED]",
+		},
+		"both": {
+			Err: &RedactingError{
+				Err:        cause,
+				Patterns:   []*regexp.Regexp{regexp.MustCompile(`AKID[A-Z0-9]{16}`)},
+				Substrings: []string{"abc123"},
+			},
+			Expect: "api error InvalidToken: token [REDACTED] rejected for key [REDACTED]",
+		},
+		"no match": {
+			Err: &RedactingError{
+				Err:        cause,
+				Substrings: []string{"secret"},
+			},
+			Expect: cause.Error(),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if e, a := c.Expect, c.Err.Error(); e != a {
+				t.Errorf("expect %q, got %q", e, a)
+			}
+
+			err := fmt.Errorf("wrapped, %w", c.Err)
+			var apiErr *GenericAPIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expect %T in chain, got %v", apiErr, err)
+			}
+			if e, a := cause, apiErr; e != a {
+				t.Errorf("expect unwrapped cause %v, got %v", e, a)
+			}
+			if e, a := cause.Message, apiErr.ErrorMessage(); e != a {
+				t.Errorf("expect unmasked message %q, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestNewRedactingError(t *testing.T) {
+	if err := NewRedactingError(nil, "secret"); err != nil {
+		t.Errorf("expect nil error, got %v", err)
+	}
+
+	cause := errors.New("bad secret value")
+	err := NewRedactingError(cause, "secret")
+	if e, a := "bad [REDACTED] value", err.Error(); e != a {
+		t.Errorf("expect %q, got %q", e, a)
+	}
+	if e, a := cause, errors.Unwrap(err); e != a {
+		t.Errorf("expect %v, got %v", e, a)
+	}
+}
@@ -0,0 +1,25 @@
+package smithy
+
+import (
+	"errors"
+	"time"
+)
+
+// RetryAfterError provides the interface for errors carrying a hint from the
+// service of how long the client should wait before retrying the request,
+// (e.g. the HTTP Retry-After header).
+type RetryAfterError interface {
+	error
+	RetryAfter() time.Duration
+}
+
+// GetRetryAfter returns the retry-after duration of the first RetryAfterError
+// in the error's chain. Returns false if the error chain does not include a
+// RetryAfterError.
+func GetRetryAfter(err error) (time.Duration, bool) {
+	var v RetryAfterError
+	if !errors.As(err, &v) {
+		return 0, false
+	}
+	return v.RetryAfter(), true
+}
@@ -0,0 +1,58 @@
+package smithy
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+type mockRetryAfterError struct {
+	error
+	retryAfter time.Duration
+}
+
+func (e mockRetryAfterError) RetryAfter() time.Duration { return e.retryAfter }
+
+func TestGetRetryAfter(t *testing.T) {
+	cases := map[string]struct {
+		Err         error
+		Expect      time.Duration
+		ExpectFound bool
+	}{
+		"nil": {},
+		"no retry after": {
+			Err: fmt.Errorf("some error"),
+		},
+		"retry after": {
+			Err:         mockRetryAfterError{error: fmt.Errorf("throttled"), retryAfter: 5 * time.Second},
+			Expect:      5 * time.Second,
+			ExpectFound: true,
+		},
+		"wrapped retry after": {
+			Err: &OperationError{
+				ServiceID:     "foo",
+				OperationName: "bar",
+				Err: fmt.Errorf("wrapped, %w",
+					mockRetryAfterError{error: fmt.Errorf("throttled"), retryAfter: time.Minute}),
+			},
+			Expect:      time.Minute,
+			ExpectFound: true,
+		},
+		"zero retry after": {
+			Err:         mockRetryAfterError{error: fmt.Errorf("throttled")},
+			ExpectFound: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, found := GetRetryAfter(c.Err)
+			if e, a := c.ExpectFound, found; e != a {
+				t.Fatalf("expect %v found, got %v", e, a)
+			}
+			if e, a := c.Expect, actual; e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package smithy
+
+import (
+	"context"
+	"errors"
+)
+
+// IsServiceFailure returns if the error should be counted as a failure of the
+// service, (e.g. by a circuit breaker). Server errors, connection errors, and
+// timeouts are failures. Client errors, such as a 404 not found, or a
+// canceled Context are expected, and are not failures. See
+// ServiceFailureMatcher for the classification rules.
+func IsServiceFailure(err error) bool {
+	return isServiceFailure(err, nil)
+}
+
+// ServiceFailureMatcher returns a predicate like IsServiceFailure, except
+// that errors with an APIError error code in excludedCodes are never counted
+// as service failures, (e.g. a modeled 503 error code the application
+// expects).
+//
+// The predicate inspects the error chain in the following order, with the
+// first match determining the result.
+//
+//   - A nil error, or an APIError with an excluded error code, is not a
+//     failure.
+//   - Canceled errors, (e.g. CanceledError() bool, or context.Canceled) are
+//     not failures.
+//   - Timeout errors, (e.g. Timeout() bool, context.DeadlineExceeded, or a
+//     server timeout, see IsServerTimeout) are failures.
+//   - Connection errors, (e.g. ConnectionError() bool) are failures.
+//   - Errors with an HTTP status code, (e.g. HTTPStatusCode() int) are
+//     failures if the status code is 5xx, and not failures if 4xx.
+//   - APIError with a server fault are failures, and client fault are not.
+//
+// Errors not matching any of these are not failures.
+func ServiceFailureMatcher(excludedCodes ...string) func(error) bool {
+	excluded := make(map[string]struct{}, len(excludedCodes))
+	for _, code := range excludedCodes {
+		excluded[code] = struct{}{}
+	}
+
+	return func(err error) bool {
+		return isServiceFailure(err, excluded)
+	}
+}
+
+func isServiceFailure(err error, excludedCodes map[string]struct{}) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr APIError
+	isAPIErr := errors.As(err, &apiErr)
+	if isAPIErr {
+		if _, ok := excludedCodes[apiErr.ErrorCode()]; ok {
+			return false
+		}
+	}
+
+	var canceled interface{ CanceledError() bool }
+	if (errors.As(err, &canceled) && canceled.CanceledError()) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var timeout interface{ Timeout() bool }
+	if (errors.As(err, &timeout) && timeout.Timeout()) || IsContextTimeout(err) || IsServerTimeout(err) {
+		return true
+	}
+
+	var conn interface{ ConnectionError() bool }
+	if errors.As(err, &conn) && conn.ConnectionError() {
+		return true
+	}
+
+	var status interface{ HTTPStatusCode() int }
+	if errors.As(err, &status) {
+		switch code := status.HTTPStatusCode(); {
+		case code >= 500 && code < 600:
+			return true
+		case code >= 400 && code < 500:
+			return false
+		}
+	}
+
+	return isAPIErr && apiErr.ErrorFault() == FaultServer
+}
@@ -0,0 +1,75 @@
+package smithy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestIsServiceFailure(t *testing.T) {
+	cases := map[string]struct {
+		Err           error
+		ExcludedCodes []string
+		Expect        bool
+	}{
+		"nil": {},
+		"500": {
+			Err:    mockStatusCodeError{statusCode: 500},
+			Expect: true,
+		},
+		"503 api error": {
+			Err:    &GenericAPIError{Code: "ServiceUnavailable", StatusCode: 503},
+			Expect: true,
+		},
+		"404": {
+			Err: mockStatusCodeError{statusCode: 404},
+		},
+		"404 api error": {
+			Err: &GenericAPIError{Code: "NotFound", StatusCode: 404, Fault: FaultClient},
+		},
+		"connection error": {
+			Err:    fmt.Errorf("send failed, %w", mockConnectionError{}),
+			Expect: true,
+		},
+		"context timeout": {
+			Err:    fmt.Errorf("request failed, %w", context.DeadlineExceeded),
+			Expect: true,
+		},
+		"server timeout code": {
+			Err:    &GenericAPIError{Code: "RequestTimeout", Fault: FaultClient},
+			Expect: true,
+		},
+		"canceled": {
+			Err: fmt.Errorf("request failed, %w", context.Canceled),
+		},
+		"server fault": {
+			Err:    &GenericAPIError{Code: "InternalFailure", Fault: FaultServer},
+			Expect: true,
+		},
+		"unknown error": {
+			Err: fmt.Errorf("some error"),
+		},
+		"excluded code": {
+			Err:           &GenericAPIError{Code: "ServiceUnavailable", StatusCode: 503},
+			ExcludedCodes: []string{"ServiceUnavailable"},
+		},
+		"not excluded code": {
+			Err:           &GenericAPIError{Code: "InternalError", StatusCode: 500},
+			ExcludedCodes: []string{"ServiceUnavailable"},
+			Expect:        true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			isFailure := IsServiceFailure
+			if len(c.ExcludedCodes) != 0 {
+				isFailure = ServiceFailureMatcher(c.ExcludedCodes...)
+			}
+
+			if e, a := c.Expect, isFailure(c.Err); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package smithy
+
+import (
+	"context"
+	"errors"
+)
+
+// serverTimeoutErrorCodes is the set of API error codes that indicate the
+// service timed out processing the request.
+var serverTimeoutErrorCodes = map[string]struct{}{
+	"RequestTimeout":          {},
+	"RequestTimeoutException": {},
+	"TimeoutException":        {},
+	"GatewayTimeout":          {},
+	"GatewayTimeoutException": {},
+}
+
+// IsContextTimeout returns if the error was caused by the client's Context
+// deadline being exceeded, (e.g. the error chain includes
+// context.DeadlineExceeded). Retrying a context timeout with the same Context
+// will not succeed.
+func IsContextTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// IsServerTimeout returns if the error was returned by the service because it
+// timed out processing the request. The error chain must include an APIError
+// with a server timeout error code, (e.g. RequestTimeout), or an error with
+// the HTTP status code 408 or 504, (e.g. HTTPStatusCode() int).
+//
+// Context timeouts are not server timeouts, see IsContextTimeout.
+func IsServerTimeout(err error) bool {
+	if err == nil || IsContextTimeout(err) {
+		return false
+	}
+
+	var apiErr APIError
+	if errors.As(err, &apiErr) {
+		if _, ok := serverTimeoutErrorCodes[apiErr.ErrorCode()]; ok {
+			return true
+		}
+	}
+
+	var status interface{ HTTPStatusCode() int }
+	if errors.As(err, &status) {
+		switch status.HTTPStatusCode() {
+		case 408, 504:
+			return true
+		}
+	}
+
+	return false
+}
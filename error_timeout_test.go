@@ -0,0 +1,74 @@
+package smithy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+type mockStatusError struct {
+	StatusCode int
+}
+
+func (e *mockStatusError) Error() string       { return fmt.Sprintf("status %d", e.StatusCode) }
+func (e *mockStatusError) HTTPStatusCode() int { return e.StatusCode }
+
+func TestTimeoutClassification(t *testing.T) {
+	cases := map[string]struct {
+		Err                  error
+		ExpectContextTimeout bool
+		ExpectServerTimeout  bool
+	}{
+		"nil": {},
+		"context deadline exceeded": {
+			Err:                  context.DeadlineExceeded,
+			ExpectContextTimeout: true,
+		},
+		"wrapped context deadline exceeded": {
+			Err: &OperationError{
+				OperationName: "Op",
+				Err:           &CanceledError{Err: context.DeadlineExceeded},
+			},
+			ExpectContextTimeout: true,
+		},
+		"context canceled": {
+			Err: &CanceledError{Err: context.Canceled},
+		},
+		"server timeout code": {
+			Err: &OperationError{
+				OperationName: "Op",
+				Err:           &GenericAPIError{Code: "RequestTimeout"},
+			},
+			ExpectServerTimeout: true,
+		},
+		"timeout exception code": {
+			Err:                 &GenericAPIError{Code: "TimeoutException"},
+			ExpectServerTimeout: true,
+		},
+		"gateway timeout status": {
+			Err:                 fmt.Errorf("wrapped, %w", &mockStatusError{StatusCode: 504}),
+			ExpectServerTimeout: true,
+		},
+		"request timeout status": {
+			Err:                 &mockStatusError{StatusCode: 408},
+			ExpectServerTimeout: true,
+		},
+		"other API error": {
+			Err: &GenericAPIError{Code: "ValidationException", StatusCode: 400},
+		},
+		"other error": {
+			Err: fmt.Errorf("some error"),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if e, a := c.ExpectContextTimeout, IsContextTimeout(c.Err); e != a {
+				t.Errorf("expect context timeout %v, got %v", e, a)
+			}
+			if e, a := c.ExpectServerTimeout, IsServerTimeout(c.Err); e != a {
+				t.Errorf("expect server timeout %v, got %v", e, a)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package smithy
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
 
 // APIError provides the generic API and protocol agnostic error type all SDK
 // generated exception types will implement.
@@ -22,6 +26,36 @@ type GenericAPIError struct {
 	Code    string
 	Message string
 	Fault   ErrorFault
+
+	// StatusCode is the status code of the response the error was
+	// deserialized from. Zero if unknown.
+	StatusCode int
+
+	// Fields are additional service specific members of the error response
+	// beyond the code and message, (e.g. the ARN of the resource the error
+	// refers to). Included in the error's message, sorted by key.
+	Fields map[string]string
+}
+
+// APIErrorFromFields returns an APIError for the code and message fields of
+// a protocol error document, and the status code of the response the document
+// was received in. The error's fault is derived from the status code, with 4xx
+// status codes a client fault, and 5xx status codes a server fault.
+func APIErrorFromFields(code, message string, statusCode int) APIError {
+	fault := FaultUnknown
+	switch {
+	case statusCode >= 400 && statusCode < 500:
+		fault = FaultClient
+	case statusCode >= 500 && statusCode < 600:
+		fault = FaultServer
+	}
+
+	return &GenericAPIError{
+		Code:       code,
+		Message:    message,
+		Fault:      fault,
+		StatusCode: statusCode,
+	}
 }
 
 // ErrorCode returns the error code for the API exception.
@@ -33,8 +67,31 @@ func (e *GenericAPIError) ErrorMessage() string { return e.Message }
 // ErrorFault returns the fault for the API exception.
 func (e *GenericAPIError) ErrorFault() ErrorFault { return e.Fault }
 
+// HTTPStatusCode returns the status code of the response the error was
+// deserialized from, or zero if unknown.
+func (e *GenericAPIError) HTTPStatusCode() int { return e.StatusCode }
+
+// ErrorFields returns the additional service specific members of the error
+// response, or nil if there are none.
+func (e *GenericAPIError) ErrorFields() map[string]string { return e.Fields }
+
 func (e *GenericAPIError) Error() string {
-	return fmt.Sprintf("api error %s: %s", e.Code, e.Message)
+	if len(e.Fields) == 0 {
+		return fmt.Sprintf("api error %s: %s", e.Code, e.Message)
+	}
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fields := make([]string, len(keys))
+	for i, k := range keys {
+		fields[i] = k + "=" + e.Fields[k]
+	}
+
+	return fmt.Sprintf("api error %s: %s (%s)", e.Code, e.Message, strings.Join(fields, ", "))
 }
 
 var _ APIError = (*GenericAPIError)(nil)
@@ -44,7 +101,29 @@ var _ APIError = (*GenericAPIError)(nil)
 type OperationError struct {
 	ServiceID     string
 	OperationName string
-	Err           error
+
+	// RequestID is the service's identifier of the request the error
+	// occurred with, if known.
+	RequestID string
+
+	Err error
+}
+
+// WrapAPIError returns the error wrapped in an OperationError decorated with
+// the operation, and service names, and the service's request ID. The wrapped
+// error remains available to errors.As and errors.Is. Returns nil if err is
+// nil.
+func WrapAPIError(err error, operation, service, requestID string) error {
+	if err == nil {
+		return nil
+	}
+
+	return &OperationError{
+		ServiceID:     service,
+		OperationName: operation,
+		RequestID:     requestID,
+		Err:           err,
+	}
 }
 
 // Service returns the name of the API service the error occurred with.
@@ -53,10 +132,18 @@ func (e *OperationError) Service() string { return e.ServiceID }
 // Operation returns the name of the API operation the error occurred with.
 func (e *OperationError) Operation() string { return e.OperationName }
 
+// ServiceRequestID returns the service's identifier of the request the error
+// occurred with, or empty string if not known.
+func (e *OperationError) ServiceRequestID() string { return e.RequestID }
+
 // Unwrap returns the nested error if any, or nil.
 func (e *OperationError) Unwrap() error { return e.Err }
 
 func (e *OperationError) Error() string {
+	if len(e.RequestID) != 0 {
+		return fmt.Sprintf("operation error %s: %s, RequestID: %s, %v",
+			e.ServiceID, e.OperationName, e.RequestID, e.Err)
+	}
 	return fmt.Sprintf("operation error %s: %s, %v", e.ServiceID, e.OperationName, e.Err)
 }
 
@@ -102,12 +189,19 @@ func (f ErrorFault) String() string {
 
 // SerializationError represents an error that occurred while attempting to serialize a request
 type SerializationError struct {
+	// OperationName is the name of the operation whose request failed to
+	// serialize, if known.
+	OperationName string
+
 	Err error // original error
 }
 
 // Error returns a formatted error for SerializationError
 func (e *SerializationError) Error() string {
-	const msg = "serialization failed"
+	msg := "serialization failed"
+	if len(e.OperationName) != 0 {
+		msg = fmt.Sprintf("operation %s serialization failed", e.OperationName)
+	}
 	if e.Err == nil {
 		return msg
 	}
@@ -121,6 +215,9 @@ func (e *SerializationError) Unwrap() error { return e.Err }
 // canceled. API operations given a Context may return this error when
 // canceled.
 type CanceledError struct {
+	// OperationName is the name of the operation that was canceled, if known.
+	OperationName string
+
 	Err error
 }
 
@@ -133,5 +230,32 @@ func (e *CanceledError) Unwrap() error {
 }
 
 func (e *CanceledError) Error() string {
+	if len(e.OperationName) != 0 {
+		return fmt.Sprintf("operation %s canceled, %v", e.OperationName, e.Err)
+	}
 	return fmt.Sprintf("canceled, %v", e.Err)
 }
+
+// TimeoutError is the error that will be returned by an API request that did
+// not complete before its Context's deadline was exceeded.
+type TimeoutError struct {
+	// OperationName is the name of the operation that timed out, if known.
+	OperationName string
+
+	Err error
+}
+
+// Timeout returns true to satisfy interfaces checking for timeout errors.
+func (*TimeoutError) Timeout() bool { return true }
+
+// Unwrap returns the underlying error, if there was one.
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+func (e *TimeoutError) Error() string {
+	if len(e.OperationName) != 0 {
+		return fmt.Sprintf("operation %s timeout, %v", e.OperationName, e.Err)
+	}
+	return fmt.Sprintf("timeout, %v", e.Err)
+}
@@ -0,0 +1,163 @@
+package smithy
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWrapAPIError(t *testing.T) {
+	apiErr := &GenericAPIError{
+		Code:    "NoSuchThing",
+		Message: "thing does not exist",
+		Fault:   FaultClient,
+	}
+
+	err := WrapAPIError(apiErr, "GetThing", "Things", "abc123")
+
+	var opErr *OperationError
+	if !errors.As(err, &opErr) {
+		t.Fatalf("expect %T error, got %v", opErr, err)
+	}
+	if e, a := "GetThing", opErr.Operation(); e != a {
+		t.Errorf("expect %v operation, got %v", e, a)
+	}
+	if e, a := "Things", opErr.Service(); e != a {
+		t.Errorf("expect %v service, got %v", e, a)
+	}
+	if e, a := "abc123", opErr.ServiceRequestID(); e != a {
+		t.Errorf("expect %v request ID, got %v", e, a)
+	}
+
+	var actualAPIErr APIError
+	if !errors.As(err, &actualAPIErr) {
+		t.Fatalf("expect %T error, got %v", actualAPIErr, err)
+	}
+	if e, a := "NoSuchThing", actualAPIErr.ErrorCode(); e != a {
+		t.Errorf("expect %v error code, got %v", e, a)
+	}
+	if !errors.Is(err, apiErr) {
+		t.Errorf("expect error to match wrapped API error")
+	}
+
+	expectMsg := "operation error Things: GetThing, RequestID: abc123, " +
+		"api error NoSuchThing: thing does not exist"
+	if e, a := expectMsg, err.Error(); e != a {
+		t.Errorf("expect %q error message, got %q", e, a)
+	}
+}
+
+func TestWrapAPIError_Nil(t *testing.T) {
+	if err := WrapAPIError(nil, "GetThing", "Things", "abc123"); err != nil {
+		t.Errorf("expect nil error, got %v", err)
+	}
+}
+
+func TestOperationError_NoRequestID(t *testing.T) {
+	err := &OperationError{
+		ServiceID:     "Things",
+		OperationName: "GetThing",
+		Err:           errors.New("failed"),
+	}
+
+	if e, a := "operation error Things: GetThing, failed", err.Error(); e != a {
+		t.Errorf("expect %q error message, got %q", e, a)
+	}
+}
+
+func TestAPIErrorFromFields(t *testing.T) {
+	cases := map[string]struct {
+		StatusCode  int
+		ExpectFault ErrorFault
+	}{
+		"client fault": {
+			StatusCode:  400,
+			ExpectFault: FaultClient,
+		},
+		"server fault": {
+			StatusCode:  503,
+			ExpectFault: FaultServer,
+		},
+		"unknown fault": {
+			StatusCode:  302,
+			ExpectFault: FaultUnknown,
+		},
+		"no status code": {
+			ExpectFault: FaultUnknown,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := APIErrorFromFields("InvalidThing", "the thing is invalid", c.StatusCode)
+
+			if e, a := "InvalidThing", err.ErrorCode(); e != a {
+				t.Errorf("expect %v code, got %v", e, a)
+			}
+			if e, a := "the thing is invalid", err.ErrorMessage(); e != a {
+				t.Errorf("expect %v message, got %v", e, a)
+			}
+			if e, a := c.ExpectFault, err.ErrorFault(); e != a {
+				t.Errorf("expect %v fault, got %v", e, a)
+			}
+			if e, a := "api error InvalidThing: the thing is invalid", err.Error(); e != a {
+				t.Errorf("expect %q error, got %q", e, a)
+			}
+
+			status, ok := err.(interface{ HTTPStatusCode() int })
+			if !ok {
+				t.Fatalf("expect error to have status code")
+			}
+			if e, a := c.StatusCode, status.HTTPStatusCode(); e != a {
+				t.Errorf("expect %v status code, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestGenericAPIError_Fields(t *testing.T) {
+	cases := map[string]struct {
+		Fields       map[string]string
+		ExpectFields map[string]string
+		ExpectErr    string
+	}{
+		"no fields": {
+			ExpectErr: "api error ResourceNotFound: not found",
+		},
+		"fields": {
+			Fields: map[string]string{
+				"ResourceArn":  "arn:aws:thing:us-west-2:012345678901:thing/abc",
+				"ResourceType": "Thing",
+			},
+			ExpectFields: map[string]string{
+				"ResourceArn":  "arn:aws:thing:us-west-2:012345678901:thing/abc",
+				"ResourceType": "Thing",
+			},
+			ExpectErr: "api error ResourceNotFound: not found " +
+				"(ResourceArn=arn:aws:thing:us-west-2:012345678901:thing/abc, ResourceType=Thing)",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var err error = &GenericAPIError{
+				Code:    "ResourceNotFound",
+				Message: "not found",
+				Fault:   FaultClient,
+				Fields:  c.Fields,
+			}
+			err = &OperationError{ServiceID: "Things", OperationName: "GetThing", Err: err}
+
+			var fielded interface{ ErrorFields() map[string]string }
+			if !errors.As(err, &fielded) {
+				t.Fatalf("expect error with fields in chain")
+			}
+			if e, a := c.ExpectFields, fielded.ErrorFields(); !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v fields, got %v", e, a)
+			}
+			if e, a := c.ExpectErr, errors.Unwrap(err).Error(); e != a {
+				t.Errorf("expect %q error, got %q", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+)
+
+// Annotation is a key value pair attached to an operation for debugging,
+// (e.g. a decision made by a middleware).
+type Annotation struct {
+	Key   string
+	Value interface{}
+}
+
+type annotationsKey struct{}
+
+// GetAnnotations returns the annotations attached to an operation, in the
+// order they were attached. Returns nil if no annotations were collected.
+func GetAnnotations(metadata Metadata) []Annotation {
+	v, _ := metadata.Get(annotationsKey{}).([]Annotation)
+	return v
+}
+
+// Annotate attaches the key value annotation to the operation the Context
+// belongs to, collected by the Annotations middleware. Annotate can be called
+// from any step of the operation's stack. Annotations attached with the same
+// key are all collected. Does nothing if the operation is not collecting
+// annotations.
+func Annotate(ctx context.Context, key string, value interface{}) {
+	collector, ok := GetStackValue(ctx, annotationCollectorKey{}).(*annotationCollector)
+	if !ok {
+		return
+	}
+	collector.add(Annotation{Key: key, Value: value})
+}
+
+// Annotations provides an initialize middleware that collects the
+// annotations attached to an operation with Annotate. When the operation
+// completes, the annotations are set in the operation's metadata, retrievable
+// with GetAnnotations, and passed to OnComplete, if set.
+type Annotations struct {
+	// OnComplete is invoked with the annotations collected when the
+	// operation completes, if set.
+	OnComplete func(ctx context.Context, annotations []Annotation)
+}
+
+// AddAnnotationsMiddleware adds the Annotations middleware to the front of
+// the stack's Initialize step, so annotations from all middleware are
+// collected. A nil onComplete only sets the annotations in the operation's
+// metadata.
+func AddAnnotationsMiddleware(stack *Stack, onComplete func(context.Context, []Annotation)) error {
+	return stack.Initialize.Add(&Annotations{OnComplete: onComplete}, Before)
+}
+
+// ID returns the identifier for the Annotations middleware.
+func (*Annotations) ID() string { return "Annotations" }
+
+// HandleInitialize seeds the operation's annotation collector, and reports
+// the collected annotations when the operation completes.
+func (m *Annotations) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	collector := &annotationCollector{}
+	ctx = WithStackValue(ctx, annotationCollectorKey{}, collector)
+
+	out, metadata, err = next.HandleInitialize(ctx, in)
+
+	annotations := collector.snapshot()
+	metadata.Set(annotationsKey{}, annotations)
+	if m.OnComplete != nil {
+		m.OnComplete(ctx, annotations)
+	}
+
+	return out, metadata, err
+}
+
+type annotationCollectorKey struct{}
+
+// annotationCollector is the operation scoped collection of annotations. Safe
+// for concurrent use, since annotations may be attached from goroutines the
+// operation starts.
+type annotationCollector struct {
+	mu          sync.Mutex
+	annotations []Annotation
+}
+
+func (c *annotationCollector) add(a Annotation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.annotations = append(c.annotations, a)
+}
+
+func (c *annotationCollector) snapshot() []Annotation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Annotation(nil), c.annotations...)
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestAnnotations(t *testing.T) {
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+
+	var completed []Annotation
+	err := AddAnnotationsMiddleware(stack, func(ctx context.Context, annotations []Annotation) {
+		completed = annotations
+	})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	err = stack.Initialize.Add(InitializeMiddlewareFunc("annotateInitialize",
+		func(ctx context.Context, in InitializeInput, next InitializeHandler) (
+			out InitializeOutput, metadata Metadata, err error,
+		) {
+			Annotate(ctx, "phase", "initialize")
+			return next.HandleInitialize(ctx, in)
+		}), After)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	err = stack.Build.Add(BuildMiddlewareFunc("annotateBuild",
+		func(ctx context.Context, in BuildInput, next BuildHandler) (
+			out BuildOutput, metadata Metadata, err error,
+		) {
+			Annotate(ctx, "phase", "build")
+			return next.HandleBuild(ctx, in)
+		}), After)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	err = stack.Finalize.Add(FinalizeMiddlewareFunc("annotateFinalize",
+		func(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+			out FinalizeOutput, metadata Metadata, err error,
+		) {
+			Annotate(ctx, "endpoint", "https://example.com")
+			return next.HandleFinalize(ctx, in)
+		}), After)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	err = stack.Deserialize.Add(DeserializeMiddlewareFunc("annotateDeserialize",
+		func(ctx context.Context, in DeserializeInput, next DeserializeHandler) (
+			out DeserializeOutput, metadata Metadata, err error,
+		) {
+			out, metadata, err = next.HandleDeserialize(ctx, in)
+			Annotate(ctx, "phase", "deserialize")
+			return out, metadata, err
+		}), After)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+		output interface{}, metadata Metadata, err error,
+	) {
+		Annotate(ctx, "attempt", 1)
+		return nil, metadata, nil
+	}), stack)
+
+	_, metadata, err := handler.Handle(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	expect := []Annotation{
+		{Key: "phase", Value: "initialize"},
+		{Key: "phase", Value: "build"},
+		{Key: "endpoint", Value: "https://example.com"},
+		{Key: "attempt", Value: 1},
+		{Key: "phase", Value: "deserialize"},
+	}
+	if e, a := expect, GetAnnotations(metadata); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v annotations, got %v", e, a)
+	}
+	if e, a := expect, completed; !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v completed annotations, got %v", e, a)
+	}
+}
+
+func TestAnnotate_NotCollected(t *testing.T) {
+	// must not panic without the Annotations middleware.
+	Annotate(context.Background(), "key", "value")
+
+	if v := GetAnnotations(Metadata{}); v != nil {
+		t.Errorf("expect no annotations, got %v", v)
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"time"
+)
+
+// AttemptDeadline provides a finalize middleware that ensures the Context
+// deadline of each of an operation's request attempts does not exceed the
+// deadline of the operation's Context. An attempt whose Context has no
+// deadline, or a later deadline, (e.g. an attempt timeout derived from a
+// Context detached from the operation's cancellation), is clamped to the
+// operation's deadline.
+//
+// Operations whose Context has no deadline are not modified. If the attempt's
+// output has a streaming body, the clamped Context is not canceled until the
+// body is closed, so the body can be read after the attempt returns.
+type AttemptDeadline struct{}
+
+// AddAttemptDeadlineMiddleware adds the middleware to record the operation's
+// deadline in the Initialize step, and clamp each attempt's deadline in the
+// Finalize step after the "Retry" middleware, if present, otherwise at the end
+// of the step.
+func AddAttemptDeadlineMiddleware(stack *Stack) error {
+	if err := stack.Initialize.Add(&attemptDeadlineInitialize{}, Before); err != nil {
+		return err
+	}
+
+	return InsertAfterRetry(stack, &AttemptDeadline{})
+}
+
+// ID returns the identifier for the AttemptDeadline middleware.
+func (*AttemptDeadline) ID() string { return "AttemptDeadline" }
+
+// HandleFinalize clamps the attempt's Context deadline to the operation's
+// deadline.
+func (*AttemptDeadline) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	opDeadline, ok := GetStackValue(ctx, operationDeadlineKey{}).(time.Time)
+	if !ok {
+		return next.HandleFinalize(ctx, in)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && !deadline.After(opDeadline) {
+		return next.HandleFinalize(ctx, in)
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, opDeadline)
+	defer func() {
+		if err != nil || !cancelOnOutputBodyClose(out.Result, cancel) {
+			cancel()
+		}
+	}()
+
+	return next.HandleFinalize(ctx, in)
+}
+
+type operationDeadlineKey struct{}
+
+// attemptDeadlineInitialize records the deadline of the operation's Context.
+type attemptDeadlineInitialize struct{}
+
+func (*attemptDeadlineInitialize) ID() string { return "AttemptDeadlineInitialize" }
+
+func (*attemptDeadlineInitialize) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	if deadline, ok := ctx.Deadline(); ok {
+		ctx = WithStackValue(ctx, operationDeadlineKey{}, deadline)
+	}
+	return next.HandleInitialize(ctx, in)
+}
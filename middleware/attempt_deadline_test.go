@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	smithycontext "github.com/aws/smithy-go/context"
+)
+
+func TestAttemptDeadline(t *testing.T) {
+	cases := map[string]struct {
+		OperationTimeout time.Duration
+		AttemptTimeout   time.Duration
+		ExpectDeadline   bool
+		ExpectClamped    bool
+	}{
+		"attempt timeout exceeds operation budget": {
+			OperationTimeout: time.Minute,
+			AttemptTimeout:   time.Hour,
+			ExpectDeadline:   true,
+			ExpectClamped:    true,
+		},
+		"attempt without deadline": {
+			OperationTimeout: time.Minute,
+			ExpectDeadline:   true,
+			ExpectClamped:    true,
+		},
+		"attempt timeout within operation budget": {
+			OperationTimeout: time.Hour,
+			AttemptTimeout:   time.Minute,
+			ExpectDeadline:   true,
+		},
+		"operation without deadline": {
+			AttemptTimeout: time.Minute,
+			ExpectDeadline: true,
+		},
+		"no deadlines": {},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := NewStack("stack", func() interface{} { return struct{}{} })
+
+			var attemptDeadlines []time.Time
+
+			// mock retry middleware deriving each attempt's Context, detached
+			// from the operation's deadline.
+			err := stack.Finalize.Add(FinalizeMiddlewareFunc("Retry",
+				func(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+					out FinalizeOutput, metadata Metadata, err error,
+				) {
+					for i := 0; i < 2; i++ {
+						attemptCtx := smithycontext.WithSuppressCancel(ctx)
+						if c.AttemptTimeout != 0 {
+							var cancel context.CancelFunc
+							attemptCtx, cancel = context.WithTimeout(attemptCtx, c.AttemptTimeout)
+							defer cancel()
+						}
+						deadline, _ := attemptCtx.Deadline()
+						attemptDeadlines = append(attemptDeadlines, deadline)
+
+						out, metadata, err = next.HandleFinalize(attemptCtx, in)
+					}
+					return out, metadata, err
+				}), After)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if err := AddAttemptDeadlineMiddleware(stack); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			ctx := context.Background()
+			var opDeadline time.Time
+			if c.OperationTimeout != 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, c.OperationTimeout)
+				defer cancel()
+				opDeadline, _ = ctx.Deadline()
+			}
+
+			var handlerDeadlines []time.Time
+			var hasDeadlines []bool
+			handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+				output interface{}, metadata Metadata, err error,
+			) {
+				deadline, ok := ctx.Deadline()
+				handlerDeadlines = append(handlerDeadlines, deadline)
+				hasDeadlines = append(hasDeadlines, ok)
+				return nil, metadata, nil
+			}), stack)
+
+			if _, _, err := handler.Handle(ctx, struct{}{}); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := 2, len(handlerDeadlines); e != a {
+				t.Fatalf("expect %v attempts, got %v", e, a)
+			}
+			for i, deadline := range handlerDeadlines {
+				if e, a := c.ExpectDeadline, hasDeadlines[i]; e != a {
+					t.Fatalf("attempt %d, expect deadline %v, got %v", i, e, a)
+				}
+
+				expect := attemptDeadlines[i]
+				if c.ExpectClamped {
+					expect = opDeadline
+				}
+				if !expect.Equal(deadline) {
+					t.Errorf("attempt %d, expect %v deadline, got %v", i, expect, deadline)
+				}
+				if !opDeadline.IsZero() && deadline.After(opDeadline) {
+					t.Errorf("attempt %d, expect deadline not after %v, got %v", i, opDeadline, deadline)
+				}
+			}
+		})
+	}
+}
+
+func TestAttemptDeadline_Expires(t *testing.T) {
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+	err := stack.Finalize.Add(FinalizeMiddlewareFunc("Retry",
+		func(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+			out FinalizeOutput, metadata Metadata, err error,
+		) {
+			return next.HandleFinalize(smithycontext.WithSuppressCancel(ctx), in)
+		}), After)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if err := AddAttemptDeadlineMiddleware(stack); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+		output interface{}, metadata Metadata, err error,
+	) {
+		select {
+		case <-ctx.Done():
+			return nil, metadata, ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil, metadata, nil
+		}
+	}), stack)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, _, err := handler.Handle(ctx, struct{}{}); err != context.DeadlineExceeded {
+		t.Errorf("expect deadline exceeded error, got %v", err)
+	}
+}
+
+func TestAttemptDeadline_StreamingOutput(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+	ctx = WithStackValue(smithycontext.WithSuppressCancel(ctx), operationDeadlineKey{}, deadline)
+
+	var body *contextBody
+	out, _, err := (&AttemptDeadline{}).HandleFinalize(ctx, FinalizeInput{}, FinalizeHandlerFunc(
+		func(ctx context.Context, in FinalizeInput) (out FinalizeOutput, metadata Metadata, err error) {
+			body = &contextBody{ctx: ctx}
+			out.Result = &mockStreamingOutput{Body: body}
+			return out, metadata, nil
+		}))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	output := out.Result.(*mockStreamingOutput)
+	if _, err := ioutil.ReadAll(output.Body); err != nil {
+		t.Fatalf("expect body to be readable after return, got %v", err)
+	}
+	if err := output.Body.Close(); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := context.Canceled, body.ctx.Err(); e != a {
+		t.Errorf("expect %v once body closed, got %v", e, a)
+	}
+}
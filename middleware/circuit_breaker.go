@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CircuitBreakerState is the state of a CircuitBreaker.
+type CircuitBreakerState int
+
+// CircuitBreakerState enumeration values
+const (
+	// CircuitClosed permits all requests.
+	CircuitClosed CircuitBreakerState = iota
+
+	// CircuitOpen rejects all requests until the cooldown has elapsed.
+	CircuitOpen
+
+	// CircuitHalfOpen permits a single trial request to determine if the
+	// circuit should be closed, or opened again.
+	CircuitHalfOpen
+)
+
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitOpenError is returned by the CircuitBreaker middleware when a request
+// is rejected because the circuit is open.
+type CircuitOpenError struct {
+	// Until is the time the circuit will permit a trial request.
+	Until time.Time
+}
+
+// Error returns the error message.
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker is open until %v, request not sent", e.Until)
+}
+
+// CircuitBreakerOptions provides the configuration of a CircuitBreaker.
+type CircuitBreakerOptions struct {
+	// FailureThreshold is the number of consecutive failures that will trip
+	// the circuit open. Defaults to 5 if less than 1.
+	FailureThreshold int
+
+	// Cooldown is the duration the circuit remains open before permitting a
+	// trial request. Defaults to 30 seconds if not greater than zero.
+	Cooldown time.Duration
+
+	// IsFailure returns if the error of a request attempt should be counted
+	// as a failure. Defaults to counting all non-nil errors.
+	IsFailure func(error) bool
+}
+
+// CircuitBreaker provides a finalize middleware that stops sending requests
+// after a number of consecutive failed attempts. While the circuit is open,
+// requests fail fast with a CircuitOpenError. After the cooldown a single
+// trial request is permitted. If the trial succeeds the circuit is closed,
+// otherwise it is opened again.
+//
+// A single CircuitBreaker should be shared by all operation stacks sending
+// requests to the same endpoint.
+type CircuitBreaker struct {
+	options CircuitBreakerOptions
+	now     func() time.Time
+
+	mu        sync.Mutex
+	state     CircuitBreakerState
+	failures  int
+	openUntil time.Time
+	trialSent bool
+}
+
+// NewCircuitBreaker returns an initialized CircuitBreaker middleware.
+func NewCircuitBreaker(options CircuitBreakerOptions) *CircuitBreaker {
+	if options.FailureThreshold < 1 {
+		options.FailureThreshold = 5
+	}
+	if options.Cooldown <= 0 {
+		options.Cooldown = 30 * time.Second
+	}
+	if options.IsFailure == nil {
+		options.IsFailure = func(err error) bool { return err != nil }
+	}
+
+	return &CircuitBreaker{
+		options: options,
+		now:     time.Now,
+	}
+}
+
+// AddCircuitBreakerMiddleware adds the CircuitBreaker middleware to the end of
+// the stack's Finalize step.
+func AddCircuitBreakerMiddleware(stack *Stack, m *CircuitBreaker) error {
+	return stack.Finalize.Add(m, After)
+}
+
+// ID returns the identifier for the CircuitBreaker middleware.
+func (*CircuitBreaker) ID() string { return "CircuitBreaker" }
+
+// State returns the current state of the circuit.
+func (m *CircuitBreaker) State() CircuitBreakerState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.state == CircuitOpen && !m.now().Before(m.openUntil) {
+		return CircuitHalfOpen
+	}
+	return m.state
+}
+
+// HandleFinalize fails fast if the circuit is open, otherwise sends the
+// request recording its result.
+func (m *CircuitBreaker) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	if err := m.allow(); err != nil {
+		return out, metadata, err
+	}
+
+	out, metadata, err = next.HandleFinalize(ctx, in)
+	m.record(m.options.IsFailure(err))
+
+	return out, metadata, err
+}
+
+func (m *CircuitBreaker) allow() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch m.state {
+	case CircuitOpen:
+		if m.now().Before(m.openUntil) {
+			return &CircuitOpenError{Until: m.openUntil}
+		}
+		m.state = CircuitHalfOpen
+		m.trialSent = false
+		fallthrough
+
+	case CircuitHalfOpen:
+		if m.trialSent {
+			return &CircuitOpenError{Until: m.openUntil}
+		}
+		m.trialSent = true
+	}
+
+	return nil
+}
+
+func (m *CircuitBreaker) record(failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !failed {
+		m.state = CircuitClosed
+		m.failures = 0
+		return
+	}
+
+	m.failures++
+	if m.state == CircuitHalfOpen || m.failures >= m.options.FailureThreshold {
+		m.state = CircuitOpen
+		m.openUntil = m.now().Add(m.options.Cooldown)
+	}
+}
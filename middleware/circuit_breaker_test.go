@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	m := NewCircuitBreaker(CircuitBreakerOptions{
+		FailureThreshold: 3,
+		Cooldown:         10 * time.Second,
+	})
+	m.now = func() time.Time { return now }
+
+	var sendErr error
+	var sent int
+	invoke := func() error {
+		_, _, err := m.HandleFinalize(context.Background(), FinalizeInput{},
+			FinalizeHandlerFunc(func(ctx context.Context, in FinalizeInput) (
+				out FinalizeOutput, metadata Metadata, err error,
+			) {
+				sent++
+				return out, metadata, sendErr
+			}),
+		)
+		return err
+	}
+
+	// Drive failures to trip the circuit.
+	sendErr = fmt.Errorf("service unavailable")
+	for i := 0; i < 3; i++ {
+		if err := invoke(); err != sendErr {
+			t.Fatalf("expect attempt %d send error, got %v", i, err)
+		}
+	}
+	if e, a := CircuitOpen, m.State(); e != a {
+		t.Fatalf("expect %v state, got %v", e, a)
+	}
+
+	// Open circuit fails fast without sending.
+	err := invoke()
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("expect %T error, got %v", openErr, err)
+	}
+	if e, a := 3, sent; e != a {
+		t.Errorf("expect %v requests sent, got %v", e, a)
+	}
+
+	// After the cooldown a failed trial re-opens the circuit.
+	now = now.Add(10 * time.Second)
+	if e, a := CircuitHalfOpen, m.State(); e != a {
+		t.Fatalf("expect %v state, got %v", e, a)
+	}
+	if err := invoke(); err != sendErr {
+		t.Fatalf("expect trial send error, got %v", err)
+	}
+	if e, a := CircuitOpen, m.State(); e != a {
+		t.Fatalf("expect %v state, got %v", e, a)
+	}
+	if err := invoke(); !errors.As(err, &openErr) {
+		t.Fatalf("expect %T error, got %v", openErr, err)
+	}
+
+	// After the cooldown a successful trial closes the circuit.
+	now = now.Add(10 * time.Second)
+	sendErr = nil
+	if err := invoke(); err != nil {
+		t.Fatalf("expect trial to succeed, got %v", err)
+	}
+	if e, a := CircuitClosed, m.State(); e != a {
+		t.Fatalf("expect %v state, got %v", e, a)
+	}
+	if err := invoke(); err != nil {
+		t.Fatalf("expect request to succeed, got %v", err)
+	}
+	if e, a := 6, sent; e != a {
+		t.Errorf("expect %v requests sent, got %v", e, a)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenSingleTrial(t *testing.T) {
+	now := time.Unix(0, 0)
+	m := NewCircuitBreaker(CircuitBreakerOptions{
+		FailureThreshold: 1,
+		Cooldown:         time.Second,
+	})
+	m.now = func() time.Time { return now }
+
+	m.record(true)
+	now = now.Add(time.Second)
+
+	if err := m.allow(); err != nil {
+		t.Fatalf("expect trial request to be permitted, got %v", err)
+	}
+	var openErr *CircuitOpenError
+	if err := m.allow(); !errors.As(err, &openErr) {
+		t.Fatalf("expect concurrent request to fail fast, got %v", err)
+	}
+}
+
+func TestCircuitBreaker_IsFailure(t *testing.T) {
+	expected := fmt.Errorf("not found")
+	m := NewCircuitBreaker(CircuitBreakerOptions{
+		FailureThreshold: 1,
+		IsFailure: func(err error) bool {
+			return err != nil && err != expected
+		},
+	})
+
+	for i := 0; i < 3; i++ {
+		_, _, err := m.HandleFinalize(context.Background(), FinalizeInput{},
+			FinalizeHandlerFunc(func(ctx context.Context, in FinalizeInput) (
+				out FinalizeOutput, metadata Metadata, err error,
+			) {
+				return out, metadata, expected
+			}),
+		)
+		if err != expected {
+			t.Fatalf("expect %v error, got %v", expected, err)
+		}
+	}
+	if e, a := CircuitClosed, m.State(); e != a {
+		t.Errorf("expect %v state, got %v", e, a)
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import "context"
+
+type (
+	serviceIDKey     struct{}
+	operationNameKey struct{}
+)
+
+// WithServiceID adds a service ID to the context, scoped to middleware stack
+// values.
+//
+// This API is called in the client runtime when bootstrapping an operation and
+// should not typically be used directly.
+func WithServiceID(parent context.Context, id string) context.Context {
+	return WithStackValue(parent, serviceIDKey{}, id)
+}
+
+// GetServiceID retrieves the service ID from the context. This is typically
+// the service shape's name from its Smithy model.
+func GetServiceID(ctx context.Context) string {
+	id, _ := GetStackValue(ctx, serviceIDKey{}).(string)
+	return id
+}
+
+// WithOperationName adds the operation name to the context, scoped to
+// middleware stack values.
+//
+// This API is called in the client runtime when bootstrapping an operation and
+// should not typically be used directly.
+func WithOperationName(parent context.Context, name string) context.Context {
+	return WithStackValue(parent, operationNameKey{}, name)
+}
+
+// GetOperationName retrieves the operation name from the context. This is
+// typically the operation shape's name from its Smithy model.
+func GetOperationName(ctx context.Context) string {
+	name, _ := GetStackValue(ctx, operationNameKey{}).(string)
+	return name
+}
+
+type (
+	operationIdempotentKey struct{}
+	idempotencyTokenKey    struct{}
+)
+
+// WithOperationIdempotent adds whether the operation is idempotent to the
+// context, scoped to middleware stack values.
+//
+// This API is called in the client runtime when bootstrapping an operation and
+// should not typically be used directly.
+func WithOperationIdempotent(parent context.Context, idempotent bool) context.Context {
+	return WithStackValue(parent, operationIdempotentKey{}, idempotent)
+}
+
+// GetOperationIdempotent retrieves whether the operation is idempotent from
+// the context, and if the idempotency of the operation is known. This is
+// typically derived from the operation shape's idempotent trait in its Smithy
+// model.
+func GetOperationIdempotent(ctx context.Context) (idempotent, ok bool) {
+	idempotent, ok = GetStackValue(ctx, operationIdempotentKey{}).(bool)
+	return idempotent, ok
+}
+
+// WithIdempotencyToken adds the operation's idempotency token to the context,
+// scoped to middleware stack values. An idempotency token makes retrying a
+// non-idempotent operation safe, since the service deduplicates requests with
+// the same token.
+func WithIdempotencyToken(parent context.Context, token string) context.Context {
+	return WithStackValue(parent, idempotencyTokenKey{}, token)
+}
+
+// GetIdempotencyToken retrieves the operation's idempotency token from the
+// context. Returns an empty string if no token was set.
+func GetIdempotencyToken(ctx context.Context) string {
+	token, _ := GetStackValue(ctx, idempotencyTokenKey{}).(string)
+	return token
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	smithy "github.com/aws/smithy-go"
+)
+
+// AddContextErrorMiddleware adds the ContextError middleware to the front of
+// the stack's Initialize step, so that it observes the errors of all other
+// middleware.
+func AddContextErrorMiddleware(stack *Stack) error {
+	return stack.Initialize.Add(&ContextError{}, Before)
+}
+
+// ContextError provides an initialize middleware that translates errors caused
+// by the operation's Context being canceled, or its deadline being exceeded,
+// into typed errors carrying the operation's name.
+//
+// Errors matching context.DeadlineExceeded are returned as
+// smithy.TimeoutError, and errors matching context.Canceled are returned as
+// smithy.CanceledError. The original error remains in the error chain, so
+// errors.Is will continue to match the context's error.
+type ContextError struct{}
+
+// ID returns the identifier for the ContextError middleware.
+func (*ContextError) ID() string { return "ContextError" }
+
+// HandleInitialize translates context cancellation and deadline errors
+// returned by the next handler.
+func (*ContextError) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	out, metadata, err = next.HandleInitialize(ctx, in)
+	if err == nil {
+		return out, metadata, err
+	}
+
+	return out, metadata, translateContextError(GetOperationName(ctx), err)
+}
+
+func translateContextError(operation string, err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		var timeoutErr *smithy.TimeoutError
+		if errors.As(err, &timeoutErr) && len(timeoutErr.OperationName) != 0 {
+			return err
+		}
+		return &smithy.TimeoutError{OperationName: operation, Err: unwrapUnnamedContextError(err)}
+
+	case errors.Is(err, context.Canceled):
+		var canceledErr *smithy.CanceledError
+		if errors.As(err, &canceledErr) && len(canceledErr.OperationName) != 0 {
+			return err
+		}
+		return &smithy.CanceledError{OperationName: operation, Err: unwrapUnnamedContextError(err)}
+	}
+
+	return err
+}
+
+// unwrapUnnamedContextError removes an outer canceled or timeout error that
+// hasn't been decorated with an operation name, so that it is not duplicated
+// when the error is wrapped.
+func unwrapUnnamedContextError(err error) error {
+	switch v := err.(type) {
+	case *smithy.CanceledError:
+		if len(v.OperationName) == 0 && v.Err != nil {
+			return v.Err
+		}
+	case *smithy.TimeoutError:
+		if len(v.OperationName) == 0 && v.Err != nil {
+			return v.Err
+		}
+	}
+	return err
+}
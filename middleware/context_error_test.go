@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	smithy "github.com/aws/smithy-go"
+)
+
+func TestContextError(t *testing.T) {
+	cases := map[string]struct {
+		Context   func() (context.Context, func())
+		HandleErr func(ctx context.Context) error
+		ExpectErr func(t *testing.T, err error)
+	}{
+		"canceled": {
+			Context: func() (context.Context, func()) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, func() {}
+			},
+			HandleErr: func(ctx context.Context) error {
+				return fmt.Errorf("send failed, %w", ctx.Err())
+			},
+			ExpectErr: func(t *testing.T, err error) {
+				var canceledErr *smithy.CanceledError
+				if !errors.As(err, &canceledErr) {
+					t.Fatalf("expect %T error, got %v", canceledErr, err)
+				}
+				if e, a := "FooOperation", canceledErr.OperationName; e != a {
+					t.Errorf("expect %v operation, got %v", e, a)
+				}
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("expect error to match context.Canceled, %v", err)
+				}
+			},
+		},
+		"already canceled error": {
+			Context: func() (context.Context, func()) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, func() {}
+			},
+			HandleErr: func(ctx context.Context) error {
+				return &smithy.CanceledError{Err: ctx.Err()}
+			},
+			ExpectErr: func(t *testing.T, err error) {
+				canceledErr, ok := err.(*smithy.CanceledError)
+				if !ok {
+					t.Fatalf("expect %T error, got %T", canceledErr, err)
+				}
+				if e, a := "FooOperation", canceledErr.OperationName; e != a {
+					t.Errorf("expect %v operation, got %v", e, a)
+				}
+				if e, a := context.Canceled, canceledErr.Err; e != a {
+					t.Errorf("expect canceled error to not be nested, got %v", a)
+				}
+			},
+		},
+		"deadline exceeded": {
+			Context: func() (context.Context, func()) {
+				return context.WithTimeout(context.Background(), -time.Second)
+			},
+			HandleErr: func(ctx context.Context) error {
+				return &smithy.CanceledError{Err: ctx.Err()}
+			},
+			ExpectErr: func(t *testing.T, err error) {
+				var timeoutErr *smithy.TimeoutError
+				if !errors.As(err, &timeoutErr) {
+					t.Fatalf("expect %T error, got %v", timeoutErr, err)
+				}
+				if e, a := "FooOperation", timeoutErr.OperationName; e != a {
+					t.Errorf("expect %v operation, got %v", e, a)
+				}
+				if !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("expect error to match context.DeadlineExceeded, %v", err)
+				}
+			},
+		},
+		"other error": {
+			Context: func() (context.Context, func()) {
+				return context.Background(), func() {}
+			},
+			HandleErr: func(ctx context.Context) error {
+				return fmt.Errorf("some error")
+			},
+			ExpectErr: func(t *testing.T, err error) {
+				if e, a := "some error", err.Error(); e != a {
+					t.Errorf("expect %v error, got %v", e, a)
+				}
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := c.Context()
+			defer cancel()
+			ctx = WithOperationName(ctx, "FooOperation")
+
+			stack := NewStack("FooOperation", func() interface{} { return struct{}{} })
+			if err := AddContextErrorMiddleware(stack); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+				output interface{}, metadata Metadata, err error,
+			) {
+				return nil, metadata, c.HandleErr(ctx)
+			}), stack)
+
+			_, _, err := handler.Handle(ctx, struct{}{})
+			if err == nil {
+				t.Fatalf("expect error, got none")
+			}
+			c.ExpectErr(t, err)
+		})
+	}
+}
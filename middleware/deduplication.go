@@ -0,0 +1,220 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type deduplicationKey struct{}
+
+// WithDeduplicationKey returns a copy of the Context with the key identifying
+// the operation for the Deduplication middleware. Operations invoked with the
+// same key within the middleware's TTL are deduplicated.
+func WithDeduplicationKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, deduplicationKey{}, key)
+}
+
+// GetDeduplicationKey returns the operation's deduplication key set on the
+// Context with WithDeduplicationKey, or empty string if not set.
+func GetDeduplicationKey(ctx context.Context) string {
+	v, _ := ctx.Value(deduplicationKey{}).(string)
+	return v
+}
+
+// DeduplicationRecord is the record of an operation kept by a
+// DeduplicationStore.
+type DeduplicationRecord struct {
+	// Done is true if the operation completed successfully, false if the
+	// operation is in progress.
+	Done bool
+
+	// Result is the operation's result, if done.
+	Result interface{}
+}
+
+// DeduplicationStore provides the interface for recording the operations
+// performed by their deduplication key. Implementations must be safe for
+// concurrent use.
+//
+// Each reservation is identified by a token returned by Reserve. Complete and
+// Release only modify the key's record if it is the reservation identified by
+// the token, so that an operation that outlives its TTL does not modify the
+// reservation of a later operation with the same key.
+type DeduplicationStore interface {
+	// Reserve records the key as in progress for the TTL, returning the token
+	// identifying the reservation and true if the key was reserved. If the key
+	// is already recorded, and has not expired, returns false and the key's
+	// record.
+	Reserve(key string, ttl time.Duration) (token string, record DeduplicationRecord, ok bool)
+
+	// Complete records the result of the key's successful operation, for the
+	// remainder of the key's TTL, if the key is reserved by the token.
+	Complete(key, token string, result interface{})
+
+	// Release removes the key's record, allowing the key to be reserved again,
+	// if the key is reserved by the token.
+	Release(key, token string)
+}
+
+// DuplicateOperationError is returned by the Deduplication middleware when an
+// operation is not invoked because an operation with the same deduplication
+// key was already performed, or is in progress, within the TTL.
+type DuplicateOperationError struct {
+	Key string
+
+	// InProgress is true if the prior operation had not completed.
+	InProgress bool
+}
+
+// Error returns the error message.
+func (e *DuplicateOperationError) Error() string {
+	if e.InProgress {
+		return fmt.Sprintf("duplicate operation for deduplication key %s, prior operation in progress", e.Key)
+	}
+	return fmt.Sprintf("duplicate operation for deduplication key %s", e.Key)
+}
+
+// RetryableError returns false, the operation must not be retried.
+func (e *DuplicateOperationError) RetryableError() bool { return false }
+
+// Deduplication provides an initialize middleware for at-most-once
+// operations. The operation's deduplication key, from GetDeduplicationKey, is
+// recorded in the store, and operations with the same key within the TTL are
+// short-circuited with a DuplicateOperationError, without being invoked. If
+// ReturnPriorResult is set, a duplicate of an operation that completed
+// successfully returns the prior operation's result instead.
+//
+// The key of an operation that fails is released, allowing the operation to
+// be invoked again. Operations without a deduplication key are not modified.
+type Deduplication struct {
+	// ReturnPriorResult returns the prior operation's result for duplicates
+	// of operations that completed successfully, instead of an error.
+	ReturnPriorResult bool
+
+	store DeduplicationStore
+	ttl   time.Duration
+}
+
+// NewDeduplication returns an initialized Deduplication middleware
+// recording operations in the store for the TTL.
+func NewDeduplication(store DeduplicationStore, ttl time.Duration) *Deduplication {
+	return &Deduplication{
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// AddDeduplicationMiddleware adds the Deduplication middleware to the end of
+// the stack's Initialize step.
+func AddDeduplicationMiddleware(stack *Stack, store DeduplicationStore, ttl time.Duration) error {
+	return stack.Initialize.Add(NewDeduplication(store, ttl), After)
+}
+
+// ID returns the identifier for the Deduplication middleware.
+func (*Deduplication) ID() string { return "Deduplication" }
+
+// HandleInitialize reserves the operation's deduplication key, invoking the
+// operation only if the key was not already recorded.
+func (m *Deduplication) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	key := GetDeduplicationKey(ctx)
+	if len(key) == 0 {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	token, record, ok := m.store.Reserve(key, m.ttl)
+	if !ok {
+		if record.Done && m.ReturnPriorResult {
+			out.Result = record.Result
+			return out, metadata, nil
+		}
+		return out, metadata, &DuplicateOperationError{Key: key, InProgress: !record.Done}
+	}
+
+	out, metadata, err = next.HandleInitialize(ctx, in)
+	if err != nil {
+		m.store.Release(key, token)
+		return out, metadata, err
+	}
+
+	m.store.Complete(key, token, out.Result)
+	return out, metadata, nil
+}
+
+// MemoryDeduplicationStore provides an in-memory DeduplicationStore safe for
+// concurrent use. Expired records are removed when the next key is reserved.
+type MemoryDeduplicationStore struct {
+	mu      sync.Mutex
+	records map[string]memoryDeduplicationRecord
+	tokens  uint64
+
+	now func() time.Time
+}
+
+type memoryDeduplicationRecord struct {
+	DeduplicationRecord
+	token   string
+	expires time.Time
+}
+
+// NewMemoryDeduplicationStore returns an initialized
+// MemoryDeduplicationStore.
+func NewMemoryDeduplicationStore() *MemoryDeduplicationStore {
+	return &MemoryDeduplicationStore{
+		records: map[string]memoryDeduplicationRecord{},
+		now:     time.Now,
+	}
+}
+
+// Reserve records the key as in progress for the TTL, returning the
+// reservation's token and true if the key was reserved, or false and the key's
+// record if it has not expired.
+func (s *MemoryDeduplicationStore) Reserve(key string, ttl time.Duration) (string, DeduplicationRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for k, r := range s.records {
+		if !now.Before(r.expires) {
+			delete(s.records, k)
+		}
+	}
+
+	if r, ok := s.records[key]; ok {
+		return "", r.DeduplicationRecord, false
+	}
+
+	s.tokens++
+	token := strconv.FormatUint(s.tokens, 10)
+	s.records[key] = memoryDeduplicationRecord{token: token, expires: now.Add(ttl)}
+	return token, DeduplicationRecord{}, true
+}
+
+// Complete records the result of the key's successful operation, if the key
+// is reserved by the token.
+func (s *MemoryDeduplicationStore) Complete(key, token string, result interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[key]
+	if !ok || r.token != token {
+		return
+	}
+	r.Done = true
+	r.Result = result
+	s.records[key] = r
+}
+
+// Release removes the key's record, if the key is reserved by the token.
+func (s *MemoryDeduplicationStore) Release(key, token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.records[key]; ok && r.token == token {
+		delete(s.records, key)
+	}
+}
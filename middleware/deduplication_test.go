@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDeduplication(t *testing.T) {
+	cases := map[string]struct {
+		ReturnPriorResult bool
+		Key               string
+		FirstErr          error
+		Elapsed           time.Duration
+		ExpectInvoked     int
+		ExpectDuplicate   bool
+		ExpectResult      interface{}
+	}{
+		"duplicate within window": {
+			Key:             "order-1",
+			Elapsed:         time.Second,
+			ExpectInvoked:   1,
+			ExpectDuplicate: true,
+		},
+		"duplicate returns prior result": {
+			ReturnPriorResult: true,
+			Key:               "order-1",
+			Elapsed:           time.Second,
+			ExpectInvoked:     1,
+			ExpectResult:      "result 1",
+		},
+		"after window": {
+			Key:           "order-1",
+			Elapsed:       time.Minute,
+			ExpectInvoked: 2,
+			ExpectResult:  "result 2",
+		},
+		"prior failed": {
+			Key:           "order-1",
+			FirstErr:      errors.New("operation failed"),
+			Elapsed:       time.Second,
+			ExpectInvoked: 2,
+			ExpectResult:  "result 2",
+		},
+		"no key": {
+			Elapsed:       time.Second,
+			ExpectInvoked: 2,
+			ExpectResult:  "result 2",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			now := time.Unix(0, 0)
+			store := NewMemoryDeduplicationStore()
+			store.now = func() time.Time { return now }
+
+			m := NewDeduplication(store, 30*time.Second)
+			m.ReturnPriorResult = c.ReturnPriorResult
+
+			var invoked int
+			handler := InitializeHandlerFunc(func(ctx context.Context, in InitializeInput) (
+				out InitializeOutput, metadata Metadata, err error,
+			) {
+				invoked++
+				if invoked == 1 && c.FirstErr != nil {
+					return out, metadata, c.FirstErr
+				}
+				out.Result = fmt.Sprintf("result %d", invoked)
+				return out, metadata, nil
+			})
+
+			ctx := context.Background()
+			if len(c.Key) != 0 {
+				ctx = WithDeduplicationKey(ctx, c.Key)
+			}
+
+			if _, _, err := m.HandleInitialize(ctx, InitializeInput{}, handler); err != c.FirstErr {
+				t.Fatalf("expect %v error, got %v", c.FirstErr, err)
+			}
+
+			now = now.Add(c.Elapsed)
+			out, _, err := m.HandleInitialize(ctx, InitializeInput{}, handler)
+			if e, a := c.ExpectInvoked, invoked; e != a {
+				t.Errorf("expect %v invocations, got %v", e, a)
+			}
+
+			var dupErr *DuplicateOperationError
+			if e, a := c.ExpectDuplicate, errors.As(err, &dupErr); e != a {
+				t.Fatalf("expect duplicate %v, got %v", e, err)
+			}
+			if c.ExpectDuplicate {
+				if e, a := c.Key, dupErr.Key; e != a {
+					t.Errorf("expect %v key, got %v", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.ExpectResult, out.Result; e != a {
+				t.Errorf("expect %v result, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestDeduplication_InProgress(t *testing.T) {
+	store := NewMemoryDeduplicationStore()
+	if _, _, ok := store.Reserve("order-1", time.Minute); !ok {
+		t.Fatalf("expect key to be reserved")
+	}
+
+	m := NewDeduplication(store, time.Minute)
+	m.ReturnPriorResult = true
+
+	_, _, err := m.HandleInitialize(WithDeduplicationKey(context.Background(), "order-1"), InitializeInput{},
+		InitializeHandlerFunc(func(ctx context.Context, in InitializeInput) (
+			out InitializeOutput, metadata Metadata, err error,
+		) {
+			t.Errorf("expect duplicate operation not to be invoked")
+			return out, metadata, nil
+		}),
+	)
+
+	var dupErr *DuplicateOperationError
+	if !errors.As(err, &dupErr) {
+		t.Fatalf("expect duplicate error, got %v", err)
+	}
+	if !dupErr.InProgress {
+		t.Errorf("expect prior operation in progress")
+	}
+}
+
+func TestDeduplication_ExpiredReservation(t *testing.T) {
+	clock := time.Unix(0, 0)
+	store := NewMemoryDeduplicationStore()
+	store.now = func() time.Time { return clock }
+
+	m := NewDeduplication(store, time.Minute)
+
+	var laterToken string
+	_, _, err := m.HandleInitialize(WithDeduplicationKey(context.Background(), "order-1"), InitializeInput{},
+		InitializeHandlerFunc(func(ctx context.Context, in InitializeInput) (
+			out InitializeOutput, metadata Metadata, err error,
+		) {
+			// The operation outlives its TTL, and a later operation reserves
+			// the same key.
+			clock = clock.Add(2 * time.Minute)
+
+			var ok bool
+			if laterToken, _, ok = store.Reserve("order-1", time.Minute); !ok {
+				t.Fatalf("expect expired key to be reserved")
+			}
+			return out, metadata, fmt.Errorf("failed")
+		}),
+	)
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+
+	if _, record, ok := store.Reserve("order-1", time.Minute); ok || record.Done {
+		t.Fatalf("expect later reservation to be kept in progress")
+	}
+
+	store.Complete("order-1", "stale", "result")
+	if _, record, _ := store.Reserve("order-1", time.Minute); record.Done {
+		t.Errorf("expect stale token not to complete the reservation")
+	}
+
+	store.Complete("order-1", laterToken, "result")
+	if _, record, _ := store.Reserve("order-1", time.Minute); !record.Done {
+		t.Errorf("expect reservation to be completed")
+	}
+}
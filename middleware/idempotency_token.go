@@ -0,0 +1,236 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/smithy-go/rand"
+)
+
+// IdempotencyTokenPolicy controls when the IdempotencyToken middleware
+// generates the operation's idempotency token.
+type IdempotencyTokenPolicy int
+
+// Enumeration of the idempotency token policies.
+const (
+	// IdempotencyTokenPerOperation generates a single token for the
+	// operation, sent with every attempt of the operation's request. The
+	// service deduplicates retries of the request.
+	IdempotencyTokenPerOperation IdempotencyTokenPolicy = iota
+
+	// IdempotencyTokenPerAttempt generates a new token for each attempt of
+	// the operation's request. Each attempt is applied by the service at most
+	// once, but retries are not deduplicated, so the token does not make
+	// retrying a non-idempotent operation safe.
+	IdempotencyTokenPerAttempt
+)
+
+func (p IdempotencyTokenPolicy) String() string {
+	switch p {
+	case IdempotencyTokenPerOperation:
+		return "PerOperation"
+	case IdempotencyTokenPerAttempt:
+		return "PerAttempt"
+	default:
+		return fmt.Sprintf("IdempotencyTokenPolicy(%d)", int(p))
+	}
+}
+
+// IdempotencyTokenProvider provides the interface for generating idempotency
+// tokens.
+type IdempotencyTokenProvider interface {
+	GetIdempotencyToken() (string, error)
+}
+
+// IdempotencyTokenApplier sets the idempotency token on an operation's
+// serialized request, (e.g. as the request's token member, or header),
+// replacing any token already set.
+type IdempotencyTokenApplier func(request interface{}, token string) error
+
+// IdempotencyTokenOptions provides the options for the IdempotencyToken
+// middleware.
+type IdempotencyTokenOptions struct {
+	// Policy controls whether the token is generated once per operation, or
+	// for each attempt.
+	Policy IdempotencyTokenPolicy
+
+	// Provider generates the tokens. Defaults to random UUID tokens read from
+	// the smithy-go rand package's Reader if nil.
+	Provider IdempotencyTokenProvider
+
+	// Apply sets the attempt's token on the serialized request. Required for
+	// the IdempotencyTokenPerAttempt policy, since the request is serialized
+	// once for all attempts. If nil with the IdempotencyTokenPerOperation
+	// policy, the operation's serializer is expected to read the token from
+	// the Context with GetIdempotencyToken.
+	Apply IdempotencyTokenApplier
+}
+
+// AddIdempotencyTokenMiddleware adds the middleware to generate the
+// operation's idempotency token, retrievable with GetIdempotencyToken. A token
+// already set on the Context with WithIdempotencyToken is used for the
+// operation's first attempt, instead of generating one.
+//
+// The token is generated in the Initialize step. If the Apply option is set,
+// the token is set on each attempt's request in the Finalize step after the
+// "Retry" middleware, if present, otherwise at the end of the step. With the
+// IdempotencyTokenPerAttempt policy, a new token is generated for each retry
+// attempt. Returns an error if the IdempotencyTokenPerAttempt policy is used
+// without the Apply option.
+func AddIdempotencyTokenMiddleware(stack *Stack, optFns ...func(*IdempotencyTokenOptions)) error {
+	var options IdempotencyTokenOptions
+	for _, fn := range optFns {
+		fn(&options)
+	}
+	if options.Provider == nil {
+		options.Provider = rand.NewUUIDIdempotencyToken(rand.Reader)
+	}
+
+	switch options.Policy {
+	case IdempotencyTokenPerOperation:
+	case IdempotencyTokenPerAttempt:
+		if options.Apply == nil {
+			return fmt.Errorf("idempotency token policy %v requires Apply", options.Policy)
+		}
+	default:
+		return fmt.Errorf("unknown idempotency token policy %v", options.Policy)
+	}
+
+	initialize := &idempotencyTokenInitialize{
+		provider:   options.Provider,
+		perAttempt: options.Policy == IdempotencyTokenPerAttempt,
+	}
+	if err := stack.Initialize.Add(initialize, Before); err != nil {
+		return err
+	}
+	if options.Apply == nil {
+		return nil
+	}
+
+	m := &IdempotencyTokenPerAttemptRefresh{
+		Provider: options.Provider,
+		Apply:    options.Apply,
+		Refresh:  options.Policy == IdempotencyTokenPerAttempt,
+	}
+	return InsertAfterRetry(stack, m)
+}
+
+// idempotencyTokenState is the operation scoped state of the idempotency
+// token middleware.
+type idempotencyTokenState struct {
+	mu sync.Mutex
+
+	// generated is set if the operation's first token was generated by the
+	// middleware, instead of provided by the caller.
+	generated bool
+
+	// applied is set once a token has been set on the request.
+	applied bool
+
+	// perAttempt is set if a new token is sent with each retry attempt, so
+	// the service cannot deduplicate the retries.
+	perAttempt bool
+}
+
+func (s *idempotencyTokenState) setApplied() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.applied = true
+}
+
+// isReplaySafe returns if the operation's request is known to be sent with
+// the same idempotency token for every attempt. A token generated by the
+// middleware is only sent if it was applied to the request.
+func (s *idempotencyTokenState) isReplaySafe() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.perAttempt {
+		return false
+	}
+	return s.applied || !s.generated
+}
+
+type idempotencyTokenStateKey struct{}
+
+// hasReplaySafeIdempotencyToken returns if the operation's request is sent
+// with an idempotency token the service can deduplicate retries with.
+func hasReplaySafeIdempotencyToken(ctx context.Context) bool {
+	if len(GetIdempotencyToken(ctx)) == 0 {
+		return false
+	}
+	state, ok := GetStackValue(ctx, idempotencyTokenStateKey{}).(*idempotencyTokenState)
+	return !ok || state.isReplaySafe()
+}
+
+// idempotencyTokenInitialize generates the operation's idempotency token, and
+// seeds the operation's token state.
+type idempotencyTokenInitialize struct {
+	provider   IdempotencyTokenProvider
+	perAttempt bool
+}
+
+func (*idempotencyTokenInitialize) ID() string { return "IdempotencyTokenInitialize" }
+
+func (m *idempotencyTokenInitialize) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	state := &idempotencyTokenState{perAttempt: m.perAttempt}
+	if len(GetIdempotencyToken(ctx)) == 0 {
+		token, err := m.provider.GetIdempotencyToken()
+		if err != nil {
+			return out, metadata, fmt.Errorf("failed to generate idempotency token, %w", err)
+		}
+		ctx = WithIdempotencyToken(ctx, token)
+		state.generated = true
+	}
+
+	ctx = WithStackValue(ctx, idempotencyTokenStateKey{}, state)
+	return next.HandleInitialize(ctx, in)
+}
+
+// IdempotencyTokenPerAttemptRefresh provides a finalize middleware that sets
+// the idempotency token on each of an operation's request attempts. If Refresh
+// is set, a new token is generated for each attempt after the first.
+type IdempotencyTokenPerAttemptRefresh struct {
+	Provider IdempotencyTokenProvider
+
+	// Apply sets the token on the attempt's request.
+	Apply IdempotencyTokenApplier
+
+	// Refresh generates a new token for each retry attempt, instead of
+	// sending the operation's token with every attempt.
+	Refresh bool
+}
+
+// ID returns the identifier for the IdempotencyTokenPerAttemptRefresh
+// middleware.
+func (*IdempotencyTokenPerAttemptRefresh) ID() string { return "IdempotencyTokenPerAttemptRefresh" }
+
+// HandleFinalize sets the attempt's idempotency token on the request,
+// generating a new token if the attempt is a retry and Refresh is set.
+func (m *IdempotencyTokenPerAttemptRefresh) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	state, ok := GetStackValue(ctx, idempotencyTokenStateKey{}).(*idempotencyTokenState)
+	if ok && m.Refresh && getAttemptNumber(ctx) > 1 {
+		token, err := m.Provider.GetIdempotencyToken()
+		if err != nil {
+			return out, metadata, fmt.Errorf("failed to generate idempotency token, %w", err)
+		}
+		ctx = WithIdempotencyToken(ctx, token)
+	}
+
+	token := GetIdempotencyToken(ctx)
+	if len(token) == 0 {
+		return next.HandleFinalize(ctx, in)
+	}
+	if err := m.Apply(in.Request, token); err != nil {
+		return out, metadata, fmt.Errorf("failed to set idempotency token on request, %w", err)
+	}
+	if ok {
+		state.setApplied()
+	}
+
+	return next.HandleFinalize(ctx, in)
+}
@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+type mockIdempotencyTokenProvider struct {
+	count int
+}
+
+func (p *mockIdempotencyTokenProvider) GetIdempotencyToken() (string, error) {
+	p.count++
+	return fmt.Sprintf("token-%d", p.count), nil
+}
+
+type mockTokenRequest struct {
+	Token string
+}
+
+func applyMockToken(request interface{}, token string) error {
+	req, ok := request.(*mockTokenRequest)
+	if !ok {
+		return fmt.Errorf("unknown request type %T", request)
+	}
+	req.Token = token
+	return nil
+}
+
+func TestIdempotencyToken(t *testing.T) {
+	cases := map[string]struct {
+		Policy       IdempotencyTokenPolicy
+		Apply        IdempotencyTokenApplier
+		Token        string
+		ExpectTokens []string
+	}{
+		"per operation": {
+			Policy:       IdempotencyTokenPerOperation,
+			Apply:        applyMockToken,
+			ExpectTokens: []string{"token-1", "token-1"},
+		},
+		"per operation serialized from context": {
+			Policy:       IdempotencyTokenPerOperation,
+			ExpectTokens: []string{"token-1", "token-1"},
+		},
+		"per attempt": {
+			Policy:       IdempotencyTokenPerAttempt,
+			Apply:        applyMockToken,
+			ExpectTokens: []string{"token-1", "token-2"},
+		},
+		"per operation with provided token": {
+			Policy:       IdempotencyTokenPerOperation,
+			Apply:        applyMockToken,
+			Token:        "provided",
+			ExpectTokens: []string{"provided", "provided"},
+		},
+		"per attempt with provided token": {
+			Policy:       IdempotencyTokenPerAttempt,
+			Apply:        applyMockToken,
+			Token:        "provided",
+			ExpectTokens: []string{"provided", "token-1"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := NewStack("stack", func() interface{} { return &mockTokenRequest{} })
+
+			// mock serializer setting the token once, from the context.
+			err := stack.Serialize.Add(SerializeMiddlewareFunc("OperationSerializer",
+				func(ctx context.Context, in SerializeInput, next SerializeHandler) (
+					out SerializeOutput, metadata Metadata, err error,
+				) {
+					in.Request.(*mockTokenRequest).Token = GetIdempotencyToken(ctx)
+					return next.HandleSerialize(ctx, in)
+				}), After)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			// mock retry middleware making two attempts.
+			err = stack.Finalize.Add(FinalizeMiddlewareFunc("Retry",
+				func(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+					out FinalizeOutput, metadata Metadata, err error,
+				) {
+					for i := 0; i < 2; i++ {
+						out, metadata, err = next.HandleFinalize(ctx, in)
+					}
+					return out, metadata, err
+				}), After)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			provider := &mockIdempotencyTokenProvider{}
+			err = AddIdempotencyTokenMiddleware(stack, func(o *IdempotencyTokenOptions) {
+				o.Policy = c.Policy
+				o.Provider = provider
+				o.Apply = c.Apply
+			})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var tokens []string
+			handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+				output interface{}, metadata Metadata, err error,
+			) {
+				tokens = append(tokens, input.(*mockTokenRequest).Token)
+				return nil, metadata, nil
+			}), stack)
+
+			ctx := context.Background()
+			if len(c.Token) != 0 {
+				ctx = WithIdempotencyToken(ctx, c.Token)
+			}
+			if _, _, err := handler.Handle(ctx, struct{}{}); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := fmt.Sprint(c.ExpectTokens), fmt.Sprint(tokens); e != a {
+				t.Errorf("expect %v tokens, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestAddIdempotencyTokenMiddleware_PerAttemptRequiresApply(t *testing.T) {
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+	err := AddIdempotencyTokenMiddleware(stack, func(o *IdempotencyTokenOptions) {
+		o.Policy = IdempotencyTokenPerAttempt
+	})
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+}
+
+func TestAddIdempotencyTokenMiddleware_UnknownPolicy(t *testing.T) {
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+	err := AddIdempotencyTokenMiddleware(stack, func(o *IdempotencyTokenOptions) {
+		o.Policy = IdempotencyTokenPolicy(10)
+	})
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+}
+
+func TestAddIdempotencyTokenMiddleware_DefaultProvider(t *testing.T) {
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+	if err := AddIdempotencyTokenMiddleware(stack); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	var token string
+	handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+		output interface{}, metadata Metadata, err error,
+	) {
+		token = GetIdempotencyToken(ctx)
+		return nil, metadata, nil
+	}), stack)
+	if _, _, err := handler.Handle(context.Background(), struct{}{}); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if e, a := 36, len(token); e != a {
+		t.Errorf("expect %v length UUID token, got %q", e, token)
+	}
+}
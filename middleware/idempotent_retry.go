@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+)
+
+// NonIdempotentRetryError wraps the error of a non-idempotent operation's
+// request attempt, preventing the attempt from being retried. Retrying the
+// request could apply the operation's side effects more than once.
+type NonIdempotentRetryError struct {
+	Err error
+}
+
+func (e *NonIdempotentRetryError) Error() string {
+	return fmt.Sprintf("not retrying non-idempotent operation, %v", e.Err)
+}
+
+// Unwrap returns the attempt's error.
+func (e *NonIdempotentRetryError) Unwrap() error { return e.Err }
+
+// RetryableError returns false, since the operation is not safe to retry.
+func (e *NonIdempotentRetryError) RetryableError() bool { return false }
+
+// IdempotentRetries provides a finalize middleware that disables retries of
+// operations that are not idempotent, unless the operation has an idempotency
+// token making the retry safe. The errors of attempts that must not be
+// retried are wrapped in a NonIdempotentRetryError.
+//
+// The operation's idempotency is retrieved with GetOperationIdempotent, and
+// its token with GetIdempotencyToken. A token generated by the
+// AddIdempotencyTokenMiddleware middleware only makes the retry safe if the
+// token was set on the request with the middleware's Apply option, and the
+// IdempotencyTokenPerAttempt policy, which sends a new token with each retry,
+// never makes the retry safe. Operations whose idempotency is unknown are not
+// modified.
+type IdempotentRetries struct{}
+
+// AddIdempotentRetriesMiddleware adds the IdempotentRetries middleware to the
+// Finalize step after the "Retry" middleware, if present, otherwise to the end
+// of the step.
+func AddIdempotentRetriesMiddleware(stack *Stack) error {
+	return InsertAfterRetry(stack, &IdempotentRetries{})
+}
+
+// ID returns the identifier for the IdempotentRetries middleware.
+func (*IdempotentRetries) ID() string { return "IdempotentRetries" }
+
+// HandleFinalize wraps the attempt's error if the operation must not be
+// retried.
+func (*IdempotentRetries) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	out, metadata, err = next.HandleFinalize(ctx, in)
+	if err == nil {
+		return out, metadata, nil
+	}
+
+	if idempotent, ok := GetOperationIdempotent(ctx); !ok || idempotent {
+		return out, metadata, err
+	}
+	if hasReplaySafeIdempotencyToken(ctx) {
+		return out, metadata, err
+	}
+
+	return out, metadata, &NonIdempotentRetryError{Err: err}
+}
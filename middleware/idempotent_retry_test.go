@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestIdempotentRetries(t *testing.T) {
+	cases := map[string]struct {
+		WithContext    func(context.Context) context.Context
+		TokenOptions   func(*IdempotencyTokenOptions)
+		ExpectAttempts int
+		ExpectWrapped  bool
+	}{
+		"non-idempotent": {
+			WithContext: func(ctx context.Context) context.Context {
+				return WithOperationIdempotent(ctx, false)
+			},
+			ExpectAttempts: 1,
+			ExpectWrapped:  true,
+		},
+		"non-idempotent with token": {
+			WithContext: func(ctx context.Context) context.Context {
+				ctx = WithOperationIdempotent(ctx, false)
+				return WithIdempotencyToken(ctx, "token")
+			},
+			ExpectAttempts: 3,
+		},
+		"non-idempotent with generated token not applied": {
+			WithContext: func(ctx context.Context) context.Context {
+				return WithOperationIdempotent(ctx, false)
+			},
+			TokenOptions:   func(o *IdempotencyTokenOptions) {},
+			ExpectAttempts: 1,
+			ExpectWrapped:  true,
+		},
+		"non-idempotent with generated token applied": {
+			WithContext: func(ctx context.Context) context.Context {
+				return WithOperationIdempotent(ctx, false)
+			},
+			TokenOptions: func(o *IdempotencyTokenOptions) {
+				o.Apply = func(interface{}, string) error { return nil }
+			},
+			ExpectAttempts: 3,
+		},
+		"non-idempotent with per attempt token": {
+			WithContext: func(ctx context.Context) context.Context {
+				return WithOperationIdempotent(ctx, false)
+			},
+			TokenOptions: func(o *IdempotencyTokenOptions) {
+				o.Policy = IdempotencyTokenPerAttempt
+				o.Apply = func(interface{}, string) error { return nil }
+			},
+			ExpectAttempts: 1,
+			ExpectWrapped:  true,
+		},
+		"non-idempotent with provided per attempt token": {
+			WithContext: func(ctx context.Context) context.Context {
+				ctx = WithOperationIdempotent(ctx, false)
+				return WithIdempotencyToken(ctx, "token")
+			},
+			TokenOptions: func(o *IdempotencyTokenOptions) {
+				o.Policy = IdempotencyTokenPerAttempt
+				o.Apply = func(interface{}, string) error { return nil }
+			},
+			ExpectAttempts: 1,
+			ExpectWrapped:  true,
+		},
+		"idempotent": {
+			WithContext: func(ctx context.Context) context.Context {
+				return WithOperationIdempotent(ctx, true)
+			},
+			ExpectAttempts: 3,
+		},
+		"unknown idempotency": {
+			WithContext:    func(ctx context.Context) context.Context { return ctx },
+			ExpectAttempts: 3,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := NewStack("stack", func() interface{} { return struct{}{} })
+
+			// mock retry middleware retrying up to 3 attempts, unless the
+			// error is not retryable.
+			err := stack.Finalize.Add(FinalizeMiddlewareFunc("Retry",
+				func(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+					out FinalizeOutput, metadata Metadata, err error,
+				) {
+					for i := 0; i < 3; i++ {
+						out, metadata, err = next.HandleFinalize(ctx, in)
+						var retryable interface{ RetryableError() bool }
+						if err == nil || (errors.As(err, &retryable) && !retryable.RetryableError()) {
+							break
+						}
+					}
+					return out, metadata, err
+				}), After)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if err := AddIdempotentRetriesMiddleware(stack); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if c.TokenOptions != nil {
+				if err := AddIdempotencyTokenMiddleware(stack, c.TokenOptions); err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+			}
+
+			attemptErr := errors.New("attempt failed")
+			var attempts int
+			handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+				output interface{}, metadata Metadata, err error,
+			) {
+				attempts++
+				return nil, metadata, attemptErr
+			}), stack)
+
+			_, _, err = handler.Handle(c.WithContext(context.Background()), struct{}{})
+			if !errors.Is(err, attemptErr) {
+				t.Fatalf("expect attempt error, got %v", err)
+			}
+			if e, a := c.ExpectAttempts, attempts; e != a {
+				t.Errorf("expect %v attempts, got %v", e, a)
+			}
+
+			var wrapped *NonIdempotentRetryError
+			if e, a := c.ExpectWrapped, errors.As(err, &wrapped); e != a {
+				t.Errorf("expect wrapped %v, got %v, %v", e, a, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// JSONSchemaValidator provides the interface for validating a JSON document
+// against a compiled JSON Schema.
+type JSONSchemaValidator interface {
+	// ValidateJSON returns an error if the document does not conform to the
+	// schema.
+	ValidateJSON(document []byte) error
+}
+
+// JSONSchemaCompiler compiles a JSON Schema document into a
+// JSONSchemaValidator.
+type JSONSchemaCompiler func(schema []byte) (JSONSchemaValidator, error)
+
+// JSONSchemaValidateOptions provides the options for the JSONSchemaValidate
+// middleware.
+type JSONSchemaValidateOptions struct {
+	// Compiler compiles the schema into the validator used. Defaults to
+	// CompileJSONSchema if nil, which rejects schemas using keywords it does
+	// not support. Allows a complete JSON Schema implementation to be used,
+	// without the middleware package depending on it.
+	Compiler JSONSchemaCompiler
+}
+
+// JSONSchemaViolationError is returned when a JSON document does not conform to
+// a JSON Schema.
+type JSONSchemaViolationError struct {
+	// Violations are the descriptions of each way the document does not
+	// conform to the schema.
+	Violations []string
+}
+
+// Error returns the error message.
+func (e *JSONSchemaViolationError) Error() string {
+	return fmt.Sprintf("JSON schema validation failed, %s", strings.Join(e.Violations, "; "))
+}
+
+// JSONSchemaValidate provides a serialize middleware that validates the
+// serialized JSON request payload against a JSON Schema, failing the operation
+// before the request is sent if the payload does not conform.
+//
+// The middleware requires the request to provide its payload stream, (e.g.
+// smithy-go's transport/http Request). Requests without a payload, or whose
+// payload stream is not seekable, are not validated, since the payload cannot
+// be read without consuming it.
+type JSONSchemaValidate struct {
+	validator JSONSchemaValidator
+}
+
+// NewJSONSchemaValidate returns an initialized JSONSchemaValidate middleware
+// for the schema. Returns an error if the schema cannot be compiled.
+func NewJSONSchemaValidate(schema []byte, optFns ...func(*JSONSchemaValidateOptions)) (*JSONSchemaValidate, error) {
+	var options JSONSchemaValidateOptions
+	for _, fn := range optFns {
+		fn(&options)
+	}
+
+	compile := options.Compiler
+	if compile == nil {
+		compile = CompileJSONSchema
+	}
+
+	validator, err := compile(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile JSON schema, %w", err)
+	}
+
+	return &JSONSchemaValidate{validator: validator}, nil
+}
+
+// AddJSONSchemaValidateMiddleware adds the JSONSchemaValidate middleware to
+// the stack's Serialize step, after the "OperationSerializer" middleware if
+// present, otherwise to the end of the step.
+func AddJSONSchemaValidateMiddleware(stack *Stack, m *JSONSchemaValidate) error {
+	if _, ok := stack.Serialize.Get("OperationSerializer"); ok {
+		return stack.Serialize.Insert(m, "OperationSerializer", After)
+	}
+	return stack.Serialize.Add(m, After)
+}
+
+// ID returns the identifier for the JSONSchemaValidate middleware.
+func (*JSONSchemaValidate) ID() string { return "JSONSchemaValidate" }
+
+// payloadRequest is the interface of requests that provide their serialized
+// payload.
+type payloadRequest interface {
+	GetStream() io.Reader
+	IsStreamSeekable() bool
+	RewindStream() error
+}
+
+// HandleSerialize validates the request's serialized payload.
+func (m *JSONSchemaValidate) HandleSerialize(ctx context.Context, in SerializeInput, next SerializeHandler) (
+	out SerializeOutput, metadata Metadata, err error,
+) {
+	req, ok := in.Request.(payloadRequest)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	stream := req.GetStream()
+	if stream == nil || !req.IsStreamSeekable() {
+		return next.HandleSerialize(ctx, in)
+	}
+
+	payload, err := ioutil.ReadAll(stream)
+	if err != nil {
+		return out, metadata, fmt.Errorf("failed to read request payload, %w", err)
+	}
+	if err := req.RewindStream(); err != nil {
+		return out, metadata, fmt.Errorf("failed to rewind request payload, %w", err)
+	}
+
+	if len(payload) != 0 {
+		if err := m.validator.ValidateJSON(payload); err != nil {
+			return out, metadata, err
+		}
+	}
+
+	return next.HandleSerialize(ctx, in)
+}
@@ -0,0 +1,188 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+type mockPayloadRequest struct {
+	stream   *bytes.Reader
+	seekable bool
+}
+
+func (r *mockPayloadRequest) GetStream() io.Reader {
+	if r.stream == nil {
+		return nil
+	}
+	return r.stream
+}
+func (r *mockPayloadRequest) IsStreamSeekable() bool { return r.seekable }
+func (r *mockPayloadRequest) RewindStream() error {
+	_, err := r.stream.Seek(0, io.SeekStart)
+	return err
+}
+
+const testJSONSchema = `{
+	"type": "object",
+	"required": ["name", "count"],
+	"properties": {
+		"name": {"type": "string", "minLength": 1, "pattern": "^[a-z]+$"},
+		"count": {"type": "integer", "minimum": 0, "maximum": 10},
+		"kind": {"enum": ["a", "b"]},
+		"tags": {"type": "array", "maxItems": 2, "items": {"type": "string"}}
+	},
+	"additionalProperties": false
+}`
+
+func TestJSONSchemaValidate(t *testing.T) {
+	cases := map[string]struct {
+		Payload          string
+		ExpectViolations []string
+	}{
+		"valid": {
+			Payload: `{"name": "foo", "count": 3, "kind": "a", "tags": ["x", "y"]}`,
+		},
+		"invalid": {
+			Payload: `{"name": "Foo", "count": 11.5, "kind": "c", "tags": ["x", 1, "z"], "extra": true}`,
+			ExpectViolations: []string{
+				"$.count: expected type [integer], got number",
+				"$.extra: value not allowed",
+				`$.kind: value is not one of the enumerated values`,
+				`$.name: value does not match pattern "^[a-z]+$"`,
+				"$.tags: expected at most 2 items, got 3",
+				"$.tags[1]: expected type [string], got integer",
+			},
+		},
+		"missing required": {
+			Payload: `{"count": 1}`,
+			ExpectViolations: []string{
+				`$: missing required property "name"`,
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			m, err := NewJSONSchemaValidate([]byte(testJSONSchema))
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var called bool
+			req := &mockPayloadRequest{stream: bytes.NewReader([]byte(c.Payload)), seekable: true}
+			_, _, err = m.HandleSerialize(context.Background(), SerializeInput{Request: req},
+				SerializeHandlerFunc(func(ctx context.Context, in SerializeInput) (
+					out SerializeOutput, metadata Metadata, err error,
+				) {
+					called = true
+					b, _ := ioutil.ReadAll(in.Request.(*mockPayloadRequest).GetStream())
+					if e, a := c.Payload, string(b); e != a {
+						t.Errorf("expect payload %v, got %v", e, a)
+					}
+					return out, metadata, nil
+				}),
+			)
+
+			if len(c.ExpectViolations) == 0 {
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				if !called {
+					t.Errorf("expect next handler to be called")
+				}
+				return
+			}
+
+			var verr *JSONSchemaViolationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expect %T error, got %v", verr, err)
+			}
+			if called {
+				t.Errorf("expect next handler not to be called")
+			}
+			if e, a := len(c.ExpectViolations), len(verr.Violations); e != a {
+				t.Fatalf("expect %v violations, got %v: %v", e, a, verr.Violations)
+			}
+			for i, e := range c.ExpectViolations {
+				if a := verr.Violations[i]; e != a {
+					t.Errorf("expect violation %d %q, got %q", i, e, a)
+				}
+			}
+		})
+	}
+}
+
+type mockJSONSchemaValidator func([]byte) error
+
+func (fn mockJSONSchemaValidator) ValidateJSON(document []byte) error { return fn(document) }
+
+func TestJSONSchemaValidate_Compiler(t *testing.T) {
+	expectErr := errors.New("invalid document")
+
+	m, err := NewJSONSchemaValidate([]byte("schema"), func(o *JSONSchemaValidateOptions) {
+		o.Compiler = func(schema []byte) (JSONSchemaValidator, error) {
+			if e, a := "schema", string(schema); e != a {
+				t.Errorf("expect schema %v, got %v", e, a)
+			}
+			return mockJSONSchemaValidator(func([]byte) error { return expectErr }), nil
+		}
+	})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	req := &mockPayloadRequest{stream: bytes.NewReader([]byte(`{}`)), seekable: true}
+	_, _, err = m.HandleSerialize(context.Background(), SerializeInput{Request: req},
+		SerializeHandlerFunc(func(ctx context.Context, in SerializeInput) (
+			out SerializeOutput, metadata Metadata, err error,
+		) {
+			t.Errorf("expect next handler not to be called")
+			return out, metadata, nil
+		}),
+	)
+	if e, a := expectErr, err; e != a {
+		t.Errorf("expect error %v, got %v", e, a)
+	}
+}
+
+func TestNewJSONSchemaValidate_InvalidSchema(t *testing.T) {
+	cases := map[string]string{
+		"not json":                   `{`,
+		"not an object":              `"string"`,
+		"bad pattern":                `{"pattern": "("}`,
+		"bad minLength":              `{"minLength": -1}`,
+		"unsupported keyword":        `{"type": "object", "oneOf": [{"required": ["a"]}, {"required": ["b"]}]}`,
+		"unsupported nested keyword": `{"properties": {"a": {"$ref": "#/definitions/a"}}, "definitions": {"a": {}}}`,
+		"unsupported format":         `{"type": "string", "format": "date-time"}`,
+	}
+
+	for name, schema := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewJSONSchemaValidate([]byte(schema)); err == nil {
+				t.Fatalf("expect error, got none")
+			}
+		})
+	}
+}
+
+func TestCompileJSONSchema_Annotations(t *testing.T) {
+	schema := `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"title": "Thing",
+		"description": "A thing.",
+		"type": "object",
+		"properties": {"name": {"type": "string", "default": "a", "examples": ["a"]}}
+	}`
+
+	validator, err := CompileJSONSchema([]byte(schema))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if err := validator.ValidateJSON([]byte(`{"name": 1}`)); err == nil {
+		t.Errorf("expect error, got none")
+	}
+}
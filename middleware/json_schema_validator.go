@@ -0,0 +1,388 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+)
+
+// CompileJSONSchema compiles a JSON Schema into a JSONSchemaValidator
+// supporting a subset of the JSON Schema validation keywords. For complete
+// JSON Schema support use a JSONSchemaCompiler backed by a full
+// implementation.
+//
+// Supported keywords are type, enum, properties, required,
+// additionalProperties, items, minimum, maximum, minLength, maxLength,
+// pattern, minItems, and maxItems. Annotation keywords, (e.g. title), are
+// ignored. Returns an error if the schema uses any other keyword, (e.g. oneOf
+// or $ref), since the documents it accepts would not be validated.
+func CompileJSONSchema(schema []byte) (JSONSchemaValidator, error) {
+	var v interface{}
+	if err := decodeJSONNumbers(schema, &v); err != nil {
+		return nil, err
+	}
+
+	return compileJSONSchema(v)
+}
+
+func decodeJSONNumbers(document []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return fmt.Errorf("unexpected data after JSON value")
+	}
+	return nil
+}
+
+type jsonSchema struct {
+	// if set the schema is the boolean schema, true matching all values.
+	boolean *bool
+
+	types      []string
+	enum       []interface{}
+	properties map[string]*jsonSchema
+	required   []string
+
+	additionalProperties *jsonSchema
+	items                *jsonSchema
+
+	minimum, maximum     *big.Float
+	minLength, maxLength *int
+	minItems, maxItems   *int
+	pattern              *regexp.Regexp
+}
+
+// jsonSchemaKeywords are the keywords CompileJSONSchema compiles, or ignores
+// since they do not affect validation.
+var jsonSchemaKeywords = map[string]bool{
+	"type": true, "enum": true, "properties": true, "required": true,
+	"additionalProperties": true, "items": true,
+	"minimum": true, "maximum": true, "minLength": true, "maxLength": true,
+	"pattern": true, "minItems": true, "maxItems": true,
+
+	// annotations
+	"$schema": true, "$id": true, "$comment": true, "title": true,
+	"description": true, "default": true, "examples": true,
+	"deprecated": true, "readOnly": true, "writeOnly": true,
+}
+
+// checkJSONSchemaKeywords returns an error if the schema object uses a
+// keyword that is not supported.
+func checkJSONSchemaKeywords(obj map[string]interface{}) error {
+	var unsupported []string
+	for keyword := range obj {
+		if !jsonSchemaKeywords[keyword] {
+			unsupported = append(unsupported, keyword)
+		}
+	}
+	if len(unsupported) == 0 {
+		return nil
+	}
+
+	sort.Strings(unsupported)
+	return fmt.Errorf("unsupported JSON schema keywords %v, use a complete JSON Schema compiler", unsupported)
+}
+
+func compileJSONSchema(v interface{}) (*jsonSchema, error) {
+	if b, ok := v.(bool); ok {
+		return &jsonSchema{boolean: &b}, nil
+	}
+
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("schema must be an object or boolean, got %T", v)
+	}
+
+	if err := checkJSONSchemaKeywords(obj); err != nil {
+		return nil, err
+	}
+
+	s := &jsonSchema{}
+	var err error
+
+	switch t := obj["type"].(type) {
+	case nil:
+	case string:
+		s.types = []string{t}
+	case []interface{}:
+		for _, e := range t {
+			name, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("type must be a string, got %T", e)
+			}
+			s.types = append(s.types, name)
+		}
+	default:
+		return nil, fmt.Errorf("type must be a string or array, got %T", t)
+	}
+
+	if enum, ok := obj["enum"]; ok {
+		if s.enum, ok = enum.([]interface{}); !ok {
+			return nil, fmt.Errorf("enum must be an array, got %T", enum)
+		}
+	}
+
+	if props, ok := obj["properties"]; ok {
+		m, ok := props.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("properties must be an object, got %T", props)
+		}
+		s.properties = make(map[string]*jsonSchema, len(m))
+		for name, prop := range m {
+			if s.properties[name], err = compileJSONSchema(prop); err != nil {
+				return nil, fmt.Errorf("property %s, %w", name, err)
+			}
+		}
+	}
+
+	if required, ok := obj["required"]; ok {
+		list, ok := required.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("required must be an array, got %T", required)
+		}
+		for _, e := range list {
+			name, ok := e.(string)
+			if !ok {
+				return nil, fmt.Errorf("required must contain strings, got %T", e)
+			}
+			s.required = append(s.required, name)
+		}
+	}
+
+	if additional, ok := obj["additionalProperties"]; ok {
+		if s.additionalProperties, err = compileJSONSchema(additional); err != nil {
+			return nil, fmt.Errorf("additionalProperties, %w", err)
+		}
+	}
+	if items, ok := obj["items"]; ok {
+		if s.items, err = compileJSONSchema(items); err != nil {
+			return nil, fmt.Errorf("items, %w", err)
+		}
+	}
+
+	if s.minimum, err = schemaNumber(obj, "minimum"); err != nil {
+		return nil, err
+	}
+	if s.maximum, err = schemaNumber(obj, "maximum"); err != nil {
+		return nil, err
+	}
+	for keyword, dst := range map[string]**int{
+		"minLength": &s.minLength, "maxLength": &s.maxLength,
+		"minItems": &s.minItems, "maxItems": &s.maxItems,
+	} {
+		if *dst, err = schemaCount(obj, keyword); err != nil {
+			return nil, err
+		}
+	}
+
+	if pattern, ok := obj["pattern"]; ok {
+		p, ok := pattern.(string)
+		if !ok {
+			return nil, fmt.Errorf("pattern must be a string, got %T", pattern)
+		}
+		if s.pattern, err = regexp.Compile(p); err != nil {
+			return nil, fmt.Errorf("invalid pattern, %w", err)
+		}
+	}
+
+	return s, nil
+}
+
+func schemaNumber(obj map[string]interface{}, keyword string) (*big.Float, error) {
+	v, ok := obj[keyword]
+	if !ok {
+		return nil, nil
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a number, got %T", keyword, v)
+	}
+	f, ok := new(big.Float).SetString(n.String())
+	if !ok {
+		return nil, fmt.Errorf("%s must be a number, got %v", keyword, n)
+	}
+	return f, nil
+}
+
+func schemaCount(obj map[string]interface{}, keyword string) (*int, error) {
+	v, ok := obj[keyword]
+	if !ok {
+		return nil, nil
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an integer, got %T", keyword, v)
+	}
+	i, err := n.Int64()
+	if err != nil || i < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative integer, got %v", keyword, n)
+	}
+	c := int(i)
+	return &c, nil
+}
+
+// ValidateJSON returns a JSONSchemaViolationError if the document does not
+// conform to the schema.
+func (s *jsonSchema) ValidateJSON(document []byte) error {
+	var v interface{}
+	if err := decodeJSONNumbers(document, &v); err != nil {
+		return fmt.Errorf("invalid JSON document, %w", err)
+	}
+
+	var violations []string
+	s.validate("$", v, &violations)
+	if len(violations) != 0 {
+		return &JSONSchemaViolationError{Violations: violations}
+	}
+	return nil
+}
+
+func (s *jsonSchema) validate(path string, v interface{}, violations *[]string) {
+	addf := func(format string, args ...interface{}) {
+		*violations = append(*violations, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if s.boolean != nil {
+		if !*s.boolean {
+			addf("value not allowed")
+		}
+		return
+	}
+
+	if len(s.types) != 0 && !matchesJSONType(v, s.types) {
+		addf("expected type %v, got %s", s.types, jsonTypeOf(v))
+		return
+	}
+
+	if s.enum != nil {
+		var found bool
+		for _, e := range s.enum {
+			if jsonEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			addf("value is not one of the enumerated values")
+		}
+	}
+
+	switch tv := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.required {
+			if _, ok := tv[name]; !ok {
+				addf("missing required property %q", name)
+			}
+		}
+
+		names := make([]string, 0, len(tv))
+		for name := range tv {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if prop, ok := s.properties[name]; ok {
+				prop.validate(path+"."+name, tv[name], violations)
+			} else if s.additionalProperties != nil {
+				s.additionalProperties.validate(path+"."+name, tv[name], violations)
+			}
+		}
+
+	case []interface{}:
+		if s.minItems != nil && len(tv) < *s.minItems {
+			addf("expected at least %d items, got %d", *s.minItems, len(tv))
+		}
+		if s.maxItems != nil && len(tv) > *s.maxItems {
+			addf("expected at most %d items, got %d", *s.maxItems, len(tv))
+		}
+		if s.items != nil {
+			for i, item := range tv {
+				s.items.validate(fmt.Sprintf("%s[%d]", path, i), item, violations)
+			}
+		}
+
+	case string:
+		n := utf8.RuneCountInString(tv)
+		if s.minLength != nil && n < *s.minLength {
+			addf("expected length of at least %d, got %d", *s.minLength, n)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			addf("expected length of at most %d, got %d", *s.maxLength, n)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(tv) {
+			addf("value does not match pattern %q", s.pattern.String())
+		}
+
+	case json.Number:
+		f, ok := new(big.Float).SetString(tv.String())
+		if !ok {
+			addf("invalid number %v", tv)
+			return
+		}
+		if s.minimum != nil && f.Cmp(s.minimum) < 0 {
+			addf("expected minimum of %v, got %v", s.minimum, tv)
+		}
+		if s.maximum != nil && f.Cmp(s.maximum) > 0 {
+			addf("expected maximum of %v, got %v", s.maximum, tv)
+		}
+	}
+}
+
+func jsonTypeOf(v interface{}) string {
+	switch tv := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case json.Number:
+		if f, ok := new(big.Float).SetString(tv.String()); ok && f.IsInt() {
+			return "integer"
+		}
+		return "number"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func matchesJSONType(v interface{}, types []string) bool {
+	actual := jsonTypeOf(v)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonEqual(a, b interface{}) bool {
+	an, aok := a.(json.Number)
+	bn, bok := b.(json.Number)
+	if aok && bok {
+		af, aok := new(big.Float).SetString(an.String())
+		bf, bok := new(big.Float).SetString(bn.String())
+		return aok && bok && af.Cmp(bf) == 0
+	}
+
+	ab, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ab, bb)
+}
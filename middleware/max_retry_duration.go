@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MaxRetryDurationExceededError wraps the error of an operation's request
+// attempt that failed after the operation's maximum retry duration elapsed,
+// preventing the attempt from being retried.
+type MaxRetryDurationExceededError struct {
+	Duration time.Duration
+	Err      error
+}
+
+func (e *MaxRetryDurationExceededError) Error() string {
+	return fmt.Sprintf("maximum retry duration of %v exceeded, %v", e.Duration, e.Err)
+}
+
+// Unwrap returns the attempt's error.
+func (e *MaxRetryDurationExceededError) Unwrap() error { return e.Err }
+
+// RetryableError returns false, since the retry duration is exhausted.
+func (e *MaxRetryDurationExceededError) RetryableError() bool { return false }
+
+// MaxRetryDuration provides a finalize middleware that caps the total time
+// spent attempting an operation, independent of the number of attempts. The
+// error of an attempt that fails once the duration has elapsed since the
+// operation's first attempt started is wrapped in a
+// MaxRetryDurationExceededError, stopping the operation from being retried.
+// The last attempt's error is returned.
+//
+// MaxRetryDuration does not cancel attempts in progress. Use
+// AddMaxRetryDurationMiddleware to add the middleware, tracking the elapsed
+// time of each operation.
+type MaxRetryDuration struct {
+	// Duration is the maximum time after the operation's first attempt starts
+	// that a failed attempt may be retried.
+	Duration time.Duration
+
+	now func() time.Time
+}
+
+// NewMaxRetryDuration returns an initialized MaxRetryDuration middleware.
+func NewMaxRetryDuration(d time.Duration) *MaxRetryDuration {
+	return &MaxRetryDuration{
+		Duration: d,
+		now:      time.Now,
+	}
+}
+
+// AddMaxRetryDurationMiddleware adds the middleware to track the operation's
+// elapsed retry time in the Initialize step, and the MaxRetryDuration
+// middleware to the Finalize step after the "Retry" middleware, if present,
+// otherwise to the end of the step. Returns an error if the duration is not
+// greater than zero.
+func AddMaxRetryDurationMiddleware(stack *Stack, d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("maximum retry duration must be greater than zero, got %v", d)
+	}
+
+	if err := stack.Initialize.Add(&maxRetryDurationInitialize{}, Before); err != nil {
+		return err
+	}
+
+	m := NewMaxRetryDuration(d)
+	return InsertAfterRetry(stack, m)
+}
+
+// ID returns the identifier for the MaxRetryDuration middleware.
+func (*MaxRetryDuration) ID() string { return "MaxRetryDuration" }
+
+// HandleFinalize wraps the attempt's error if the operation's maximum retry
+// duration has elapsed.
+func (m *MaxRetryDuration) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	tracker, ok := GetStackValue(ctx, retryDurationTrackerKey{}).(*retryDurationTracker)
+	if !ok {
+		return next.HandleFinalize(ctx, in)
+	}
+	start := tracker.start(m.now())
+
+	out, metadata, err = next.HandleFinalize(ctx, in)
+	if err == nil || m.now().Sub(start) < m.Duration {
+		return out, metadata, err
+	}
+
+	return out, metadata, &MaxRetryDurationExceededError{Duration: m.Duration, Err: err}
+}
+
+type retryDurationTrackerKey struct{}
+
+// retryDurationTracker records when the operation's first attempt started.
+type retryDurationTracker struct {
+	mu      sync.Mutex
+	started bool
+	startAt time.Time
+}
+
+// start returns when the first attempt started, recording now if no attempt
+// has started.
+func (t *retryDurationTracker) start(now time.Time) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.started {
+		t.started = true
+		t.startAt = now
+	}
+	return t.startAt
+}
+
+// maxRetryDurationInitialize seeds the operation's retry duration tracker.
+type maxRetryDurationInitialize struct{}
+
+func (*maxRetryDurationInitialize) ID() string { return "MaxRetryDurationInitialize" }
+
+func (*maxRetryDurationInitialize) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	ctx = WithStackValue(ctx, retryDurationTrackerKey{}, &retryDurationTracker{})
+	return next.HandleInitialize(ctx, in)
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMaxRetryDuration(t *testing.T) {
+	cases := map[string]struct {
+		Duration       time.Duration
+		AttemptTime    time.Duration
+		SucceedAt      int
+		ExpectAttempts int
+		ExpectExceeded bool
+	}{
+		"budget exceeded": {
+			Duration:       3500 * time.Millisecond,
+			AttemptTime:    time.Second,
+			ExpectAttempts: 4,
+			ExpectExceeded: true,
+		},
+		"budget exceeded by first attempt": {
+			Duration:       time.Second,
+			AttemptTime:    2 * time.Second,
+			ExpectAttempts: 1,
+			ExpectExceeded: true,
+		},
+		"attempts exhausted within budget": {
+			Duration:       time.Hour,
+			AttemptTime:    time.Second,
+			ExpectAttempts: 10,
+		},
+		"success within budget": {
+			Duration:       3500 * time.Millisecond,
+			AttemptTime:    time.Second,
+			SucceedAt:      3,
+			ExpectAttempts: 3,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := NewStack("stack", func() interface{} { return struct{}{} })
+
+			// mock retry middleware retrying up to 10 attempts, unless the
+			// error is not retryable.
+			err := stack.Finalize.Add(FinalizeMiddlewareFunc("Retry",
+				func(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+					out FinalizeOutput, metadata Metadata, err error,
+				) {
+					for i := 0; i < 10; i++ {
+						out, metadata, err = next.HandleFinalize(ctx, in)
+						var retryable interface{ RetryableError() bool }
+						if err == nil || (errors.As(err, &retryable) && !retryable.RetryableError()) {
+							break
+						}
+					}
+					return out, metadata, err
+				}), After)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if err := AddMaxRetryDurationMiddleware(stack, c.Duration); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			now := time.Unix(0, 0)
+			m, _ := stack.Finalize.Get("MaxRetryDuration")
+			m.(*MaxRetryDuration).now = func() time.Time { return now }
+
+			var attempts int
+			var lastErr error
+			handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+				output interface{}, metadata Metadata, err error,
+			) {
+				attempts++
+				now = now.Add(c.AttemptTime)
+				if attempts == c.SucceedAt {
+					return nil, metadata, nil
+				}
+				lastErr = fmt.Errorf("attempt %d failed", attempts)
+				return nil, metadata, lastErr
+			}), stack)
+
+			_, _, err = handler.Handle(context.Background(), struct{}{})
+			if e, a := c.ExpectAttempts, attempts; e != a {
+				t.Errorf("expect %v attempts, got %v", e, a)
+			}
+			if c.SucceedAt != 0 {
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, lastErr) {
+				t.Errorf("expect last attempt error, got %v", err)
+			}
+			var exceeded *MaxRetryDurationExceededError
+			if e, a := c.ExpectExceeded, errors.As(err, &exceeded); e != a {
+				t.Errorf("expect exceeded %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestAddMaxRetryDurationMiddleware_Invalid(t *testing.T) {
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+	if err := AddMaxRetryDurationMiddleware(stack, 0); err == nil {
+		t.Fatalf("expect error, got none")
+	}
+}
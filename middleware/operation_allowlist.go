@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+)
+
+// OperationNotAllowedError is returned by the OperationAllowlist middleware
+// when an operation not in the allowlist is invoked.
+type OperationNotAllowedError struct {
+	Operation string
+}
+
+// Error returns the error message.
+func (e *OperationNotAllowedError) Error() string {
+	return fmt.Sprintf("operation %q is not allowed", e.Operation)
+}
+
+// RetryableError returns false, the operation must not be retried.
+func (e *OperationNotAllowedError) RetryableError() bool { return false }
+
+// OperationAllowlist provides an initialize middleware that only permits the
+// operations in its allowlist to be invoked, (e.g. for security restricted
+// builds). Operations are identified by the operation name from
+// GetOperationName. Operations not in the allowlist, including operations
+// without a name, fail with an OperationNotAllowedError without being
+// invoked.
+type OperationAllowlist struct {
+	allowed map[string]struct{}
+}
+
+// NewOperationAllowlist returns an initialized OperationAllowlist middleware
+// permitting the named operations.
+func NewOperationAllowlist(ops ...string) *OperationAllowlist {
+	allowed := make(map[string]struct{}, len(ops))
+	for _, op := range ops {
+		allowed[op] = struct{}{}
+	}
+
+	return &OperationAllowlist{allowed: allowed}
+}
+
+// AddOperationAllowlistMiddleware adds the OperationAllowlist middleware to
+// the front of the stack's Initialize step, so disallowed operations are
+// rejected before any other middleware is invoked.
+func AddOperationAllowlistMiddleware(stack *Stack, ops ...string) error {
+	return stack.Initialize.Add(NewOperationAllowlist(ops...), Before)
+}
+
+// ID returns the identifier for the OperationAllowlist middleware.
+func (*OperationAllowlist) ID() string { return "OperationAllowlist" }
+
+// HandleInitialize rejects the operation if it is not in the allowlist.
+func (m *OperationAllowlist) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	op := GetOperationName(ctx)
+	if _, ok := m.allowed[op]; !ok || len(op) == 0 {
+		return out, metadata, &OperationNotAllowedError{Operation: op}
+	}
+
+	return next.HandleInitialize(ctx, in)
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestOperationAllowlist(t *testing.T) {
+	cases := map[string]struct {
+		Operation     string
+		ExpectAllowed bool
+	}{
+		"allowed": {
+			Operation:     "GetObject",
+			ExpectAllowed: true,
+		},
+		"other allowed": {
+			Operation:     "ListObjects",
+			ExpectAllowed: true,
+		},
+		"disallowed": {
+			Operation: "DeleteObject",
+		},
+		"differs in case": {
+			Operation: "getobject",
+		},
+		"no operation name": {},
+	}
+
+	m := NewOperationAllowlist("GetObject", "ListObjects")
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if len(c.Operation) != 0 {
+				ctx = WithOperationName(ctx, c.Operation)
+			}
+
+			var invoked bool
+			_, _, err := m.HandleInitialize(ctx, InitializeInput{},
+				InitializeHandlerFunc(func(ctx context.Context, in InitializeInput) (
+					out InitializeOutput, metadata Metadata, err error,
+				) {
+					invoked = true
+					return out, metadata, nil
+				}),
+			)
+
+			if e, a := c.ExpectAllowed, invoked; e != a {
+				t.Errorf("expect invoked %v, got %v", e, a)
+			}
+			if c.ExpectAllowed {
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				return
+			}
+
+			var notAllowed *OperationNotAllowedError
+			if !errors.As(err, &notAllowed) {
+				t.Fatalf("expect OperationNotAllowedError, got %v", err)
+			}
+			if e, a := c.Operation, notAllowed.Operation; e != a {
+				t.Errorf("expect %v operation, got %v", e, a)
+			}
+		})
+	}
+}
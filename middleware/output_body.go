@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"reflect"
+)
+
+var readCloserType = reflect.TypeOf((*io.ReadCloser)(nil)).Elem()
+
+// cancelOnOutputBodyClose defers calling cancel until the streaming body of
+// the operation's output is closed, so that the body can continue to be read
+// under the Context after the handler returns. The output's streaming body is
+// its first non-nil io.ReadCloser member. Returns false without deferring
+// cancel if the output has no streaming body.
+func cancelOnOutputBodyClose(result interface{}, cancel context.CancelFunc) bool {
+	v := reflect.ValueOf(result)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return false
+	}
+
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if field.Type() != readCloserType || field.IsNil() || !field.CanSet() {
+			continue
+		}
+
+		field.Set(reflect.ValueOf(&cancelOnCloseBody{
+			ReadCloser: field.Interface().(io.ReadCloser),
+			cancel:     cancel,
+		}))
+		return true
+	}
+
+	return false
+}
+
+// cancelOnCloseBody is a streaming output body that cancels the Context it is
+// read under when closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
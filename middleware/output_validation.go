@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	smithy "github.com/aws/smithy-go"
+)
+
+// OutputValidator validates the deserialized output of an operation, returning
+// an error if the output is invalid, (e.g. a required member is missing).
+// Validators may use smithy.InvalidParamsError, and smithy.NewErrParamRequired
+// to describe the invalid members.
+type OutputValidator func(output interface{}) error
+
+// OutputValidation provides a deserialize middleware that validates the
+// operation's deserialized output after the response is deserialized. A
+// misbehaving server may omit members the client requires to be present.
+//
+// Invalid outputs fail the operation with a smithy.DeserializationError
+// wrapping the validator's error.
+type OutputValidation struct {
+	Validate OutputValidator
+}
+
+// AddOutputValidationMiddleware adds the OutputValidation middleware to the
+// front of the stack's Deserialize step, so that the output is validated after
+// all other deserialize middleware have handled the response. Returns an error
+// if validate is nil.
+func AddOutputValidationMiddleware(stack *Stack, validate OutputValidator) error {
+	if validate == nil {
+		return fmt.Errorf("output validator must not be nil")
+	}
+	return stack.Deserialize.Add(&OutputValidation{Validate: validate}, Before)
+}
+
+// ID returns the identifier for the OutputValidation middleware.
+func (*OutputValidation) ID() string { return "OutputValidation" }
+
+// HandleDeserialize validates the deserialized output returned by the next
+// handler.
+func (m *OutputValidation) HandleDeserialize(ctx context.Context, in DeserializeInput, next DeserializeHandler) (
+	out DeserializeOutput, metadata Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	if err := m.Validate(out.Result); err != nil {
+		return out, metadata, &smithy.DeserializationError{Err: err}
+	}
+
+	return out, metadata, nil
+}
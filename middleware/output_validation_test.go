@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	smithy "github.com/aws/smithy-go"
+)
+
+func TestOutputValidation(t *testing.T) {
+	type output struct {
+		ID   *string
+		Name string
+	}
+
+	validate := func(v interface{}) error {
+		o := v.(*output)
+		invalidParams := smithy.InvalidParamsError{Context: "GetThingOutput"}
+		if o.ID == nil {
+			invalidParams.Add(smithy.NewErrParamRequired("ID"))
+		}
+		if len(o.Name) == 0 {
+			invalidParams.Add(smithy.NewErrParamRequired("Name"))
+		}
+		if invalidParams.Len() > 0 {
+			return invalidParams
+		}
+		return nil
+	}
+
+	id := "abc123"
+
+	cases := map[string]struct {
+		Output    *output
+		ExpectErr string
+	}{
+		"complete output": {
+			Output: &output{ID: &id, Name: "thing"},
+		},
+		"missing required member": {
+			Output:    &output{Name: "thing"},
+			ExpectErr: "GetThingOutput.ID",
+		},
+		"empty required member": {
+			Output:    &output{ID: &id},
+			ExpectErr: "GetThingOutput.Name",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := NewStack("stack", func() interface{} { return struct{}{} })
+			if err := AddOutputValidationMiddleware(stack, validate); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			err := stack.Deserialize.Add(DeserializeMiddlewareFunc("OperationDeserializer",
+				func(ctx context.Context, in DeserializeInput, next DeserializeHandler) (
+					out DeserializeOutput, metadata Metadata, err error,
+				) {
+					out.Result = c.Output
+					return out, metadata, nil
+				}), After)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+				output interface{}, metadata Metadata, err error,
+			) {
+				return nil, metadata, nil
+			}), stack)
+
+			result, _, err := handler.Handle(context.Background(), struct{}{})
+			if len(c.ExpectErr) != 0 {
+				var deserializeErr *smithy.DeserializationError
+				if !errors.As(err, &deserializeErr) {
+					t.Fatalf("expect %T error, got %v", deserializeErr, err)
+				}
+				var paramsErr smithy.InvalidParamsError
+				if !errors.As(err, &paramsErr) {
+					t.Fatalf("expect %T error, got %v", paramsErr, err)
+				}
+				if e, a := c.ExpectErr, paramsErr.Errs()[0].(smithy.InvalidParamError).Field(); e != a {
+					t.Errorf("expect %v field, got %v", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.Output, result; e != a {
+				t.Errorf("expect %v result, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestAddOutputValidationMiddleware(t *testing.T) {
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+	if err := AddOutputValidationMiddleware(stack, nil); err == nil {
+		t.Fatalf("expect error for nil validator, got none")
+	}
+
+	err := AddOutputValidationMiddleware(stack, func(interface{}) error { return nil })
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if _, ok := stack.Deserialize.Get("OutputValidation"); !ok {
+		t.Errorf("expect OutputValidation middleware in stack")
+	}
+}
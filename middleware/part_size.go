@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+)
+
+// PartSizeTooSmallError is returned by the PartSizeFloor middleware when a
+// multipart upload part, that is not the last part, is smaller than the minimum
+// part size.
+type PartSizeTooSmallError struct {
+	Size    int64
+	MinSize int64
+}
+
+// Error returns the error message.
+func (e *PartSizeTooSmallError) Error() string {
+	return fmt.Sprintf("part size %d bytes is less than minimum part size of %d bytes", e.Size, e.MinSize)
+}
+
+// PartSizer returns the size in bytes of the multipart upload part of the
+// operation input, and if the part is the last part of the upload. ok is
+// false if the input is not a part upload.
+type PartSizer func(input interface{}) (size int64, last bool, ok bool)
+
+// PartSizeFloor provides an initialize middleware that validates the size of
+// multipart upload parts meet a minimum size, except the last part of the
+// upload. Validating the part size before the request is sent prevents the
+// upload failing late with the service rejecting the part.
+type PartSizeFloor struct {
+	// MinSize is the minimum size, in bytes, of all parts but the last.
+	MinSize int64
+
+	// PartSize returns the size of the operation input's part.
+	PartSize PartSizer
+}
+
+// AddPartSizeFloorMiddleware adds the PartSizeFloor middleware to the end of
+// the stack's Initialize step.
+func AddPartSizeFloorMiddleware(stack *Stack, minSize int64, partSize PartSizer) error {
+	return stack.Initialize.Add(&PartSizeFloor{
+		MinSize:  minSize,
+		PartSize: partSize,
+	}, After)
+}
+
+// ID returns the identifier for the PartSizeFloor middleware.
+func (*PartSizeFloor) ID() string { return "PartSizeFloor" }
+
+// HandleInitialize validates the size of the input's part is at least the
+// minimum part size, unless it is the last part.
+func (m *PartSizeFloor) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	if err := ValidatePartSize(m.PartSize, in.Parameters, m.MinSize); err != nil {
+		return out, metadata, err
+	}
+
+	return next.HandleInitialize(ctx, in)
+}
+
+// ValidatePartSize returns a PartSizeTooSmallError if the size of the input's
+// part is less than minSize, and the part is not the last part. Allows
+// multipart upload utilities to validate their configured part size before
+// starting an upload.
+func ValidatePartSize(partSize PartSizer, input interface{}, minSize int64) error {
+	size, last, ok := partSize(input)
+	if !ok || last {
+		return nil
+	}
+	if size < minSize {
+		return &PartSizeTooSmallError{Size: size, MinSize: minSize}
+	}
+	return nil
+}
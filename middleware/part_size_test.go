@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPartSizeFloor(t *testing.T) {
+	type uploadPart struct {
+		Size int64
+		Last bool
+	}
+
+	partSize := func(input interface{}) (int64, bool, bool) {
+		v, ok := input.(*uploadPart)
+		if !ok {
+			return 0, false, false
+		}
+		return v.Size, v.Last, true
+	}
+
+	const minSize = 5 * 1024 * 1024
+
+	cases := map[string]struct {
+		Input     interface{}
+		ExpectErr bool
+	}{
+		"valid part size": {
+			Input: &uploadPart{Size: minSize},
+		},
+		"undersized part": {
+			Input:     &uploadPart{Size: minSize - 1},
+			ExpectErr: true,
+		},
+		"undersized last part": {
+			Input: &uploadPart{Size: 1, Last: true},
+		},
+		"not a part upload": {
+			Input: struct{}{},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := &PartSizeFloor{MinSize: minSize, PartSize: partSize}
+
+			var called bool
+			_, _, err := m.HandleInitialize(context.Background(),
+				InitializeInput{Parameters: c.Input},
+				InitializeHandlerFunc(func(ctx context.Context, in InitializeInput) (
+					out InitializeOutput, metadata Metadata, err error,
+				) {
+					called = true
+					return out, metadata, nil
+				}),
+			)
+
+			if c.ExpectErr {
+				var sizeErr *PartSizeTooSmallError
+				if !errors.As(err, &sizeErr) {
+					t.Fatalf("expect %T error, got %v", sizeErr, err)
+				}
+				if e, a := int64(minSize), sizeErr.MinSize; e != a {
+					t.Errorf("expect %v minimum size, got %v", e, a)
+				}
+				if called {
+					t.Errorf("expect operation not to continue")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if !called {
+				t.Errorf("expect operation to continue")
+			}
+		})
+	}
+}
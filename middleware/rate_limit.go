@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimit provides the token bucket parameters for rate limiting an
+// operation.
+type RateLimit struct {
+	// Rate is the number of operation invocations permitted per second. Must
+	// be greater than zero.
+	Rate float64
+
+	// Burst is the maximum number of operation invocations that may be
+	// permitted at once. Defaults to 1 if less than 1.
+	Burst int
+}
+
+// PerOperationRateLimit provides an initialize middleware that limits the rate
+// operations are invoked, keyed by the operation name from the Context. The
+// middleware blocks until the operation is permitted, or the Context is
+// canceled. Operations without a configured limit are not rate limited.
+//
+// A single PerOperationRateLimit should be shared by all operation stacks of a
+// client, so that the limits apply across invocations.
+type PerOperationRateLimit struct {
+	limiters map[string]*tokenBucket
+}
+
+// NewPerOperationRateLimit returns an initialized PerOperationRateLimit
+// middleware for the operation limits provided. Returns an error if any limit
+// has a rate that isn't greater than zero.
+func NewPerOperationRateLimit(limits map[string]RateLimit) (*PerOperationRateLimit, error) {
+	limiters := make(map[string]*tokenBucket, len(limits))
+	for name, limit := range limits {
+		if limit.Rate <= 0 {
+			return nil, fmt.Errorf("rate limit for operation %s must be greater than zero, got %v",
+				name, limit.Rate)
+		}
+		limiters[name] = newTokenBucket(limit, time.Now)
+	}
+
+	return &PerOperationRateLimit{
+		limiters: limiters,
+	}, nil
+}
+
+// AddPerOperationRateLimitMiddleware adds the PerOperationRateLimit
+// middleware to the stack's Initialize step.
+func AddPerOperationRateLimitMiddleware(stack *Stack, m *PerOperationRateLimit) error {
+	return stack.Initialize.Add(m, After)
+}
+
+// ID returns the identifier for the PerOperationRateLimit middleware.
+func (*PerOperationRateLimit) ID() string { return "PerOperationRateLimit" }
+
+// HandleInitialize waits until the operation is permitted by its rate limit
+// before continuing.
+func (m *PerOperationRateLimit) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	limiter, ok := m.limiters[GetOperationName(ctx)]
+	if !ok {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	if err := limiter.Wait(ctx); err != nil {
+		return out, metadata, fmt.Errorf("failed waiting for operation rate limit, %w", err)
+	}
+
+	return next.HandleInitialize(ctx, in)
+}
+
+// tokenBucket provides a token bucket rate limiter safe for concurrent use.
+type tokenBucket struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit, now func() time.Time) *tokenBucket {
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		rate:   limit.Rate,
+		burst:  burst,
+		now:    now,
+		tokens: burst,
+		last:   now(),
+	}
+}
+
+// Wait blocks until a token is available, or the Context is canceled.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	for {
+		delay := b.reserve()
+		if delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token if one is available, returning zero. Otherwise
+// returns the duration until the next token will be available.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
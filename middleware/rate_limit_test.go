@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPerOperationRateLimit(t *testing.T) {
+	m, err := NewPerOperationRateLimit(map[string]RateLimit{
+		"Throttled": {Rate: 0.001, Burst: 1},
+	})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	invoke := func(operation string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		ctx = WithOperationName(ctx, operation)
+
+		_, _, err := m.HandleInitialize(ctx, InitializeInput{},
+			InitializeHandlerFunc(func(ctx context.Context, in InitializeInput) (
+				out InitializeOutput, metadata Metadata, err error,
+			) {
+				return out, metadata, nil
+			}),
+		)
+		return err
+	}
+
+	// Burst of the throttled operation is permitted.
+	if err := invoke("Throttled"); err != nil {
+		t.Fatalf("expect first throttled operation to be permitted, got %v", err)
+	}
+
+	// Subsequent invocations are blocked until the Context times out.
+	err = invoke("Throttled")
+	if err == nil {
+		t.Fatalf("expect throttled operation to be blocked")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect deadline exceeded error, got %v", err)
+	}
+
+	// Other operations are not affected.
+	for i := 0; i < 5; i++ {
+		if err := invoke("Unlimited"); err != nil {
+			t.Fatalf("expect unlimited operation to be permitted, got %v", err)
+		}
+	}
+}
+
+func TestTokenBucket_Refill(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTokenBucket(RateLimit{Rate: 2, Burst: 2}, func() time.Time { return now })
+
+	for i := 0; i < 2; i++ {
+		if d := b.reserve(); d != 0 {
+			t.Fatalf("expect burst token %d to be available, got %v delay", i, d)
+		}
+	}
+	if e, a := 500*time.Millisecond, b.reserve(); e != a {
+		t.Errorf("expect %v delay, got %v", e, a)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if d := b.reserve(); d != 0 {
+		t.Errorf("expect refilled token to be available, got %v delay", d)
+	}
+
+	now = now.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		if d := b.reserve(); d != 0 {
+			t.Fatalf("expect token %d to be available, got %v delay", i, d)
+		}
+	}
+	if d := b.reserve(); d == 0 {
+		t.Errorf("expect tokens to be capped at burst")
+	}
+}
+
+func TestNewPerOperationRateLimit_InvalidRate(t *testing.T) {
+	_, err := NewPerOperationRateLimit(map[string]RateLimit{
+		"Foo": {Rate: 0},
+	})
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+}
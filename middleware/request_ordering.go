@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+)
+
+// OrderingKeyFunc returns the key an operation's execution is serialized by,
+// for the operation's input. Operations with an empty key are not serialized.
+type OrderingKeyFunc func(input interface{}) string
+
+// RequestOrdering provides an initialize middleware that serializes the
+// execution of operations with the same key, (e.g. writes to the same
+// resource). Operations with different keys execute concurrently.
+//
+// The middleware must be shared between the stacks of the operations to be
+// serialized, with the same RequestOrdering value added to each stack.
+type RequestOrdering struct {
+	key OrderingKeyFunc
+
+	mu    sync.Mutex
+	locks map[string]*orderingLock
+}
+
+// orderingLock is the lock of a single key. The lock is a buffered channel so
+// that waiting for it can be canceled with the operation's context.
+type orderingLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+// NewRequestOrdering returns an initialized RequestOrdering middleware
+// serializing operations by the key returned by key.
+func NewRequestOrdering(key OrderingKeyFunc) *RequestOrdering {
+	return &RequestOrdering{
+		key:   key,
+		locks: map[string]*orderingLock{},
+	}
+}
+
+// AddRequestOrderingMiddleware adds the RequestOrdering middleware to the
+// front of the stack's Initialize step.
+func AddRequestOrderingMiddleware(stack *Stack, m *RequestOrdering) error {
+	return stack.Initialize.Add(m, Before)
+}
+
+// ID returns the identifier for the RequestOrdering middleware.
+func (*RequestOrdering) ID() string { return "RequestOrdering" }
+
+// HandleInitialize waits until no other operation with the same key is
+// executing before continuing with the operation. Returns the context's error
+// if the context is canceled while waiting.
+func (m *RequestOrdering) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	key := m.key(in.Parameters)
+	if len(key) == 0 {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	lock := m.acquireRef(key)
+	defer m.releaseRef(key, lock)
+
+	select {
+	case lock.ch <- struct{}{}:
+	case <-ctx.Done():
+		return out, metadata, ctx.Err()
+	}
+	defer func() { <-lock.ch }()
+
+	return next.HandleInitialize(ctx, in)
+}
+
+func (m *RequestOrdering) acquireRef(key string) *orderingLock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lock, ok := m.locks[key]
+	if !ok {
+		lock = &orderingLock{ch: make(chan struct{}, 1)}
+		m.locks[key] = lock
+	}
+	lock.refs++
+	return lock
+}
+
+// releaseRef removes the key's lock once no operations are using it, so that
+// locks of keys no longer in use are not retained.
+func (m *RequestOrdering) releaseRef(key string, lock *orderingLock) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lock.refs--
+	if lock.refs == 0 {
+		delete(m.locks, key)
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRequestOrdering(t *testing.T) {
+	cases := map[string]struct {
+		Keys          [2]string
+		ExpectOverlap bool
+	}{
+		"same key": {
+			Keys: [2]string{"resource-a", "resource-a"},
+		},
+		"different keys": {
+			Keys:          [2]string{"resource-a", "resource-b"},
+			ExpectOverlap: true,
+		},
+		"empty key": {
+			Keys:          [2]string{"", ""},
+			ExpectOverlap: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := NewRequestOrdering(func(input interface{}) string {
+				return input.(string)
+			})
+
+			var mu sync.Mutex
+			var running, maxRunning int
+
+			handler := InitializeHandlerFunc(func(ctx context.Context, in InitializeInput) (
+				out InitializeOutput, metadata Metadata, err error,
+			) {
+				mu.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mu.Unlock()
+
+				time.Sleep(50 * time.Millisecond)
+
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return out, metadata, nil
+			})
+
+			var wg sync.WaitGroup
+			for _, key := range c.Keys {
+				wg.Add(1)
+				go func(key string) {
+					defer wg.Done()
+					_, _, err := m.HandleInitialize(context.Background(),
+						InitializeInput{Parameters: key}, handler)
+					if err != nil {
+						t.Errorf("expect no error, got %v", err)
+					}
+				}(key)
+			}
+			wg.Wait()
+
+			if e, a := c.ExpectOverlap, maxRunning > 1; e != a {
+				t.Errorf("expect overlap %v, got %v", e, a)
+			}
+			if e, a := 0, len(m.locks); e != a {
+				t.Errorf("expect %v retained locks, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestRequestOrdering_CanceledWhileWaiting(t *testing.T) {
+	m := NewRequestOrdering(func(interface{}) string { return "key" })
+
+	release := make(chan struct{})
+	acquired := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.HandleInitialize(context.Background(), InitializeInput{},
+			InitializeHandlerFunc(func(ctx context.Context, in InitializeInput) (
+				out InitializeOutput, metadata Metadata, err error,
+			) {
+				close(acquired)
+				<-release
+				return out, metadata, nil
+			}),
+		)
+	}()
+	<-acquired
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := m.HandleInitialize(ctx, InitializeInput{},
+		InitializeHandlerFunc(func(ctx context.Context, in InitializeInput) (
+			out InitializeOutput, metadata Metadata, err error,
+		) {
+			t.Errorf("expect handler not to be called")
+			return out, metadata, nil
+		}),
+	)
+	if e, a := context.Canceled, err; e != a {
+		t.Errorf("expect %v error, got %v", e, a)
+	}
+
+	close(release)
+	<-done
+}
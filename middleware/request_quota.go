@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// RequestCounter provides the interface for counting outbound requests by
+// key.
+type RequestCounter interface {
+	// IncrementRequestCount increments the count of requests for the key,
+	// returning the updated count.
+	IncrementRequestCount(key string) int64
+}
+
+// QuotaChecker provides the interface for checking if an outbound request
+// is permitted by the quota for its key.
+type QuotaChecker interface {
+	// CheckQuota returns an error if sending another request for the key
+	// would exceed the key's quota.
+	CheckQuota(key string) error
+}
+
+// QuotaExceededError is returned when an outbound request is rejected because
+// the quota for its key has been reached.
+type QuotaExceededError struct {
+	Key   string
+	Limit int64
+}
+
+// Error returns the error message.
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("request quota of %d exceeded for %s", e.Limit, e.Key)
+}
+
+// RequestQuota provides a finalize middleware that counts each outbound
+// request attempt, keyed by the operation name from the Context. If a
+// QuotaChecker is provided, the quota is checked before the request is counted
+// and sent. Rejected requests are not counted.
+type RequestQuota struct {
+	counter RequestCounter
+	checker QuotaChecker
+}
+
+// NewRequestQuota returns an initialized RequestQuota middleware. The checker
+// may be nil if requests should only be counted.
+func NewRequestQuota(counter RequestCounter, checker QuotaChecker) *RequestQuota {
+	return &RequestQuota{
+		counter: counter,
+		checker: checker,
+	}
+}
+
+// AddRequestQuotaMiddleware adds the RequestQuota middleware to the end of
+// the stack's Finalize step.
+func AddRequestQuotaMiddleware(stack *Stack, counter RequestCounter, checker QuotaChecker) error {
+	return stack.Finalize.Add(NewRequestQuota(counter, checker), After)
+}
+
+// ID returns the identifier for the RequestQuota middleware.
+func (*RequestQuota) ID() string { return "RequestQuota" }
+
+// HandleFinalize checks the quota for the operation, counting the request
+// before it is sent.
+func (m *RequestQuota) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	key := GetOperationName(ctx)
+
+	if m.checker != nil {
+		if err := m.checker.CheckQuota(key); err != nil {
+			return out, metadata, err
+		}
+	}
+	m.counter.IncrementRequestCount(key)
+
+	return next.HandleFinalize(ctx, in)
+}
+
+// RequestCounts provides a RequestCounter safe for concurrent use.
+type RequestCounts struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+// NewRequestCounts returns an initialized RequestCounts.
+func NewRequestCounts() *RequestCounts {
+	return &RequestCounts{
+		counts: map[string]int64{},
+	}
+}
+
+// IncrementRequestCount increments the count of requests for the key,
+// returning the updated count.
+func (c *RequestCounts) IncrementRequestCount(key string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counts[key]++
+	return c.counts[key]
+}
+
+// RequestCount returns the count of requests for the key.
+func (c *RequestCounts) RequestCount(key string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.counts[key]
+}
+
+// QuotaLimits provides a QuotaChecker that rejects requests once the count of
+// requests for a key reaches the key's limit. Keys without a limit are not
+// rejected.
+//
+// The check and increment of the count are not atomic, concurrent requests
+// may exceed the limit by the number of requests in flight.
+type QuotaLimits struct {
+	Counts *RequestCounts
+	Limits map[string]int64
+}
+
+// CheckQuota returns a QuotaExceededError if the count of requests for the key
+// has reached the key's limit.
+func (q QuotaLimits) CheckQuota(key string) error {
+	limit, ok := q.Limits[key]
+	if !ok {
+		return nil
+	}
+	if q.Counts.RequestCount(key) >= limit {
+		return &QuotaExceededError{Key: key, Limit: limit}
+	}
+	return nil
+}
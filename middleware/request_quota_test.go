@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRequestQuota(t *testing.T) {
+	counts := NewRequestCounts()
+	m := NewRequestQuota(counts, QuotaLimits{
+		Counts: counts,
+		Limits: map[string]int64{"Limited": 2},
+	})
+
+	var sent int
+	invoke := func(operation string) error {
+		ctx := WithOperationName(context.Background(), operation)
+		_, _, err := m.HandleFinalize(ctx, FinalizeInput{},
+			FinalizeHandlerFunc(func(ctx context.Context, in FinalizeInput) (
+				out FinalizeOutput, metadata Metadata, err error,
+			) {
+				sent++
+				return out, metadata, nil
+			}),
+		)
+		return err
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := invoke("Limited"); err != nil {
+			t.Fatalf("expect request %d to be permitted, got %v", i, err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if err := invoke("Unlimited"); err != nil {
+			t.Fatalf("expect unlimited request %d to be permitted, got %v", i, err)
+		}
+	}
+
+	err := invoke("Limited")
+	if err == nil {
+		t.Fatalf("expect quota exceeded error, got none")
+	}
+	var quotaErr *QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("expect %T error, got %v", quotaErr, err)
+	}
+	if e, a := "Limited", quotaErr.Key; e != a {
+		t.Errorf("expect %v key, got %v", e, a)
+	}
+
+	if e, a := int64(2), counts.RequestCount("Limited"); e != a {
+		t.Errorf("expect %v limited requests counted, got %v", e, a)
+	}
+	if e, a := int64(3), counts.RequestCount("Unlimited"); e != a {
+		t.Errorf("expect %v unlimited requests counted, got %v", e, a)
+	}
+	if e, a := 5, sent; e != a {
+		t.Errorf("expect %v requests sent, got %v", e, a)
+	}
+}
+
+func TestRequestQuota_CountOnly(t *testing.T) {
+	counts := NewRequestCounts()
+	m := NewRequestQuota(counts, nil)
+
+	ctx := WithOperationName(context.Background(), "Foo")
+	for i := 0; i < 3; i++ {
+		_, _, err := m.HandleFinalize(ctx, FinalizeInput{},
+			FinalizeHandlerFunc(func(ctx context.Context, in FinalizeInput) (
+				out FinalizeOutput, metadata Metadata, err error,
+			) {
+				return out, metadata, nil
+			}),
+		)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+	}
+
+	if e, a := int64(3), counts.RequestCount("Foo"); e != a {
+		t.Errorf("expect %v requests counted, got %v", e, a)
+	}
+}
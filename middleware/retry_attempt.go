@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+)
+
+// InsertAfterRetry adds the middleware to the stack's Finalize step after the
+// "Retry" middleware, if present, otherwise to the end of the step, so that
+// the middleware is invoked for each of an operation's request attempts. The
+// middleware must be invoked by the retry middleware for each attempt.
+//
+// The middleware to number the operation's request attempts is added with the
+// first middleware, and the middleware added are invoked after it. The
+// attempt number is shared by all middleware added with InsertAfterRetry.
+func InsertAfterRetry(stack *Stack, m FinalizeMiddleware) error {
+	if err := addRetryAttemptMiddleware(stack); err != nil {
+		return err
+	}
+	if _, ok := stack.Finalize.Get("Retry"); ok {
+		return stack.Finalize.Insert(m, "RetryAttempt", After)
+	}
+	return stack.Finalize.Add(m, After)
+}
+
+// addRetryAttemptMiddleware adds the middleware to seed the operation's
+// attempt count, and number each attempt, if not already added.
+func addRetryAttemptMiddleware(stack *Stack) error {
+	if _, ok := stack.Initialize.Get("RetryAttemptInitialize"); !ok {
+		if err := stack.Initialize.Add(&retryAttemptInitialize{}, Before); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := stack.Finalize.Get("RetryAttempt"); ok {
+		return nil
+	}
+	if _, ok := stack.Finalize.Get("Retry"); ok {
+		return stack.Finalize.Insert(&retryAttempt{}, "Retry", After)
+	}
+	return stack.Finalize.Add(&retryAttempt{}, After)
+}
+
+type retryAttemptNumberKey struct{}
+
+// getAttemptNumber returns the number of the operation's request attempt,
+// starting at 1. Returns 0 if the attempts are not numbered.
+func getAttemptNumber(ctx context.Context) int {
+	v, _ := GetStackValue(ctx, retryAttemptNumberKey{}).(int)
+	return v
+}
+
+// retryAttemptCount is the operation scoped count of attempts made.
+type retryAttemptCount struct {
+	mu    sync.Mutex
+	count int
+}
+
+// next increments and returns the attempt number.
+func (c *retryAttemptCount) next() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.count++
+	return c.count
+}
+
+type retryAttemptCountKey struct{}
+
+// retryAttemptInitialize seeds the operation's attempt count.
+type retryAttemptInitialize struct{}
+
+func (*retryAttemptInitialize) ID() string { return "RetryAttemptInitialize" }
+
+func (*retryAttemptInitialize) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	ctx = WithStackValue(ctx, retryAttemptCountKey{}, &retryAttemptCount{})
+	return next.HandleInitialize(ctx, in)
+}
+
+// retryAttempt numbers each of the operation's request attempts.
+type retryAttempt struct{}
+
+func (*retryAttempt) ID() string { return "RetryAttempt" }
+
+func (*retryAttempt) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	count, ok := GetStackValue(ctx, retryAttemptCountKey{}).(*retryAttemptCount)
+	if !ok {
+		return next.HandleFinalize(ctx, in)
+	}
+
+	ctx = WithStackValue(ctx, retryAttemptNumberKey{}, count.next())
+	return next.HandleFinalize(ctx, in)
+}
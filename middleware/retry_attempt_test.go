@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestInsertAfterRetry(t *testing.T) {
+	cases := map[string]struct {
+		WithRetry   bool
+		ExpectOrder []string
+	}{
+		"with retry": {
+			WithRetry:   true,
+			ExpectOrder: []string{"Retry", "RetryAttempt", "second", "first"},
+		},
+		"without retry": {
+			ExpectOrder: []string{"RetryAttempt", "first", "second"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := NewStack("stack", func() interface{} { return struct{}{} })
+
+			attempts := 1
+			if c.WithRetry {
+				attempts = 3
+
+				// mock retry middleware making three attempts.
+				err := stack.Finalize.Add(FinalizeMiddlewareFunc("Retry",
+					func(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+						out FinalizeOutput, metadata Metadata, err error,
+					) {
+						for i := 0; i < 3; i++ {
+							out, metadata, err = next.HandleFinalize(ctx, in)
+						}
+						return out, metadata, err
+					}), After)
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+			}
+
+			numbers := map[string][]int{}
+			for _, id := range []string{"first", "second"} {
+				id := id
+				err := InsertAfterRetry(stack, FinalizeMiddlewareFunc(id,
+					func(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+						out FinalizeOutput, metadata Metadata, err error,
+					) {
+						numbers[id] = append(numbers[id], getAttemptNumber(ctx))
+						return next.HandleFinalize(ctx, in)
+					}))
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+			}
+
+			if e, a := fmt.Sprint(c.ExpectOrder), fmt.Sprint(stack.Finalize.List()); e != a {
+				t.Errorf("expect %v order, got %v", e, a)
+			}
+
+			handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+				output interface{}, metadata Metadata, err error,
+			) {
+				return nil, metadata, nil
+			}), stack)
+			if _, _, err := handler.Handle(context.Background(), struct{}{}); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var expect []int
+			for i := 1; i <= attempts; i++ {
+				expect = append(expect, i)
+			}
+			for _, id := range []string{"first", "second"} {
+				if e, a := fmt.Sprint(expect), fmt.Sprint(numbers[id]); e != a {
+					t.Errorf("expect %v attempt numbers for %v, got %v", e, id, a)
+				}
+			}
+		})
+	}
+}
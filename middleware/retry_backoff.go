@@ -0,0 +1,266 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/aws/smithy-go/rand"
+	smithytime "github.com/aws/smithy-go/time"
+)
+
+// JitterStrategy is the strategy used to randomize the delay computed by
+// ExponentialBackoff.
+type JitterStrategy int
+
+// Enumeration of the jitter strategies supported by ExponentialBackoff. See
+// https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+const (
+	// FullJitter delays a random duration between zero and the exponential
+	// delay, (sleep = random(0, min(cap, base*2^attempt))).
+	FullJitter JitterStrategy = iota
+
+	// EqualJitter delays half of the exponential delay, plus a random
+	// duration up to the other half.
+	EqualJitter
+
+	// DecorrelatedJitter delays a random duration derived from the previous
+	// delay, instead of the attempt number,
+	// (sleep = min(cap, random(base, prev*3))).
+	DecorrelatedJitter
+)
+
+func (s JitterStrategy) String() string {
+	switch s {
+	case FullJitter:
+		return "FullJitter"
+	case EqualJitter:
+		return "EqualJitter"
+	case DecorrelatedJitter:
+		return "DecorrelatedJitter"
+	default:
+		return fmt.Sprintf("JitterStrategy(%d)", int(s))
+	}
+}
+
+// ExponentialBackoff computes the delay before retrying an operation's
+// request, growing exponentially from Base, bounded by Cap, and randomized by
+// the Jitter strategy. The randomized delay is then bounded by MinDelay and
+// MaxDelay.
+type ExponentialBackoff struct {
+	Base time.Duration
+	Cap  time.Duration
+
+	Jitter JitterStrategy
+
+	// MinDelay is the minimum delay of a retry attempt, applied after jitter.
+	// Zero applies no minimum.
+	MinDelay time.Duration
+
+	// MaxDelay is the maximum delay of a retry attempt, applied after jitter.
+	// Zero applies no maximum. Must not be less than MinDelay.
+	MaxDelay time.Duration
+
+	// Rand is the random source the jitter is read from. Defaults to the
+	// smithy-go rand package's Reader if nil.
+	Rand io.Reader
+}
+
+// ComputeDelay returns the delay before the retry attempt, starting at 1 for
+// the first retry. prevDelay is the delay returned for the previous retry,
+// used by the DecorrelatedJitter strategy, and zero for the first retry.
+func (b *ExponentialBackoff) ComputeDelay(attempt int, prevDelay time.Duration) (time.Duration, error) {
+	if err := b.Validate(); err != nil {
+		return 0, err
+	}
+	if attempt < 1 {
+		return 0, nil
+	}
+
+	delay, err := b.jitterDelay(attempt, prevDelay)
+	if err != nil {
+		return 0, err
+	}
+
+	if delay < b.MinDelay {
+		delay = b.MinDelay
+	}
+	if b.MaxDelay > 0 && delay > b.MaxDelay {
+		delay = b.MaxDelay
+	}
+	return delay, nil
+}
+
+// Validate returns an error if the backoff's configuration is invalid, (e.g.
+// MinDelay is larger than MaxDelay).
+func (b *ExponentialBackoff) Validate() error {
+	if b.Base <= 0 || b.Cap < b.Base {
+		return fmt.Errorf("backoff base must be positive and not larger than cap, got base %v, cap %v",
+			b.Base, b.Cap)
+	}
+	if b.MinDelay < 0 || b.MaxDelay < 0 {
+		return fmt.Errorf("backoff delay bounds must not be negative, got min %v, max %v",
+			b.MinDelay, b.MaxDelay)
+	}
+	if b.MaxDelay > 0 && b.MinDelay > b.MaxDelay {
+		return fmt.Errorf("backoff min delay must not be larger than max delay, got min %v, max %v",
+			b.MinDelay, b.MaxDelay)
+	}
+	switch b.Jitter {
+	case FullJitter, EqualJitter, DecorrelatedJitter:
+	default:
+		return fmt.Errorf("unknown jitter strategy %v", b.Jitter)
+	}
+	return nil
+}
+
+// jitterDelay returns the delay of the attempt randomized by the backoff's
+// jitter strategy.
+func (b *ExponentialBackoff) jitterDelay(attempt int, prevDelay time.Duration) (time.Duration, error) {
+	switch b.Jitter {
+	case FullJitter:
+		return b.random(0, b.exponentialDelay(attempt))
+
+	case EqualJitter:
+		delay := b.exponentialDelay(attempt)
+		jitter, err := b.random(0, delay-delay/2)
+		if err != nil {
+			return 0, err
+		}
+		return delay/2 + jitter, nil
+
+	case DecorrelatedJitter:
+		if prevDelay < b.Base {
+			prevDelay = b.Base
+		}
+		upper := b.Cap
+		if prevDelay <= b.Cap/3 {
+			upper = prevDelay * 3
+		}
+		return b.random(b.Base, upper)
+
+	default:
+		return 0, fmt.Errorf("unknown jitter strategy %v", b.Jitter)
+	}
+}
+
+// exponentialDelay returns min(cap, base*2^(attempt-1)), without overflowing.
+func (b *ExponentialBackoff) exponentialDelay(attempt int) time.Duration {
+	delay := b.Base
+	for i := 1; i < attempt; i++ {
+		if delay > b.Cap/2 {
+			return b.Cap
+		}
+		delay *= 2
+	}
+	return delay
+}
+
+// random returns a random duration in the inclusive range [lower, upper].
+func (b *ExponentialBackoff) random(lower, upper time.Duration) (time.Duration, error) {
+	if upper <= lower {
+		return lower, nil
+	}
+
+	reader := b.Rand
+	if reader == nil {
+		reader = rand.Reader
+	}
+
+	n, err := rand.Int63n(reader, int64(upper-lower)+1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compute backoff jitter, %w", err)
+	}
+	return lower + time.Duration(n), nil
+}
+
+// AddBackoffMiddleware adds the middleware to delay each of an operation's
+// request attempts after the first by the delay computed by backoff. Returns
+// an error if the backoff's configuration is invalid.
+//
+// The delay is applied in the Finalize step after the "Retry" middleware, if
+// present, otherwise at the end of the step. The "Retry" middleware must
+// invoke the next handler for each attempt for the delay to be applied between
+// attempts.
+func AddBackoffMiddleware(stack *Stack, backoff *ExponentialBackoff) error {
+	if err := backoff.Validate(); err != nil {
+		return fmt.Errorf("invalid backoff configuration, %w", err)
+	}
+
+	if err := stack.Initialize.Add(&backoffInitialize{}, Before); err != nil {
+		return err
+	}
+
+	m := &Backoff{Backoff: backoff}
+	return InsertAfterRetry(stack, m)
+}
+
+// backoffState is the operation scoped state of the delay of the previous
+// attempt.
+type backoffState struct {
+	mu        sync.Mutex
+	prevDelay time.Duration
+}
+
+type backoffStateKey struct{}
+
+// backoffInitialize seeds the operation's backoff state.
+type backoffInitialize struct{}
+
+func (*backoffInitialize) ID() string { return "BackoffInitialize" }
+
+func (*backoffInitialize) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	ctx = WithStackValue(ctx, backoffStateKey{}, &backoffState{})
+	return next.HandleInitialize(ctx, in)
+}
+
+// Backoff provides a finalize middleware that delays each of an operation's
+// request attempts after the first by the delay computed by Backoff.
+type Backoff struct {
+	Backoff *ExponentialBackoff
+
+	sleep func(context.Context, time.Duration) error
+}
+
+// ID returns the identifier for the Backoff middleware.
+func (*Backoff) ID() string { return "Backoff" }
+
+// HandleFinalize delays the attempt, if it is a retry. Returns the context's
+// error if the context is canceled while delaying.
+func (m *Backoff) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	state, ok := GetStackValue(ctx, backoffStateKey{}).(*backoffState)
+	if !ok {
+		return next.HandleFinalize(ctx, in)
+	}
+
+	retry := getAttemptNumber(ctx) - 1
+
+	state.mu.Lock()
+	var delay time.Duration
+	if retry > 0 {
+		delay, err = m.Backoff.ComputeDelay(retry, state.prevDelay)
+		state.prevDelay = delay
+	}
+	state.mu.Unlock()
+	if err != nil {
+		return out, metadata, err
+	}
+
+	if delay > 0 {
+		sleep := m.sleep
+		if sleep == nil {
+			sleep = smithytime.SleepWithContext
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return out, metadata, err
+		}
+	}
+
+	return next.HandleFinalize(ctx, in)
+}
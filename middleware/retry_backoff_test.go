@@ -0,0 +1,249 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	mathrand "math/rand"
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff_DecorrelatedJitter(t *testing.T) {
+	b := &ExponentialBackoff{
+		Base:   100 * time.Millisecond,
+		Cap:    5 * time.Second,
+		Jitter: DecorrelatedJitter,
+		Rand:   mathrand.New(mathrand.NewSource(1)),
+	}
+
+	var prev time.Duration
+	var capped bool
+	for attempt := 1; attempt <= 50; attempt++ {
+		delay, err := b.ComputeDelay(attempt, prev)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+
+		lower := b.Base
+		upper := prev * 3
+		if upper < lower*3 {
+			upper = lower * 3
+		}
+		if upper > b.Cap {
+			upper = b.Cap
+		}
+		if delay < lower || delay > upper {
+			t.Errorf("attempt %d, expect delay in [%v, %v], got %v", attempt, lower, upper, delay)
+		}
+		if upper == b.Cap {
+			capped = true
+		}
+		prev = delay
+	}
+	if !capped {
+		t.Errorf("expect delays to reach the cap")
+	}
+
+	// seeded source produces the same sequence
+	b.Rand = mathrand.New(mathrand.NewSource(1))
+	replay := &ExponentialBackoff{
+		Base: b.Base, Cap: b.Cap, Jitter: b.Jitter,
+		Rand: mathrand.New(mathrand.NewSource(1)),
+	}
+	prev = 0
+	for attempt := 1; attempt <= 10; attempt++ {
+		e, _ := b.ComputeDelay(attempt, prev)
+		a, _ := replay.ComputeDelay(attempt, prev)
+		if e != a {
+			t.Fatalf("attempt %d, expect %v delay, got %v", attempt, e, a)
+		}
+		prev = e
+	}
+}
+
+func TestExponentialBackoff_Bounds(t *testing.T) {
+	cases := map[JitterStrategy]struct {
+		Lower, Upper func(exp time.Duration) time.Duration
+	}{
+		FullJitter: {
+			Lower: func(time.Duration) time.Duration { return 0 },
+			Upper: func(exp time.Duration) time.Duration { return exp },
+		},
+		EqualJitter: {
+			Lower: func(exp time.Duration) time.Duration { return exp / 2 },
+			Upper: func(exp time.Duration) time.Duration { return exp },
+		},
+	}
+
+	for strategy, c := range cases {
+		t.Run(strategy.String(), func(t *testing.T) {
+			b := &ExponentialBackoff{
+				Base:   10 * time.Millisecond,
+				Cap:    time.Second,
+				Jitter: strategy,
+				Rand:   mathrand.New(mathrand.NewSource(1)),
+			}
+
+			exp := b.Base
+			for attempt := 1; attempt <= 100; attempt++ {
+				delay, err := b.ComputeDelay(attempt, 0)
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				if lower, upper := c.Lower(exp), c.Upper(exp); delay < lower || delay > upper {
+					t.Errorf("attempt %d, expect delay in [%v, %v], got %v", attempt, lower, upper, delay)
+				}
+
+				if exp *= 2; exp > b.Cap {
+					exp = b.Cap
+				}
+			}
+		})
+	}
+}
+
+func TestExponentialBackoff_Invalid(t *testing.T) {
+	cases := map[string]*ExponentialBackoff{
+		"no base":            {Cap: time.Second},
+		"cap less than base": {Base: time.Second, Cap: time.Millisecond},
+		"unknown jitter":     {Base: time.Millisecond, Cap: time.Second, Jitter: JitterStrategy(10)},
+		"min larger than max": {
+			Base: time.Millisecond, Cap: time.Second,
+			MinDelay: time.Second, MaxDelay: time.Millisecond,
+		},
+		"negative min": {Base: time.Millisecond, Cap: time.Second, MinDelay: -time.Second},
+	}
+
+	for name, b := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := b.ComputeDelay(1, 0); err == nil {
+				t.Fatalf("expect error, got none")
+			}
+
+			stack := NewStack("stack", func() interface{} { return struct{}{} })
+			if err := AddBackoffMiddleware(stack, b); err == nil {
+				t.Fatalf("expect stack build error, got none")
+			}
+		})
+	}
+}
+
+func TestExponentialBackoff_DelayBounds(t *testing.T) {
+	cases := map[string]struct {
+		MinDelay, MaxDelay time.Duration
+	}{
+		"min": {
+			MinDelay: 200 * time.Millisecond,
+		},
+		"max": {
+			MaxDelay: 50 * time.Millisecond,
+		},
+		"min and max": {
+			MinDelay: 20 * time.Millisecond,
+			MaxDelay: 100 * time.Millisecond,
+		},
+		"min equal to max": {
+			MinDelay: 30 * time.Millisecond,
+			MaxDelay: 30 * time.Millisecond,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			b := &ExponentialBackoff{
+				Base:     10 * time.Millisecond,
+				Cap:      time.Second,
+				Jitter:   FullJitter,
+				MinDelay: c.MinDelay,
+				MaxDelay: c.MaxDelay,
+				Rand:     mathrand.New(mathrand.NewSource(1)),
+			}
+
+			stack := NewStack("stack", func() interface{} { return struct{}{} })
+			if err := AddBackoffMiddleware(stack, b); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			for attempt := 1; attempt <= 20; attempt++ {
+				delay, err := b.ComputeDelay(attempt, 0)
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				if delay < c.MinDelay {
+					t.Errorf("attempt %d, expect delay at least %v, got %v", attempt, c.MinDelay, delay)
+				}
+				if c.MaxDelay > 0 && delay > c.MaxDelay {
+					t.Errorf("attempt %d, expect delay at most %v, got %v", attempt, c.MaxDelay, delay)
+				}
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+
+	// mock retry middleware invoking the next handler until it succeeds.
+	err := stack.Finalize.Add(FinalizeMiddlewareFunc("Retry",
+		func(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+			out FinalizeOutput, metadata Metadata, err error,
+		) {
+			for i := 0; i < 5; i++ {
+				out, metadata, err = next.HandleFinalize(ctx, in)
+				if err == nil {
+					break
+				}
+			}
+			return out, metadata, err
+		}), After)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	backoff := &ExponentialBackoff{
+		Base:   100 * time.Millisecond,
+		Cap:    time.Second,
+		Jitter: DecorrelatedJitter,
+		Rand:   mathrand.New(mathrand.NewSource(1)),
+	}
+	if err := AddBackoffMiddleware(stack, backoff); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	var delays []time.Duration
+	m, _ := stack.Finalize.Get("Backoff")
+	m.(*Backoff).sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	var attempt int
+	handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+		output interface{}, metadata Metadata, err error,
+	) {
+		attempt++
+		if attempt < 4 {
+			return nil, metadata, fmt.Errorf("attempt %d failed", attempt)
+		}
+		return nil, metadata, nil
+	}), stack)
+
+	if _, _, err := handler.Handle(context.Background(), struct{}{}); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if e, a := 3, len(delays); e != a {
+		t.Fatalf("expect %v delays, got %v", e, a)
+	}
+	prev := backoff.Base
+	for i, delay := range delays {
+		upper := prev * 3
+		if upper > backoff.Cap {
+			upper = backoff.Cap
+		}
+		if delay < backoff.Base || delay > upper {
+			t.Errorf("delay %d, expect in [%v, %v], got %v", i, backoff.Base, upper, delay)
+		}
+		prev = delay
+	}
+}
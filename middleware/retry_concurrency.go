@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+)
+
+// RetryConcurrencyLimit provides a finalize middleware that limits how many
+// retry attempts, attempts of an operation's request after the first, may run
+// concurrently. Retry attempts beyond the limit wait until a running retry
+// attempt completes, or the Context is canceled. First attempts are not
+// limited.
+//
+// A single RetryConcurrencyLimit should be shared by all operation stacks of a
+// client, so that the limit applies client-wide, preventing a thundering herd
+// of retries when many operations fail at once.
+type RetryConcurrencyLimit struct {
+	sem chan struct{}
+}
+
+// NewRetryConcurrencyLimit returns an initialized RetryConcurrencyLimit
+// middleware permitting up to limit concurrent retry attempts. Returns an
+// error if limit is not greater than zero.
+func NewRetryConcurrencyLimit(limit int) (*RetryConcurrencyLimit, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("retry concurrency limit must be greater than zero, got %v", limit)
+	}
+	return &RetryConcurrencyLimit{
+		sem: make(chan struct{}, limit),
+	}, nil
+}
+
+// AddRetryConcurrencyLimitMiddleware adds the RetryConcurrencyLimit
+// middleware to the Finalize step after the "Retry" middleware, if present,
+// otherwise to the end of the step. The "Retry" middleware must invoke the
+// next handler for each attempt for retry attempts to be limited.
+func AddRetryConcurrencyLimitMiddleware(stack *Stack, m *RetryConcurrencyLimit) error {
+	return InsertAfterRetry(stack, m)
+}
+
+// ID returns the identifier for the RetryConcurrencyLimit middleware.
+func (*RetryConcurrencyLimit) ID() string { return "RetryConcurrencyLimit" }
+
+// HandleFinalize waits for the retry concurrency limit to permit the attempt,
+// if the attempt is a retry.
+func (m *RetryConcurrencyLimit) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	if getAttemptNumber(ctx) <= 1 {
+		return next.HandleFinalize(ctx, in)
+	}
+
+	select {
+	case m.sem <- struct{}{}:
+	case <-ctx.Done():
+		return out, metadata, fmt.Errorf("failed waiting for retry concurrency limit, %w", ctx.Err())
+	}
+	defer func() { <-m.sem }()
+
+	return next.HandleFinalize(ctx, in)
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testAttemptKey struct{}
+
+func TestRetryConcurrencyLimit(t *testing.T) {
+	const (
+		limit      = 2
+		operations = 10
+		attempts   = 3
+	)
+
+	m, err := NewRetryConcurrencyLimit(limit)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	var mu sync.Mutex
+	var retrying, maxRetrying, firstAttempts, maxFirstAttempts int
+
+	newStack := func() *Stack {
+		stack := NewStack("stack", func() interface{} { return struct{}{} })
+
+		// mock retry middleware retrying until attempts are exhausted.
+		err := stack.Finalize.Add(FinalizeMiddlewareFunc("Retry",
+			func(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+				out FinalizeOutput, metadata Metadata, err error,
+			) {
+				for i := 0; i < attempts; i++ {
+					attemptCtx := context.WithValue(ctx, testAttemptKey{}, i)
+					if out, metadata, err = next.HandleFinalize(attemptCtx, in); err == nil {
+						break
+					}
+				}
+				return out, metadata, err
+			}), After)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if err := AddRetryConcurrencyLimitMiddleware(stack, m); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		return stack
+	}
+
+	track := func(counter, max *int, delta int) {
+		mu.Lock()
+		defer mu.Unlock()
+		*counter += delta
+		if *counter > *max {
+			*max = *counter
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < operations; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+				output interface{}, metadata Metadata, err error,
+			) {
+				if ctx.Value(testAttemptKey{}).(int) == 0 {
+					track(&firstAttempts, &maxFirstAttempts, 1)
+					defer track(&firstAttempts, &maxFirstAttempts, -1)
+				} else {
+					track(&retrying, &maxRetrying, 1)
+					defer track(&retrying, &maxRetrying, -1)
+				}
+				time.Sleep(10 * time.Millisecond)
+				return nil, metadata, fmt.Errorf("attempt failed")
+			}), newStack())
+
+			if _, _, err := handler.Handle(context.Background(), struct{}{}); err == nil {
+				t.Errorf("expect error, got none")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxRetrying > limit {
+		t.Errorf("expect at most %v concurrent retries, got %v", limit, maxRetrying)
+	}
+	if maxRetrying == 0 {
+		t.Errorf("expect retries to be attempted")
+	}
+	if maxFirstAttempts <= limit {
+		t.Errorf("expect first attempts not to be limited, got %v concurrent", maxFirstAttempts)
+	}
+}
+
+func TestRetryConcurrencyLimit_Canceled(t *testing.T) {
+	m, err := NewRetryConcurrencyLimit(1)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	m.sem <- struct{}{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ctx = WithStackValue(ctx, retryAttemptNumberKey{}, 2)
+
+	_, _, err = m.HandleFinalize(ctx, FinalizeInput{}, FinalizeHandlerFunc(func(ctx context.Context, in FinalizeInput) (
+		out FinalizeOutput, metadata Metadata, err error,
+	) {
+		t.Errorf("expect retry attempt not to be made")
+		return out, metadata, nil
+	}))
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+}
+
+func TestNewRetryConcurrencyLimit_Invalid(t *testing.T) {
+	if _, err := NewRetryConcurrencyLimit(0); err == nil {
+		t.Fatalf("expect error, got none")
+	}
+}
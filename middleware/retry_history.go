@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// AttemptRecord is the record of a single attempt of an operation's request.
+type AttemptRecord struct {
+	// Attempt is the attempt number, starting at 1.
+	Attempt int
+
+	// Err is the error returned by the attempt, or nil if the attempt
+	// succeeded.
+	Err error
+
+	// Delay is the duration between the end of the previous attempt, and
+	// the start of this attempt. Zero for the first attempt.
+	Delay time.Duration
+
+	// Duration is how long the attempt took.
+	Duration time.Duration
+
+	// StatusCode is the status code of the attempt's response, (e.g. the
+	// HTTP status code), or 0 if no response was received.
+	StatusCode int
+}
+
+type retryHistoryKey struct{}
+
+// GetRetryHistory returns the records of each attempt made by an operation.
+// Returns nil if the retry history was not recorded.
+func GetRetryHistory(metadata Metadata) []AttemptRecord {
+	v, _ := metadata.Get(retryHistoryKey{}).([]AttemptRecord)
+	return v
+}
+
+func setRetryHistory(metadata *Metadata, history []AttemptRecord) {
+	metadata.Set(retryHistoryKey{}, history)
+}
+
+// AddRetryHistoryMiddleware adds the middleware to record the history of an
+// operation's request attempts into the operation's metadata. The history is
+// retrieved with GetRetryHistory.
+//
+// The attempt recorder is added to the Finalize step after the "Retry"
+// middleware, if present, otherwise to the end of the step. The recorder must
+// be invoked by the retry middleware for each attempt for all attempts to be
+// recorded. The attempt's response status code is recorded from the raw
+// response in the Deserialize step, if the response provides one with a
+// HTTPStatusCode method, (e.g. smithy-go's transport/http Response).
+func AddRetryHistoryMiddleware(stack *Stack) error {
+	if err := stack.Initialize.Add(&retryHistoryInitialize{}, Before); err != nil {
+		return err
+	}
+	if err := stack.Deserialize.Add(&retryHistoryResponse{}, After); err != nil {
+		return err
+	}
+
+	return InsertAfterRetry(stack, &RetryHistory{})
+}
+
+// attemptHistory is the operation scoped collection of attempt records.
+type attemptHistory struct {
+	mu      sync.Mutex
+	records []AttemptRecord
+	lastEnd time.Time
+}
+
+func (h *attemptHistory) add(attempt int, start, end time.Time, statusCode int, err error) []AttemptRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if attempt == 0 {
+		attempt = len(h.records) + 1
+	}
+	record := AttemptRecord{
+		Attempt:    attempt,
+		Err:        err,
+		Duration:   end.Sub(start),
+		StatusCode: statusCode,
+	}
+	if !h.lastEnd.IsZero() {
+		record.Delay = start.Sub(h.lastEnd)
+	}
+	h.lastEnd = end
+	h.records = append(h.records, record)
+
+	return h.snapshot()
+}
+
+func (h *attemptHistory) snapshot() []AttemptRecord {
+	return append([]AttemptRecord(nil), h.records...)
+}
+
+type attemptHistoryKey struct{}
+
+// retryHistoryInitialize seeds the operation's attempt history, and sets the
+// final history in the operation's metadata.
+type retryHistoryInitialize struct{}
+
+func (*retryHistoryInitialize) ID() string { return "RetryHistoryInitialize" }
+
+func (*retryHistoryInitialize) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	history := &attemptHistory{}
+	ctx = WithStackValue(ctx, attemptHistoryKey{}, history)
+
+	out, metadata, err = next.HandleInitialize(ctx, in)
+
+	history.mu.Lock()
+	setRetryHistory(&metadata, history.snapshot())
+	history.mu.Unlock()
+
+	return out, metadata, err
+}
+
+// RetryHistory provides a finalize middleware that records each attempt of an
+// operation's request.
+type RetryHistory struct {
+	now func() time.Time
+}
+
+// ID returns the identifier for the RetryHistory middleware.
+func (*RetryHistory) ID() string { return "RetryHistory" }
+
+// HandleFinalize records the attempt's error, duration, and response status
+// code.
+func (m *RetryHistory) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	history, ok := GetStackValue(ctx, attemptHistoryKey{}).(*attemptHistory)
+	if !ok {
+		return next.HandleFinalize(ctx, in)
+	}
+
+	now := m.now
+	if now == nil {
+		now = time.Now
+	}
+
+	status := &attemptStatus{}
+	ctx = WithStackValue(ctx, attemptStatusKey{}, status)
+
+	start := now()
+	out, metadata, err = next.HandleFinalize(ctx, in)
+
+	statusCode := status.code
+	var statusErr interface{ HTTPStatusCode() int }
+	if statusCode == 0 && errors.As(err, &statusErr) {
+		statusCode = statusErr.HTTPStatusCode()
+	}
+	setRetryHistory(&metadata, history.add(getAttemptNumber(ctx), start, now(), statusCode, err))
+
+	return out, metadata, err
+}
+
+// attemptStatus is the attempt scoped status code of the attempt's response.
+type attemptStatus struct {
+	code int
+}
+
+type attemptStatusKey struct{}
+
+// retryHistoryResponse records the status code of the attempt's raw response.
+type retryHistoryResponse struct{}
+
+func (*retryHistoryResponse) ID() string { return "RetryHistoryResponse" }
+
+func (*retryHistoryResponse) HandleDeserialize(ctx context.Context, in DeserializeInput, next DeserializeHandler) (
+	out DeserializeOutput, metadata Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+
+	status, ok := GetStackValue(ctx, attemptStatusKey{}).(*attemptStatus)
+	if !ok {
+		return out, metadata, err
+	}
+	if resp, ok := out.RawResponse.(interface{ HTTPStatusCode() int }); ok {
+		status.code = resp.HTTPStatusCode()
+	}
+
+	return out, metadata, err
+}
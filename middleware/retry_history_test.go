@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRetryHistory(t *testing.T) {
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+
+	clock := time.Unix(0, 0)
+	tick := func(d time.Duration) { clock = clock.Add(d) }
+
+	// mock retry middleware invoking the next handler until it succeeds.
+	err := stack.Finalize.Add(FinalizeMiddlewareFunc("Retry",
+		func(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+			out FinalizeOutput, metadata Metadata, err error,
+		) {
+			for i := 0; i < 5; i++ {
+				if i > 0 {
+					tick(time.Duration(i) * time.Second)
+				}
+				out, metadata, err = next.HandleFinalize(ctx, in)
+				if err == nil {
+					break
+				}
+			}
+			return out, metadata, err
+		}), After)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if err := AddRetryHistoryMiddleware(stack); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	m, _ := stack.Finalize.Get("RetryHistory")
+	m.(*RetryHistory).now = func() time.Time { return clock }
+
+	errs := []error{fmt.Errorf("first"), fmt.Errorf("second"), nil}
+	statusCodes := []int{500, 503, 200}
+	var attempt int
+	handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+		output interface{}, metadata Metadata, err error,
+	) {
+		output = mockStatusResponse(statusCodes[attempt])
+		err = errs[attempt]
+		attempt++
+		tick(100 * time.Millisecond)
+		return output, metadata, err
+	}), stack)
+
+	_, metadata, err := handler.Handle(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	history := GetRetryHistory(metadata)
+	if e, a := 3, len(history); e != a {
+		t.Fatalf("expect %v attempt records, got %v", e, a)
+	}
+
+	expectDelays := []time.Duration{0, time.Second, 2 * time.Second}
+	for i, record := range history {
+		if e, a := i+1, record.Attempt; e != a {
+			t.Errorf("expect attempt %v, got %v", e, a)
+		}
+		if e, a := errs[i], record.Err; e != a {
+			t.Errorf("expect attempt %d error %v, got %v", i+1, e, a)
+		}
+		if e, a := 100*time.Millisecond, record.Duration; e != a {
+			t.Errorf("expect attempt %d duration %v, got %v", i+1, e, a)
+		}
+		if e, a := expectDelays[i], record.Delay; e != a {
+			t.Errorf("expect attempt %d delay %v, got %v", i+1, e, a)
+		}
+		if e, a := statusCodes[i], record.StatusCode; e != a {
+			t.Errorf("expect attempt %d status code %v, got %v", i+1, e, a)
+		}
+	}
+}
+
+func TestRetryHistory_ErrorStatusCode(t *testing.T) {
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+	if err := AddRetryHistoryMiddleware(stack); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+		output interface{}, metadata Metadata, err error,
+	) {
+		return nil, metadata, &mockStatusError{statusCode: 404}
+	}), stack)
+
+	_, metadata, _ := handler.Handle(context.Background(), struct{}{})
+
+	history := GetRetryHistory(metadata)
+	if e, a := 1, len(history); e != a {
+		t.Fatalf("expect %v attempt records, got %v", e, a)
+	}
+	if e, a := 404, history[0].StatusCode; e != a {
+		t.Errorf("expect status code %v, got %v", e, a)
+	}
+}
+
+type mockStatusResponse int
+
+func (r mockStatusResponse) HTTPStatusCode() int { return int(r) }
+
+type mockStatusError struct {
+	statusCode int
+}
+
+func (e *mockStatusError) Error() string       { return fmt.Sprintf("status %d", e.statusCode) }
+func (e *mockStatusError) HTTPStatusCode() int { return e.statusCode }
+
+func TestRetryHistory_Error(t *testing.T) {
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+	if err := AddRetryHistoryMiddleware(stack); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+		output interface{}, metadata Metadata, err error,
+	) {
+		return nil, metadata, fmt.Errorf("failed")
+	}), stack)
+
+	_, metadata, err := handler.Handle(context.Background(), struct{}{})
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+
+	history := GetRetryHistory(metadata)
+	if e, a := 1, len(history); e != a {
+		t.Fatalf("expect %v attempt records, got %v", e, a)
+	}
+	if history[0].Err == nil {
+		t.Errorf("expect attempt error to be recorded")
+	}
+	if e, a := 0, history[0].StatusCode; e != a {
+		t.Errorf("expect status code %v without response, got %v", e, a)
+	}
+}
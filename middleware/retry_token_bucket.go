@@ -0,0 +1,233 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// RetryTokenBucket provides the interface for a bucket of retry tokens that
+// may be shared between the operations of one or more clients. Tokens are
+// taken from the bucket for each retry attempt, and added back when requests
+// succeed. Implementations must be safe for concurrent use.
+type RetryTokenBucket interface {
+	// GetToken attempts to take cost tokens from the bucket. Returns a
+	// function that will return the tokens to the bucket, or an error if the
+	// bucket does not have enough tokens.
+	GetToken(cost uint) (release func() error, err error)
+
+	// AddTokens adds tokens to the bucket, up to its capacity.
+	AddTokens(v uint) error
+}
+
+// RetryTokensExhaustedError is returned by the RetryTokens middleware when a
+// retry attempt is not permitted because the retry token bucket does not have
+// enough tokens. The error is not retryable.
+type RetryTokensExhaustedError struct {
+	// Err is the error of the previous attempt, that would have been retried.
+	Err error
+}
+
+// Error returns the error message.
+func (e *RetryTokensExhaustedError) Error() string {
+	return fmt.Sprintf("retry token bucket exhausted, %v", e.Err)
+}
+
+// Unwrap returns the error of the previous attempt.
+func (e *RetryTokensExhaustedError) Unwrap() error { return e.Err }
+
+// RetryableError returns false, the attempt must not be retried.
+func (e *RetryTokensExhaustedError) RetryableError() bool { return false }
+
+// FixedRetryTokenBucket provides a RetryTokenBucket with a fixed capacity of
+// tokens that is initially full.
+type FixedRetryTokenBucket struct {
+	mu        sync.Mutex
+	capacity  uint
+	remaining uint
+}
+
+// NewFixedRetryTokenBucket returns an initialized FixedRetryTokenBucket full
+// with capacity tokens.
+func NewFixedRetryTokenBucket(capacity uint) *FixedRetryTokenBucket {
+	return &FixedRetryTokenBucket{
+		capacity:  capacity,
+		remaining: capacity,
+	}
+}
+
+// GetToken takes cost tokens from the bucket, returning a function that will
+// return the tokens to the bucket. Returns an error if the bucket does not have
+// enough tokens.
+func (b *FixedRetryTokenBucket) GetToken(cost uint) (func() error, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if cost > b.remaining {
+		return nil, fmt.Errorf("retry token bucket has %d tokens, need %d", b.remaining, cost)
+	}
+	b.remaining -= cost
+
+	return func() error {
+		return b.AddTokens(cost)
+	}, nil
+}
+
+// AddTokens adds tokens to the bucket, up to its capacity.
+func (b *FixedRetryTokenBucket) AddTokens(v uint) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if v >= b.capacity-b.remaining {
+		b.remaining = b.capacity
+	} else {
+		b.remaining += v
+	}
+	return nil
+}
+
+// Remaining returns the number of tokens remaining in the bucket.
+func (b *FixedRetryTokenBucket) Remaining() uint {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.remaining
+}
+
+// Defaults for the RetryTokens middleware.
+const (
+	DefaultRetryTokenCost        uint = 5
+	DefaultRetryTimeoutTokenCost uint = 10
+	DefaultRetryNoRetryIncrement uint = 1
+)
+
+// RetryTokens provides a finalize middleware that takes tokens from a
+// RetryTokenBucket for each retry attempt of an operation, and refills the
+// bucket when attempts succeed. When the bucket does not have enough tokens
+// the retry attempt is not sent, and a non-retryable
+// RetryTokensExhaustedError is returned, suppressing further retries until
+// the bucket is refilled.
+//
+// Successful first attempts add NoRetryIncrement tokens to the bucket.
+// Successful retry attempts return the tokens the retry took.
+type RetryTokens struct {
+	// Bucket is the retry token bucket, which may be shared between clients.
+	Bucket RetryTokenBucket
+
+	// RetryCost is the number of tokens taken for a retry attempt. Defaults
+	// to DefaultRetryTokenCost if zero.
+	RetryCost uint
+
+	// TimeoutRetryCost is the number of tokens taken for a retry attempt when
+	// the previous attempt failed with a timeout error. Defaults to
+	// DefaultRetryTimeoutTokenCost if zero.
+	TimeoutRetryCost uint
+
+	// NoRetryIncrement is the number of tokens added to the bucket when the
+	// first attempt succeeds. Defaults to DefaultRetryNoRetryIncrement if
+	// zero.
+	NoRetryIncrement uint
+}
+
+// AddRetryTokensMiddleware adds the RetryTokens middleware to the stack's
+// Finalize step after the "Retry" middleware, if present, otherwise to the end
+// of the step. The middleware must be invoked by the retry middleware for each
+// attempt.
+func AddRetryTokensMiddleware(stack *Stack, m *RetryTokens) error {
+	if err := stack.Initialize.Add(&retryTokensInitialize{}, Before); err != nil {
+		return err
+	}
+
+	return InsertAfterRetry(stack, m)
+}
+
+// ID returns the identifier for the RetryTokens middleware.
+func (*RetryTokens) ID() string { return "RetryTokens" }
+
+// HandleFinalize takes tokens from the bucket if the attempt is a retry, and
+// refills the bucket if the attempt succeeds.
+func (m *RetryTokens) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	state, ok := GetStackValue(ctx, retryTokensStateKey{}).(*retryTokensState)
+	if !ok {
+		return next.HandleFinalize(ctx, in)
+	}
+
+	var release func() error
+	if getAttemptNumber(ctx) > 1 {
+		cost := m.retryCost()
+		if isTimeoutError(state.lastErr) {
+			cost = m.timeoutRetryCost()
+		}
+
+		release, err = m.Bucket.GetToken(cost)
+		if err != nil {
+			return out, metadata, &RetryTokensExhaustedError{Err: state.lastErr}
+		}
+	}
+
+	out, metadata, err = next.HandleFinalize(ctx, in)
+	state.lastErr = err
+	if err != nil {
+		return out, metadata, err
+	}
+
+	if release != nil {
+		if rErr := release(); rErr != nil {
+			return out, metadata, fmt.Errorf("failed to release retry token, %w", rErr)
+		}
+	} else if rErr := m.Bucket.AddTokens(m.noRetryIncrement()); rErr != nil {
+		return out, metadata, fmt.Errorf("failed to add retry tokens, %w", rErr)
+	}
+
+	return out, metadata, nil
+}
+
+func (m *RetryTokens) retryCost() uint {
+	if m.RetryCost == 0 {
+		return DefaultRetryTokenCost
+	}
+	return m.RetryCost
+}
+
+func (m *RetryTokens) timeoutRetryCost() uint {
+	if m.TimeoutRetryCost == 0 {
+		return DefaultRetryTimeoutTokenCost
+	}
+	return m.TimeoutRetryCost
+}
+
+func (m *RetryTokens) noRetryIncrement() uint {
+	if m.NoRetryIncrement == 0 {
+		return DefaultRetryNoRetryIncrement
+	}
+	return m.NoRetryIncrement
+}
+
+func isTimeoutError(err error) bool {
+	var v interface{ Timeout() bool }
+	return errors.As(err, &v) && v.Timeout()
+}
+
+type retryTokensStateKey struct{}
+
+// retryTokensState is the operation scoped record of the previous attempt's
+// error.
+type retryTokensState struct {
+	lastErr error
+}
+
+// retryTokensInitialize seeds the operation scoped state of the RetryTokens
+// middleware.
+type retryTokensInitialize struct{}
+
+func (*retryTokensInitialize) ID() string { return "RetryTokensInitialize" }
+
+func (*retryTokensInitialize) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	ctx = WithStackValue(ctx, retryTokensStateKey{}, &retryTokensState{})
+	return next.HandleInitialize(ctx, in)
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type mockRetryableError struct{ Retryable bool }
+
+func (e *mockRetryableError) Error() string        { return "mock retryable error" }
+func (e *mockRetryableError) RetryableError() bool { return e.Retryable }
+
+func TestRetryTokens(t *testing.T) {
+	bucket := NewFixedRetryTokenBucket(10)
+
+	const maxAttempts = 3
+	var results []error
+	var attempts int
+
+	newHandler := func() Handler {
+		stack := NewStack("stack", func() interface{} { return struct{}{} })
+
+		// mock retry middleware retrying retryable errors.
+		err := stack.Finalize.Add(FinalizeMiddlewareFunc("Retry",
+			func(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+				out FinalizeOutput, metadata Metadata, err error,
+			) {
+				for i := 0; i < maxAttempts; i++ {
+					out, metadata, err = next.HandleFinalize(ctx, in)
+					var v interface{ RetryableError() bool }
+					if err == nil || !errors.As(err, &v) || !v.RetryableError() {
+						break
+					}
+				}
+				return out, metadata, err
+			}), After)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if err := AddRetryTokensMiddleware(stack, &RetryTokens{Bucket: bucket}); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+
+		return DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+			output interface{}, metadata Metadata, err error,
+		) {
+			attempts++
+			err, results = results[0], results[1:]
+			return nil, metadata, err
+		}), stack)
+	}
+
+	invoke := func(errs ...error) (int, error) {
+		results = errs
+		attempts = 0
+		_, _, err := newHandler().Handle(context.Background(), struct{}{})
+		return attempts, err
+	}
+
+	retryable := &mockRetryableError{Retryable: true}
+
+	// Failures with retries deplete the bucket.
+	n, err := invoke(retryable, retryable, retryable)
+	if e, a := maxAttempts, n; e != a {
+		t.Errorf("expect %v attempts, got %v", e, a)
+	}
+	if e, a := error(retryable), err; e != a {
+		t.Errorf("expect %v error, got %v", e, a)
+	}
+	if e, a := uint(0), bucket.Remaining(); e != a {
+		t.Fatalf("expect %v tokens remaining, got %v", e, a)
+	}
+
+	// Retries are suppressed while the bucket is depleted.
+	n, err = invoke(retryable, nil)
+	if e, a := 1, n; e != a {
+		t.Errorf("expect %v attempts, got %v", e, a)
+	}
+	var exhaustedErr *RetryTokensExhaustedError
+	if !errors.As(err, &exhaustedErr) {
+		t.Fatalf("expect %T error, got %v", exhaustedErr, err)
+	}
+	if e, a := error(retryable), exhaustedErr.Unwrap(); e != a {
+		t.Errorf("expect %v wrapped error, got %v", e, a)
+	}
+
+	// Successful first attempts refill the bucket.
+	for i := 0; i < int(DefaultRetryTokenCost); i++ {
+		if _, err := invoke(nil); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+	}
+	if e, a := DefaultRetryTokenCost, bucket.Remaining(); e != a {
+		t.Fatalf("expect %v tokens remaining, got %v", e, a)
+	}
+
+	// Retry is permitted once refilled, and successful retry returns its
+	// tokens.
+	n, err = invoke(retryable, nil)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := 2, n; e != a {
+		t.Errorf("expect %v attempts, got %v", e, a)
+	}
+	if e, a := DefaultRetryTokenCost, bucket.Remaining(); e != a {
+		t.Errorf("expect %v tokens remaining, got %v", e, a)
+	}
+}
+
+func TestFixedRetryTokenBucket(t *testing.T) {
+	bucket := NewFixedRetryTokenBucket(10)
+
+	release, err := bucket.GetToken(4)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := uint(6), bucket.Remaining(); e != a {
+		t.Errorf("expect %v tokens remaining, got %v", e, a)
+	}
+
+	if _, err := bucket.GetToken(7); err == nil {
+		t.Errorf("expect error, got none")
+	}
+
+	if err := release(); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if err := bucket.AddTokens(5); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := uint(10), bucket.Remaining(); e != a {
+		t.Errorf("expect bucket capacity %v tokens, got %v", e, a)
+	}
+}
+
+func TestRetryTokens_TimeoutCost(t *testing.T) {
+	bucket := NewFixedRetryTokenBucket(20)
+	m := &RetryTokens{Bucket: bucket}
+
+	ctx := WithStackValue(context.Background(), retryTokensStateKey{}, &retryTokensState{})
+	next := FinalizeHandlerFunc(func(ctx context.Context, in FinalizeInput) (
+		out FinalizeOutput, metadata Metadata, err error,
+	) {
+		return out, metadata, &timeoutError{}
+	})
+
+	for i := 0; i < 2; i++ {
+		ctx := WithStackValue(ctx, retryAttemptNumberKey{}, i+1)
+		if _, _, err := m.HandleFinalize(ctx, FinalizeInput{}, next); err == nil {
+			t.Fatalf("expect error, got none")
+		}
+	}
+	if e, a := 20-DefaultRetryTimeoutTokenCost, bucket.Remaining(); e != a {
+		t.Errorf("expect %v tokens remaining, got %v", e, a)
+	}
+}
+
+type timeoutError struct{}
+
+func (*timeoutError) Error() string { return "timeout" }
+func (*timeoutError) Timeout() bool { return true }
@@ -1,6 +1,15 @@
 package middleware
 
-import "context"
+import (
+	"context"
+	"errors"
+
+	smithy "github.com/aws/smithy-go"
+)
+
+// operationSerializerID is the ID of the operation's serializer middleware,
+// whose errors the SerializeStep wraps in a smithy.SerializationError.
+const operationSerializerID = "OperationSerializer"
 
 // SerializeInput provides the input parameters for the SerializeMiddleware to
 // consume. SerializeMiddleware may modify the Request value before forwarding
@@ -74,6 +83,10 @@ var _ SerializeMiddleware = (serializeMiddlewareFunc{})
 
 // SerializeStep provides the ordered grouping of SerializeMiddleware to be
 // invoked on a handler.
+//
+// Errors returned by the operation's serializer, the "OperationSerializer"
+// middleware, before it calls the next handler are wrapped in a
+// smithy.SerializationError carrying the operation's name.
 type SerializeStep struct {
 	newRequest func() interface{}
 	ids        *orderedIDs
@@ -205,7 +218,44 @@ var _ SerializeHandler = (*decoratedSerializeHandler)(nil)
 func (h decoratedSerializeHandler) HandleSerialize(ctx context.Context, in SerializeInput) (
 	out SerializeOutput, metadata Metadata, err error,
 ) {
-	return h.With.HandleSerialize(ctx, in, h.Next)
+	if h.With.ID() != operationSerializerID {
+		return h.With.HandleSerialize(ctx, in, h.Next)
+	}
+
+	next := &serializeCalledHandler{Next: h.Next}
+	out, metadata, err = h.With.HandleSerialize(ctx, in, next)
+	if err != nil && !next.called {
+		// The serializer failed before passing the request on, and the error
+		// is a failure to serialize the request.
+		err = wrapSerializationError(GetOperationName(ctx), err)
+	}
+	return out, metadata, err
+}
+
+// wrapSerializationError wraps the error in a smithy.SerializationError, if
+// the error is not already a serialization error, or the Context's error.
+func wrapSerializationError(operation string, err error) error {
+	var serializationErr *smithy.SerializationError
+	switch {
+	case errors.As(err, &serializationErr),
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded):
+		return err
+	}
+	return &smithy.SerializationError{OperationName: operation, Err: err}
+}
+
+// serializeCalledHandler records if the next handler was called.
+type serializeCalledHandler struct {
+	Next   SerializeHandler
+	called bool
+}
+
+func (h *serializeCalledHandler) HandleSerialize(ctx context.Context, in SerializeInput) (
+	out SerializeOutput, metadata Metadata, err error,
+) {
+	h.called = true
+	return h.Next.HandleSerialize(ctx, in)
 }
 
 // SerializeHandlerFunc provides a wrapper around a function to be used as a serialize middleware handler.
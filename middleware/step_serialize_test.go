@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	smithy "github.com/aws/smithy-go"
+)
+
+func TestSerializeStep_SerializationError(t *testing.T) {
+	endpointErr := fmt.Errorf("endpoint resolution failed")
+
+	cases := map[string]struct {
+		SerializeErr       error
+		ResolveErr         error
+		BuildErr           error
+		ExpectSerializeErr bool
+		ExpectMessage      string
+	}{
+		"serializer failure": {
+			SerializeErr:       fmt.Errorf("encoding failed"),
+			ExpectSerializeErr: true,
+			ExpectMessage:      "operation GetThing serialization failed: encoding failed",
+		},
+		"typed serializer failure": {
+			SerializeErr:       &smithy.SerializationError{OperationName: "PutThing", Err: fmt.Errorf("encoding failed")},
+			ExpectSerializeErr: true,
+			ExpectMessage:      "operation PutThing serialization failed: encoding failed",
+		},
+		"serializer canceled": {
+			SerializeErr:  context.Canceled,
+			ExpectMessage: "context canceled",
+		},
+		"other serialize middleware failure": {
+			ResolveErr:    endpointErr,
+			ExpectMessage: "endpoint resolution failed",
+		},
+		"context canceled": {
+			ResolveErr:    context.Canceled,
+			ExpectMessage: "context canceled",
+		},
+		"downstream failure": {
+			BuildErr:      fmt.Errorf("build failed"),
+			ExpectMessage: "build failed",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := NewStack("stack", func() interface{} { return struct{}{} })
+			stack.Serialize.Add(SerializeMiddlewareFunc("ResolveEndpoint",
+				func(ctx context.Context, in SerializeInput, next SerializeHandler) (
+					out SerializeOutput, metadata Metadata, err error,
+				) {
+					if c.ResolveErr != nil {
+						return out, metadata, c.ResolveErr
+					}
+					return next.HandleSerialize(ctx, in)
+				}), After)
+			stack.Serialize.Add(SerializeMiddlewareFunc("OperationSerializer",
+				func(ctx context.Context, in SerializeInput, next SerializeHandler) (
+					out SerializeOutput, metadata Metadata, err error,
+				) {
+					if c.SerializeErr != nil {
+						return out, metadata, c.SerializeErr
+					}
+					return next.HandleSerialize(ctx, in)
+				}), After)
+			stack.Build.Add(BuildMiddlewareFunc("Build",
+				func(ctx context.Context, in BuildInput, next BuildHandler) (
+					out BuildOutput, metadata Metadata, err error,
+				) {
+					return out, metadata, c.BuildErr
+				}), After)
+
+			handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+				output interface{}, metadata Metadata, err error,
+			) {
+				return nil, metadata, nil
+			}), stack)
+
+			ctx := WithOperationName(context.Background(), "GetThing")
+			_, _, err := handler.Handle(ctx, struct{}{})
+			if err == nil {
+				t.Fatalf("expect error, got none")
+			}
+
+			var serializationErr *smithy.SerializationError
+			if e, a := c.ExpectSerializeErr, errors.As(err, &serializationErr); e != a {
+				t.Errorf("expect serialization error %v, got %v, %v", e, a, err)
+			}
+			if e, a := c.ExpectMessage, err.Error(); e != a {
+				t.Errorf("expect %q error message, got %q", e, a)
+			}
+		})
+	}
+}
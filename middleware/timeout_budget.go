@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TimeoutBudgetExceededError is the error returned by the TimeoutBudget
+// middleware when an attempt is not sent because the operation's timeout
+// budget is exhausted. Err is the Context's error.
+type TimeoutBudgetExceededError struct {
+	Budget time.Duration
+	Err    error
+}
+
+// Error returns the error message.
+func (e *TimeoutBudgetExceededError) Error() string {
+	return fmt.Sprintf("operation timeout budget of %v exhausted, %v", e.Budget, e.Err)
+}
+
+// Unwrap returns the Context's error.
+func (e *TimeoutBudgetExceededError) Unwrap() error { return e.Err }
+
+// RetryableError returns false, the attempt must not be retried.
+func (e *TimeoutBudgetExceededError) RetryableError() bool { return false }
+
+// AddTimeoutBudgetMiddleware adds the TimeoutBudget middleware to the front of
+// the stack's Initialize step, and the budget check to the stack's Finalize
+// step after the "Retry" middleware, if present, otherwise to the end of the
+// step. Returns an error if the budget is not greater than zero.
+func AddTimeoutBudgetMiddleware(stack *Stack, budget time.Duration) error {
+	if budget <= 0 {
+		return fmt.Errorf("timeout budget must be greater than zero, got %v", budget)
+	}
+
+	if err := stack.Initialize.Add(&TimeoutBudget{Budget: budget}, Before); err != nil {
+		return err
+	}
+
+	check := &timeoutBudgetCheck{budget: budget}
+	return InsertAfterRetry(stack, check)
+}
+
+// TimeoutBudget provides an initialize middleware that bounds the total wall
+// time of an operation, including all of its attempts, and any operations it
+// invokes with its Context. The budget is applied as a deadline of the
+// operation's Context. If the Context already has an earlier deadline, that
+// deadline is kept.
+//
+// When added with AddTimeoutBudgetMiddleware, attempts started after the
+// budget is exhausted fail fast with a TimeoutBudgetExceededError, wrapped in
+// a smithy.TimeoutError, without the request being sent.
+//
+// If the operation's output has a streaming body, the budget's Context is not
+// canceled until the body is closed, so the body can be read after the
+// operation returns, within the budget.
+type TimeoutBudget struct {
+	// Budget is the total duration the operation may take.
+	Budget time.Duration
+}
+
+// ID returns the identifier for the TimeoutBudget middleware.
+func (*TimeoutBudget) ID() string { return "TimeoutBudget" }
+
+// HandleInitialize sets the deadline of the operation's Context to the
+// timeout budget.
+func (m *TimeoutBudget) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, m.Budget)
+	defer func() {
+		if err != nil || !cancelOnOutputBodyClose(out.Result, cancel) {
+			cancel()
+		}
+	}()
+
+	ctx = WithStackValue(ctx, timeoutBudgetKey{}, &timeoutBudgetContexts{
+		budget: ctx,
+		parent: parent,
+	})
+	return next.HandleInitialize(ctx, in)
+}
+
+type timeoutBudgetKey struct{}
+
+// timeoutBudgetContexts are the operation's Context with the timeout budget
+// applied, and the Context the budget was applied to.
+type timeoutBudgetContexts struct {
+	budget context.Context
+	parent context.Context
+}
+
+// exceeded returns if the budget's deadline was exceeded, instead of the
+// parent Context being canceled, or its own deadline being exceeded.
+func (c *timeoutBudgetContexts) exceeded() bool {
+	return c.budget.Err() == context.DeadlineExceeded && c.parent.Err() == nil
+}
+
+// timeoutBudgetCheck provides a finalize middleware that fails an attempt
+// without sending it if the operation's timeout budget is exhausted, or its
+// Context is otherwise done.
+type timeoutBudgetCheck struct {
+	budget time.Duration
+}
+
+func (*timeoutBudgetCheck) ID() string { return "TimeoutBudgetCheck" }
+
+func (m *timeoutBudgetCheck) HandleFinalize(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+	out FinalizeOutput, metadata Metadata, err error,
+) {
+	if err := ctx.Err(); err != nil {
+		if budget, ok := GetStackValue(ctx, timeoutBudgetKey{}).(*timeoutBudgetContexts); ok && budget.exceeded() {
+			err = &TimeoutBudgetExceededError{Budget: m.budget, Err: err}
+		}
+		return out, metadata, translateContextError(GetOperationName(ctx), err)
+	}
+
+	return next.HandleFinalize(ctx, in)
+}
@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	smithy "github.com/aws/smithy-go"
+)
+
+func TestTimeoutBudget(t *testing.T) {
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+
+	// mock retry middleware retrying until an error is not retryable.
+	err := stack.Finalize.Add(FinalizeMiddlewareFunc("Retry",
+		func(ctx context.Context, in FinalizeInput, next FinalizeHandler) (
+			out FinalizeOutput, metadata Metadata, err error,
+		) {
+			for i := 0; i < 3; i++ {
+				out, metadata, err = next.HandleFinalize(ctx, in)
+				var v interface{ RetryableError() bool }
+				if err == nil || (errors.As(err, &v) && !v.RetryableError()) {
+					break
+				}
+			}
+			return out, metadata, err
+		}), After)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	const budget = 50 * time.Millisecond
+	if err := AddTimeoutBudgetMiddleware(stack, budget); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	var attempts int
+	start := time.Now()
+	handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+		output interface{}, metadata Metadata, err error,
+	) {
+		attempts++
+
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			t.Fatalf("expect deadline to be set")
+		}
+		if deadline.Before(start.Add(budget)) || deadline.After(time.Now().Add(budget)) {
+			t.Errorf("expect deadline at budget, got %v", deadline.Sub(start))
+		}
+
+		// The attempt takes longer than the budget.
+		<-ctx.Done()
+		return nil, metadata, ctx.Err()
+	}), stack)
+
+	ctx := WithOperationName(context.Background(), "GetThing")
+	_, _, err = handler.Handle(ctx, struct{}{})
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+
+	if e, a := 1, attempts; e != a {
+		t.Errorf("expect %v attempts, got %v", e, a)
+	}
+
+	var timeoutErr *smithy.TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("expect %T error, got %v", timeoutErr, err)
+	}
+	if e, a := "GetThing", timeoutErr.OperationName; e != a {
+		t.Errorf("expect %v operation name, got %v", e, a)
+	}
+
+	var budgetErr *TimeoutBudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("expect %T error, got %v", budgetErr, err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect error to be deadline exceeded, got %v", err)
+	}
+}
+
+func TestTimeoutBudget_EarlierDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	expect, _ := ctx.Deadline()
+
+	m := &TimeoutBudget{Budget: time.Hour}
+	_, _, err := m.HandleInitialize(ctx, InitializeInput{}, InitializeHandlerFunc(
+		func(ctx context.Context, in InitializeInput) (out InitializeOutput, metadata Metadata, err error) {
+			if actual, _ := ctx.Deadline(); !expect.Equal(actual) {
+				t.Errorf("expect %v deadline, got %v", expect, actual)
+			}
+			return out, metadata, nil
+		}))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+}
+
+func TestTimeoutBudget_CallerCanceled(t *testing.T) {
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+	if err := AddTimeoutBudgetMiddleware(stack, time.Minute); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	var attempts int
+	handler := DecorateHandler(HandlerFunc(func(ctx context.Context, input interface{}) (
+		output interface{}, metadata Metadata, err error,
+	) {
+		attempts++
+		return nil, metadata, nil
+	}), stack)
+
+	ctx, cancel := context.WithCancel(WithOperationName(context.Background(), "GetThing"))
+	cancel()
+
+	_, _, err := handler.Handle(ctx, struct{}{})
+	if e, a := 0, attempts; e != a {
+		t.Errorf("expect %v attempts, got %v", e, a)
+	}
+
+	var canceledErr *smithy.CanceledError
+	if !errors.As(err, &canceledErr) {
+		t.Fatalf("expect %T error, got %v", canceledErr, err)
+	}
+	var budgetErr *TimeoutBudgetExceededError
+	if errors.As(err, &budgetErr) {
+		t.Errorf("expect error not to be %T, got %v", budgetErr, err)
+	}
+}
+
+func TestTimeoutBudget_StreamingOutput(t *testing.T) {
+	var body *contextBody
+	m := &TimeoutBudget{Budget: time.Minute}
+	out, _, err := m.HandleInitialize(context.Background(), InitializeInput{}, InitializeHandlerFunc(
+		func(ctx context.Context, in InitializeInput) (out InitializeOutput, metadata Metadata, err error) {
+			body = &contextBody{ctx: ctx}
+			out.Result = &mockStreamingOutput{Body: body}
+			return out, metadata, nil
+		}))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	output := out.Result.(*mockStreamingOutput)
+	if _, err := ioutil.ReadAll(output.Body); err != nil {
+		t.Fatalf("expect body to be readable after return, got %v", err)
+	}
+	if err := output.Body.Close(); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if !body.closed {
+		t.Errorf("expect body to be closed")
+	}
+	if e, a := context.Canceled, body.ctx.Err(); e != a {
+		t.Errorf("expect %v once body closed, got %v", e, a)
+	}
+}
+
+func TestTimeoutBudget_NonStreamingOutput(t *testing.T) {
+	var opCtx context.Context
+	m := &TimeoutBudget{Budget: time.Minute}
+	_, _, err := m.HandleInitialize(context.Background(), InitializeInput{}, InitializeHandlerFunc(
+		func(ctx context.Context, in InitializeInput) (out InitializeOutput, metadata Metadata, err error) {
+			opCtx = ctx
+			out.Result = &mockStreamingOutput{}
+			return out, metadata, nil
+		}))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if e, a := context.Canceled, opCtx.Err(); e != a {
+		t.Errorf("expect %v once operation returns, got %v", e, a)
+	}
+}
+
+func TestAddTimeoutBudgetMiddleware_Invalid(t *testing.T) {
+	stack := NewStack("stack", func() interface{} { return struct{}{} })
+	if err := AddTimeoutBudgetMiddleware(stack, 0); err == nil {
+		t.Errorf("expect error, got none")
+	}
+}
+
+type mockStreamingOutput struct {
+	Name string
+	Body io.ReadCloser
+}
+
+// contextBody is a streaming body that fails to be read once the Context it
+// is read under is done.
+type contextBody struct {
+	ctx    context.Context
+	closed bool
+}
+
+func (b *contextBody) Read(p []byte) (int, error) {
+	if err := b.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return 0, io.EOF
+}
+
+func (b *contextBody) Close() error {
+	b.closed = true
+	return nil
+}
@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	smithycontext "github.com/aws/smithy-go/context"
+)
+
+// BatchResult is the result of a single operation of a batch flushed by a
+// WriteBatcher.
+type BatchResult struct {
+	Output interface{}
+	Err    error
+}
+
+// WriteBatcher flushes a batch of buffered write operations, (e.g. as a single
+// batch write request). Returns a result for each of the inputs, in the same
+// order, or an error if the batch failed as a whole.
+type WriteBatcher func(ctx context.Context, inputs []interface{}) ([]BatchResult, error)
+
+// WriteCoalescingOptions provides the options for the WriteCoalescing
+// middleware.
+type WriteCoalescingOptions struct {
+	// Batcher flushes the buffered operations. Required.
+	Batcher WriteBatcher
+
+	// Qualifies returns if the operation's input may be buffered and flushed
+	// as part of a batch. Operations that do not qualify are invoked
+	// immediately. Required.
+	Qualifies func(input interface{}) bool
+
+	// Window is how long the first operation of a batch is buffered before the
+	// batch is flushed. Must be greater than zero.
+	Window time.Duration
+
+	// MaxBatchSize is the number of buffered operations that causes the batch
+	// to be flushed before the end of the window. Zero means no maximum.
+	MaxBatchSize int
+}
+
+// WriteCoalescing provides an initialize middleware that buffers qualifying
+// write operations for a short window, flushing them as a single batch with
+// the Batcher. A batch is flushed when the window of the batch's first
+// operation ends, or when the batch reaches its maximum size, whichever is
+// first.
+//
+// Buffered operations wait for the batch to be flushed, returning their result
+// from the batch as the operation's result. The operations' own requests are
+// not sent. Operations whose Context is canceled while waiting return the
+// Context's error, but remain part of the batch.
+//
+// A single WriteCoalescing should be shared by all operation stacks of a
+// client, so that operations are batched across invocations.
+type WriteCoalescing struct {
+	options WriteCoalescingOptions
+
+	mu      sync.Mutex
+	pending *writeBatch
+}
+
+// writeBatch is a batch of buffered operations waiting to be flushed.
+type writeBatch struct {
+	ctx     context.Context
+	inputs  []interface{}
+	results []chan BatchResult
+	timer   *time.Timer
+	flushed bool
+}
+
+// NewWriteCoalescing returns an initialized WriteCoalescing middleware.
+// Returns an error if the options are not valid.
+func NewWriteCoalescing(options WriteCoalescingOptions) (*WriteCoalescing, error) {
+	if options.Batcher == nil {
+		return nil, fmt.Errorf("write coalescing batcher must be set")
+	}
+	if options.Qualifies == nil {
+		return nil, fmt.Errorf("write coalescing qualifies function must be set")
+	}
+	if options.Window <= 0 {
+		return nil, fmt.Errorf("write coalescing window must be greater than zero, got %v", options.Window)
+	}
+	if options.MaxBatchSize < 0 {
+		return nil, fmt.Errorf("write coalescing max batch size must not be negative, got %v",
+			options.MaxBatchSize)
+	}
+
+	return &WriteCoalescing{
+		options: options,
+	}, nil
+}
+
+// AddWriteCoalescingMiddleware adds the WriteCoalescing middleware to the end
+// of the stack's Initialize step.
+func AddWriteCoalescingMiddleware(stack *Stack, m *WriteCoalescing) error {
+	return stack.Initialize.Add(m, After)
+}
+
+// ID returns the identifier for the WriteCoalescing middleware.
+func (*WriteCoalescing) ID() string { return "WriteCoalescing" }
+
+// HandleInitialize buffers the operation if it qualifies, waiting for its
+// batch to be flushed.
+func (m *WriteCoalescing) HandleInitialize(ctx context.Context, in InitializeInput, next InitializeHandler) (
+	out InitializeOutput, metadata Metadata, err error,
+) {
+	if !m.options.Qualifies(in.Parameters) {
+		return next.HandleInitialize(ctx, in)
+	}
+
+	result := m.add(ctx, in.Parameters)
+
+	select {
+	case r := <-result:
+		out.Result = r.Output
+		return out, metadata, r.Err
+	case <-ctx.Done():
+		return out, metadata, ctx.Err()
+	}
+}
+
+// add buffers the input in the pending batch, starting a new batch if none is
+// pending. Returns the channel the input's result will be sent on.
+func (m *WriteCoalescing) add(ctx context.Context, input interface{}) <-chan BatchResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	batch := m.pending
+	if batch == nil {
+		// The batch is flushed with the first operation's Context, without
+		// its cancellation, since the batch includes other operations.
+		batch = &writeBatch{ctx: smithycontext.WithSuppressCancel(ctx)}
+		batch.timer = time.AfterFunc(m.options.Window, func() { m.flush(batch) })
+		m.pending = batch
+	}
+
+	result := make(chan BatchResult, 1)
+	batch.inputs = append(batch.inputs, input)
+	batch.results = append(batch.results, result)
+
+	if m.options.MaxBatchSize > 0 && len(batch.inputs) >= m.options.MaxBatchSize {
+		batch.timer.Stop()
+		m.pending = nil
+		batch.flushed = true
+		go m.send(batch)
+	}
+
+	return result
+}
+
+// flush flushes the batch when its window ends, if not already flushed.
+func (m *WriteCoalescing) flush(batch *writeBatch) {
+	m.mu.Lock()
+	if batch.flushed {
+		m.mu.Unlock()
+		return
+	}
+	batch.flushed = true
+	if m.pending == batch {
+		m.pending = nil
+	}
+	m.mu.Unlock()
+
+	m.send(batch)
+}
+
+// send invokes the batcher with the batch, sending each operation its result.
+func (m *WriteCoalescing) send(batch *writeBatch) {
+	results, err := m.options.Batcher(batch.ctx, batch.inputs)
+	if err == nil && len(results) != len(batch.inputs) {
+		err = fmt.Errorf("write batcher returned %d results for %d inputs",
+			len(results), len(batch.inputs))
+	}
+
+	for i, result := range batch.results {
+		if err != nil {
+			result <- BatchResult{Err: err}
+		} else {
+			result <- results[i]
+		}
+	}
+}
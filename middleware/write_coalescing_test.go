@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+type writeInput struct {
+	Key   string
+	Write bool
+}
+
+func TestWriteCoalescing(t *testing.T) {
+	cases := map[string]struct {
+		Window        time.Duration
+		MaxBatchSize  int
+		Inputs        []writeInput
+		ExpectBatches [][]string
+		ExpectDirect  []string
+	}{
+		"flush on deadline": {
+			Window: 50 * time.Millisecond,
+			Inputs: []writeInput{
+				{Key: "a", Write: true},
+				{Key: "b", Write: true},
+				{Key: "c", Write: true},
+			},
+			ExpectBatches: [][]string{{"a", "b", "c"}},
+		},
+		"flush on size": {
+			Window:       time.Hour,
+			MaxBatchSize: 2,
+			Inputs: []writeInput{
+				{Key: "a", Write: true},
+				{Key: "b", Write: true},
+			},
+			ExpectBatches: [][]string{{"a", "b"}},
+		},
+		"non-qualifying not batched": {
+			Window: 50 * time.Millisecond,
+			Inputs: []writeInput{
+				{Key: "a", Write: true},
+				{Key: "read"},
+				{Key: "b", Write: true},
+			},
+			ExpectBatches: [][]string{{"a", "b"}},
+			ExpectDirect:  []string{"read"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			var batches [][]string
+			var direct []string
+
+			m, err := NewWriteCoalescing(WriteCoalescingOptions{
+				Window:       c.Window,
+				MaxBatchSize: c.MaxBatchSize,
+				Qualifies: func(input interface{}) bool {
+					return input.(writeInput).Write
+				},
+				Batcher: func(ctx context.Context, inputs []interface{}) ([]BatchResult, error) {
+					var keys []string
+					results := make([]BatchResult, 0, len(inputs))
+					for _, input := range inputs {
+						key := input.(writeInput).Key
+						keys = append(keys, key)
+						results = append(results, BatchResult{Output: "batched " + key})
+					}
+					sort.Strings(keys)
+
+					mu.Lock()
+					batches = append(batches, keys)
+					mu.Unlock()
+					return results, nil
+				},
+			})
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var wg sync.WaitGroup
+			for _, input := range c.Inputs {
+				wg.Add(1)
+				go func(input writeInput) {
+					defer wg.Done()
+					out, _, err := m.HandleInitialize(context.Background(),
+						InitializeInput{Parameters: input},
+						InitializeHandlerFunc(func(ctx context.Context, in InitializeInput) (
+							out InitializeOutput, metadata Metadata, err error,
+						) {
+							key := in.Parameters.(writeInput).Key
+							mu.Lock()
+							direct = append(direct, key)
+							mu.Unlock()
+							out.Result = "direct " + key
+							return out, metadata, nil
+						}),
+					)
+					if err != nil {
+						t.Errorf("expect no error, got %v", err)
+						return
+					}
+
+					expect := "batched " + input.Key
+					if !input.Write {
+						expect = "direct " + input.Key
+					}
+					if e, a := expect, out.Result; e != a {
+						t.Errorf("expect %v result, got %v", e, a)
+					}
+				}(input)
+			}
+			wg.Wait()
+
+			if e, a := c.ExpectBatches, batches; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v batches, got %v", e, a)
+			}
+			if e, a := c.ExpectDirect, direct; !reflect.DeepEqual(e, a) {
+				t.Errorf("expect %v direct operations, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestWriteCoalescing_BatchError(t *testing.T) {
+	m, err := NewWriteCoalescing(WriteCoalescingOptions{
+		Window:    10 * time.Millisecond,
+		Qualifies: func(interface{}) bool { return true },
+		Batcher: func(ctx context.Context, inputs []interface{}) ([]BatchResult, error) {
+			return nil, fmt.Errorf("batch failed")
+		},
+	})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	_, _, err = m.HandleInitialize(context.Background(), InitializeInput{Parameters: "a"},
+		InitializeHandlerFunc(func(ctx context.Context, in InitializeInput) (
+			out InitializeOutput, metadata Metadata, err error,
+		) {
+			t.Errorf("expect operation not to be invoked directly")
+			return out, metadata, nil
+		}),
+	)
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+}
+
+func TestNewWriteCoalescing_Invalid(t *testing.T) {
+	batcher := func(context.Context, []interface{}) ([]BatchResult, error) { return nil, nil }
+	qualifies := func(interface{}) bool { return true }
+
+	cases := map[string]WriteCoalescingOptions{
+		"no batcher":   {Qualifies: qualifies, Window: time.Second},
+		"no qualifies": {Batcher: batcher, Window: time.Second},
+		"no window":    {Batcher: batcher, Qualifies: qualifies},
+		"negative size": {
+			Batcher: batcher, Qualifies: qualifies, Window: time.Second, MaxBatchSize: -1,
+		},
+	}
+
+	for name, options := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewWriteCoalescing(options); err == nil {
+				t.Fatalf("expect error, got none")
+			}
+		})
+	}
+}
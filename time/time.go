@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -22,8 +23,31 @@ const (
 	httpDateFormatSingleDigitDayTwoDigitYear = "Mon, _2 Jan 06 15:04:05 GMT"
 )
 
+// Timestamp formats of the Smithy timestampFormat trait.
+const (
+	DateTimeFormatName     = "date-time"
+	HTTPDateFormatName     = "http-date"
+	EpochSecondsFormatName = "epoch-seconds"
+)
+
 var millisecondFloat = big.NewFloat(1e3)
 
+// FormatTimestamp formats value as a string in the Smithy timestamp format,
+// one of DateTimeFormatName, HTTPDateFormatName, or EpochSecondsFormatName.
+// Returns an error if the format is not supported.
+func FormatTimestamp(value time.Time, format string) (string, error) {
+	switch format {
+	case DateTimeFormatName:
+		return FormatDateTime(value), nil
+	case HTTPDateFormatName:
+		return FormatHTTPDate(value), nil
+	case EpochSecondsFormatName:
+		return strconv.FormatFloat(FormatEpochSeconds(value), 'f', -1, 64), nil
+	default:
+		return "", fmt.Errorf("unsupported timestamp format %q", format)
+	}
+}
+
 // FormatDateTime formats value as a date-time, (RFC3339 section 5.6)
 //
 // Example: 1985-04-12T23:20:50.52Z
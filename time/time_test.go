@@ -225,3 +225,36 @@ func TestEpochSeconds(t *testing.T) {
 		t.Errorf("expected %v, got %v", e, a)
 	}
 }
+
+func TestFormatTimestamp(t *testing.T) {
+	value := time.Date(2014, 4, 29, 18, 30, 38, 0, time.UTC)
+
+	cases := map[string]struct {
+		Format    string
+		Expect    string
+		ExpectErr bool
+	}{
+		"date-time":     {Format: DateTimeFormatName, Expect: "2014-04-29T18:30:38Z"},
+		"http-date":     {Format: HTTPDateFormatName, Expect: "Tue, 29 Apr 2014 18:30:38 GMT"},
+		"epoch-seconds": {Format: EpochSecondsFormatName, Expect: "1398796238"},
+		"unsupported":   {Format: "unix", ExpectErr: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := FormatTimestamp(value, c.Format)
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.Expect, actual; e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
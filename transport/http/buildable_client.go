@@ -0,0 +1,283 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Defaults for the HTTP transport created by NewBuildableClient.
+const (
+	DefaultHTTPTransportMaxIdleConns          = 100
+	DefaultHTTPTransportMaxIdleConnsPerHost   = 10
+	DefaultHTTPTransportIdleConnTimeout       = 90 * time.Second
+	DefaultHTTPTransportTLSHandshakeTimeout   = 10 * time.Second
+	DefaultHTTPTransportExpectContinueTimeout = 1 * time.Second
+
+	DefaultDialConnectTimeout   = 30 * time.Second
+	DefaultDialKeepAliveTimeout = 30 * time.Second
+)
+
+// BuildableClient provides an HTTP client implementation with options to
+// create copies of the HTTP client when additional configuration is provided.
+//
+// The client's methods will not share the http.Transport value between copies
+// of the BuildableClient. Only exported member values of the Transport and
+// optional Dialer will be copied between copies of BuildableClient.
+type BuildableClient struct {
+	transport *http.Transport
+	dialer    *net.Dialer
+
+	initOnce sync.Once
+
+	clientTimeout time.Duration
+	checkRedirect func(*http.Request, []*http.Request) error
+	client        *http.Client
+
+	wireObservers        []wireObserverEntry
+	responseBodyWrappers []ResponseBodyWrapper
+
+	transportRetriesDisabled bool
+	connectionBytesReporter  func(ConnectionBytes)
+}
+
+// NewBuildableClient returns an initialized client for invoking HTTP
+// requests.
+func NewBuildableClient() *BuildableClient {
+	return &BuildableClient{}
+}
+
+// Do implements the ClientDo interface, invoking the underlying HTTP client
+// to round trip the request. Do's behavior is safe to call concurrently. The
+// HTTP client is lazily built on the first call to Do. Options applied after
+// the first Do call will produce a new BuildableClient, and will not modify
+// the HTTP client in use.
+func (b *BuildableClient) Do(req *http.Request) (*http.Response, error) {
+	b.initOnce.Do(b.build)
+
+	return b.client.Do(req)
+}
+
+// CloseIdleConnections closes the idle connections of the client's HTTP
+// transport. Connections in use are not closed.
+func (b *BuildableClient) CloseIdleConnections() {
+	b.initOnce.Do(b.build)
+
+	b.client.CloseIdleConnections()
+}
+
+// closeIdleConnections closes the idle connections of the RoundTripper, if it
+// supports closing idle connections.
+func closeIdleConnections(rt http.RoundTripper) {
+	if c, ok := rt.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
+
+// Freeze returns a frozen ClientDo that cannot be modified by further
+// options.
+func (b *BuildableClient) Freeze() ClientDo {
+	cpy := b.clone()
+	cpy.build()
+	return cpy.client
+}
+
+func (b *BuildableClient) build() {
+	tr := b.GetTransport()
+	if b.connectionBytesReporter != nil {
+		counter := &connectionBytesCounter{report: b.connectionBytesReporter}
+		tr.DialContext = counter.dialContext(tr.DialContext)
+	}
+
+	b.client = &http.Client{
+		Timeout:       b.clientTimeout,
+		CheckRedirect: b.checkRedirect,
+		Transport: newBodyWrappingRoundTripper(
+			newObservingRoundTripper(
+				newNonReplayableRoundTripper(tr, b.transportRetriesDisabled),
+				b.wireObservers,
+			),
+			b.responseBodyWrappers,
+		),
+	}
+}
+
+func (b *BuildableClient) clone() *BuildableClient {
+	cpy := NewBuildableClient()
+	cpy.transport = b.GetTransport()
+	cpy.dialer = b.GetDialer()
+	cpy.clientTimeout = b.clientTimeout
+	cpy.checkRedirect = b.checkRedirect
+	cpy.wireObservers = append([]wireObserverEntry(nil), b.wireObservers...)
+	cpy.responseBodyWrappers = append([]ResponseBodyWrapper(nil), b.responseBodyWrappers...)
+	cpy.transportRetriesDisabled = b.transportRetriesDisabled
+	cpy.connectionBytesReporter = b.connectionBytesReporter
+
+	return cpy
+}
+
+// WithTransportOptions copies the BuildableClient and returns it with the
+// http.Transport options applied.
+func (b *BuildableClient) WithTransportOptions(opts ...func(*http.Transport)) *BuildableClient {
+	cpy := b.clone()
+
+	tr := cpy.GetTransport()
+	for _, opt := range opts {
+		opt(tr)
+	}
+	cpy.transport = tr
+
+	return cpy
+}
+
+// WithDialerOptions copies the BuildableClient and returns it with the
+// net.Dialer options applied. Will set the client's http.Transport DialContext
+// member.
+func (b *BuildableClient) WithDialerOptions(opts ...func(*net.Dialer)) *BuildableClient {
+	cpy := b.clone()
+
+	dialer := cpy.GetDialer()
+	for _, opt := range opts {
+		opt(dialer)
+	}
+	cpy.dialer = dialer
+
+	tr := cpy.GetTransport()
+	tr.DialContext = cpy.dialer.DialContext
+	cpy.transport = tr
+
+	return cpy
+}
+
+// DialContextFunc provides the signature of a function that returns a network
+// connection to the address. It matches the signature of the http.Transport
+// DialContext member.
+type DialContextFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// WithDialContext copies the BuildableClient and returns it with the
+// transport's connections created by the dial function, instead of dialing
+// the network. This allows requests to be sent over preconnected, tunneled, or
+// in-memory connections, (e.g. net.Pipe).
+//
+// The dial function is called for each new connection the transport needs.
+// Options applied with WithDialerOptions after WithDialContext will replace
+// the dial function.
+func (b *BuildableClient) WithDialContext(dial DialContextFunc) *BuildableClient {
+	return b.WithTransportOptions(func(tr *http.Transport) {
+		tr.DialContext = dial
+	})
+}
+
+// WithTimeout sets the timeout used by the client for all requests.
+func (b *BuildableClient) WithTimeout(timeout time.Duration) *BuildableClient {
+	cpy := b.clone()
+	cpy.clientTimeout = timeout
+	return cpy
+}
+
+// WithCheckRedirect copies the BuildableClient and returns it with the
+// client's redirect policy set to checkRedirect. See the http.Client
+// CheckRedirect member for the behavior of the function. A nil checkRedirect
+// resets the client to the http.Client default policy.
+func (b *BuildableClient) WithCheckRedirect(checkRedirect func(req *http.Request, via []*http.Request) error) *BuildableClient {
+	cpy := b.clone()
+	cpy.checkRedirect = checkRedirect
+	return cpy
+}
+
+// WithResponseHeaderTimeout copies the BuildableClient and returns it with the
+// transport's ResponseHeaderTimeout set. The request will fail if the
+// response headers are not received within the timeout after the request has
+// been sent. Unlike WithTimeout, the timeout does not include the time to
+// read the response body. Zero means no timeout.
+func (b *BuildableClient) WithResponseHeaderTimeout(timeout time.Duration) *BuildableClient {
+	return b.WithTransportOptions(func(tr *http.Transport) {
+		tr.ResponseHeaderTimeout = timeout
+	})
+}
+
+// WithTLSHandshakeTimeout copies the BuildableClient and returns it with the
+// transport's TLSHandshakeTimeout set. The request will fail if the TLS
+// handshake with the server does not complete within the timeout. Zero means
+// no timeout. If not set, DefaultHTTPTransportTLSHandshakeTimeout is used.
+func (b *BuildableClient) WithTLSHandshakeTimeout(timeout time.Duration) *BuildableClient {
+	return b.WithTransportOptions(func(tr *http.Transport) {
+		tr.TLSHandshakeTimeout = timeout
+	})
+}
+
+// WithRootCAs copies the BuildableClient and returns it with the transport's
+// TLS configuration using the provided certificate pool as the set of root
+// certificate authorities to verify server certificates against. A nil pool
+// resets the client to use the host's system root CA set.
+func (b *BuildableClient) WithRootCAs(pool *x509.CertPool) *BuildableClient {
+	return b.WithTransportOptions(func(tr *http.Transport) {
+		if tr.TLSClientConfig == nil {
+			tr.TLSClientConfig = &tls.Config{}
+		} else {
+			tr.TLSClientConfig = tr.TLSClientConfig.Clone()
+		}
+		tr.TLSClientConfig.RootCAs = pool
+	})
+}
+
+// GetTransport returns a copy of the client's HTTP Transport.
+func (b *BuildableClient) GetTransport() *http.Transport {
+	var tr *http.Transport
+	if b.transport != nil {
+		tr = b.transport.Clone()
+	} else {
+		tr = defaultHTTPTransport()
+	}
+
+	return tr
+}
+
+// GetDialer returns a copy of the client's network dialer.
+func (b *BuildableClient) GetDialer() *net.Dialer {
+	var dialer *net.Dialer
+	if b.dialer != nil {
+		d := *b.dialer
+		dialer = &d
+	} else {
+		dialer = defaultDialer()
+	}
+
+	return dialer
+}
+
+// GetTimeout returns a copy of the client's timeout to cancel requests with.
+func (b *BuildableClient) GetTimeout() time.Duration {
+	return b.clientTimeout
+}
+
+func defaultDialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout:   DefaultDialConnectTimeout,
+		KeepAlive: DefaultDialKeepAliveTimeout,
+	}
+}
+
+func defaultHTTPTransport() *http.Transport {
+	dialer := defaultDialer()
+
+	tr := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   DefaultHTTPTransportTLSHandshakeTimeout,
+		MaxIdleConns:          DefaultHTTPTransportMaxIdleConns,
+		MaxIdleConnsPerHost:   DefaultHTTPTransportMaxIdleConnsPerHost,
+		IdleConnTimeout:       DefaultHTTPTransportIdleConnTimeout,
+		ExpectContinueTimeout: DefaultHTTPTransportExpectContinueTimeout,
+		ForceAttemptHTTP2:     true,
+		TLSClientConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		},
+	}
+
+	return tr
+}
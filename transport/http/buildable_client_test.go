@@ -0,0 +1,518 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildableClient_DefaultTransport(t *testing.T) {
+	client := NewBuildableClient()
+
+	if client.transport != nil {
+		t.Errorf("expect transport to be lazily initialized")
+	}
+
+	tr := client.GetTransport()
+	if tr == nil {
+		t.Fatalf("expect default transport")
+	}
+	if e, a := DefaultHTTPTransportMaxIdleConns, tr.MaxIdleConns; e != a {
+		t.Errorf("expect %v max idle conns, got %v", e, a)
+	}
+}
+
+func TestBuildableClient_WithRootCAs(t *testing.T) {
+	pool := x509.NewCertPool()
+
+	client := NewBuildableClient()
+	withPool := client.WithRootCAs(pool)
+
+	tr := withPool.GetTransport()
+	if tr.TLSClientConfig == nil {
+		t.Fatalf("expect TLS config to be set")
+	}
+	if e, a := pool, tr.TLSClientConfig.RootCAs; e != a {
+		t.Errorf("expect %p root CAs, got %p", e, a)
+	}
+
+	if v := client.GetTransport().TLSClientConfig.RootCAs; v != nil {
+		t.Errorf("expect original client to not be modified, got %p", v)
+	}
+
+	reset := withPool.WithRootCAs(nil)
+	if v := reset.GetTransport().TLSClientConfig.RootCAs; v != nil {
+		t.Errorf("expect nil root CAs to use system defaults, got %p", v)
+	}
+	if e, a := pool, withPool.GetTransport().TLSClientConfig.RootCAs; e != a {
+		t.Errorf("expect %p root CAs to be retained, got %p", e, a)
+	}
+}
+
+func TestBuildableClient_WithRootCAs_PreservesTLSConfig(t *testing.T) {
+	client := NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+		tr.TLSClientConfig.ServerName = "example.com"
+	})
+
+	tr := client.WithRootCAs(x509.NewCertPool()).GetTransport()
+	if e, a := "example.com", tr.TLSClientConfig.ServerName; e != a {
+		t.Errorf("expect %v server name, got %v", e, a)
+	}
+}
+
+func TestBuildableClient_WithWireObserver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = ioutil.ReadAll(r.Body)
+		w.Header().Set("X-Response", "abc")
+		w.WriteHeader(201)
+		_, _ = w.Write([]byte("response body content"))
+	}))
+	defer server.Close()
+
+	var observations []WireObservation
+	client := NewBuildableClient().WithWireObserver(WireObserverFunc(func(o WireObservation) {
+		observations = append(observations, o)
+	}), 8)
+
+	req, err := http.NewRequest("POST", server.URL+"/path", strings.NewReader("request body content"))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	req.Header.Set("X-Request", "123")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "response body content", string(body); e != a {
+		t.Errorf("expect full response body %q, got %q", e, a)
+	}
+
+	if e, a := 1, len(observations); e != a {
+		t.Fatalf("expect %v observations, got %v", e, a)
+	}
+	o := observations[0]
+	if o.Err != nil {
+		t.Errorf("expect no error, got %v", o.Err)
+	}
+	if e, a := "123", o.Request.Header.Get("X-Request"); e != a {
+		t.Errorf("expect %v request header, got %v", e, a)
+	}
+	if e, a := "/path", o.Request.URL.Path; e != a {
+		t.Errorf("expect %v request path, got %v", e, a)
+	}
+	if e, a := "request ", string(o.RequestBody); e != a {
+		t.Errorf("expect %q request body capture, got %q", e, a)
+	}
+	if e, a := 201, o.Response.StatusCode; e != a {
+		t.Errorf("expect %v status code, got %v", e, a)
+	}
+	if e, a := "abc", o.Response.Header.Get("X-Response"); e != a {
+		t.Errorf("expect %v response header, got %v", e, a)
+	}
+	if e, a := "response", string(o.ResponseBody); e != a {
+		t.Errorf("expect %q response body capture, got %q", e, a)
+	}
+
+	o.Response.Header.Set("X-Response", "modified")
+	if e, a := "abc", resp.Header.Get("X-Response"); e != a {
+		t.Errorf("expect observer copy to not modify response, got %v", a)
+	}
+}
+
+func TestBuildableClient_WithWireObserver_StreamingResponse(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("first "))
+		w.(http.Flusher).Flush()
+		<-release
+		_, _ = w.Write([]byte("second"))
+	}))
+	defer server.Close()
+	defer func() {
+		select {
+		case <-release:
+		default:
+			close(release)
+		}
+	}()
+
+	observed := make(chan WireObservation, 1)
+	client := NewBuildableClient().WithWireObserver(WireObserverFunc(func(o WireObservation) {
+		observed <- o
+	}), 1024)
+
+	// The response is returned before the stream completes.
+	resp, err := client.Do(mustNewRequest(t, "GET", server.URL))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	select {
+	case <-observed:
+		t.Fatalf("expect observer not notified before body is read")
+	default:
+	}
+
+	close(release)
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "first second", string(body); e != a {
+		t.Errorf("expect %q body, got %q", e, a)
+	}
+
+	select {
+	case o := <-observed:
+		if e, a := "first second", string(o.ResponseBody); e != a {
+			t.Errorf("expect %q response body capture, got %q", e, a)
+		}
+		if o.ResponseBodyErr != nil {
+			t.Errorf("expect no response body error, got %v", o.ResponseBodyErr)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expect observer notified at EOF")
+	}
+
+	resp.Body.Close()
+	select {
+	case <-observed:
+		t.Errorf("expect observer notified once")
+	default:
+	}
+}
+
+type readWriteCloser struct {
+	io.Reader
+	bytes.Buffer
+	closed bool
+}
+
+func (c *readWriteCloser) Read(p []byte) (int, error)  { return c.Reader.Read(p) }
+func (c *readWriteCloser) Write(p []byte) (int, error) { return c.Buffer.Write(p) }
+func (c *readWriteCloser) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestObservingRoundTripper_ResponseBody(t *testing.T) {
+	readErr := fmt.Errorf("connection reset")
+
+	cases := map[string]struct {
+		Body          func() io.ReadCloser
+		ExpectCapture string
+		ExpectErr     error
+		ExpectWriter  bool
+	}{
+		"read error": {
+			Body: func() io.ReadCloser {
+				return ioutil.NopCloser(io.MultiReader(strings.NewReader("partial"), &failingReader{err: readErr}))
+			},
+			ExpectCapture: "partial",
+			ExpectErr:     readErr,
+		},
+		"closed before EOF": {
+			Body: func() io.ReadCloser {
+				return ioutil.NopCloser(strings.NewReader("unread"))
+			},
+		},
+		"switching protocols": {
+			Body: func() io.ReadCloser {
+				return &readWriteCloser{Reader: strings.NewReader("upgraded")}
+			},
+			ExpectWriter: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var observations []WireObservation
+			rt := newObservingRoundTripper(
+				roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+					return &http.Response{StatusCode: 200, Header: http.Header{}, Body: c.Body()}, nil
+				}),
+				[]wireObserverEntry{{
+					observer: WireObserverFunc(func(o WireObservation) {
+						observations = append(observations, o)
+					}),
+					maxBodyCapture: 1024,
+				}},
+			)
+
+			resp, err := rt.RoundTrip(mustNewRequest(t, "GET", "https://example.com"))
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if len(observations) != 0 {
+				t.Fatalf("expect no observation before body is read")
+			}
+
+			w, ok := resp.Body.(io.Writer)
+			if e, a := c.ExpectWriter, ok; e != a {
+				t.Fatalf("expect body writer %v, got %v", e, a)
+			}
+			if ok {
+				if _, err := w.Write([]byte("ping")); err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+			}
+
+			if len(c.ExpectCapture) != 0 {
+				_, _ = ioutil.ReadAll(resp.Body)
+			}
+			resp.Body.Close()
+
+			if e, a := 1, len(observations); e != a {
+				t.Fatalf("expect %v observations, got %v", e, a)
+			}
+			o := observations[0]
+			if e, a := c.ExpectCapture, string(o.ResponseBody); e != a {
+				t.Errorf("expect %q response body capture, got %q", e, a)
+			}
+			if e, a := c.ExpectErr, o.ResponseBodyErr; e != a {
+				t.Errorf("expect %v response body error, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestBuildableClient_WithDialContext(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		defer serverConn.Close()
+
+		req, err := http.ReadRequest(bufio.NewReader(serverConn))
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		body := "hello " + req.URL.Path
+		resp := &http.Response{
+			StatusCode:    200,
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			ContentLength: int64(len(body)),
+			Body:          ioutil.NopCloser(strings.NewReader(body)),
+		}
+		serverErr <- resp.Write(serverConn)
+	}()
+
+	var dialed int
+	client := NewBuildableClient().
+		WithTransportOptions(func(tr *http.Transport) {
+			tr.Proxy = nil
+		}).
+		WithDialContext(func(ctx context.Context, network, address string) (net.Conn, error) {
+			dialed++
+			return clientConn, nil
+		})
+
+	req, err := http.NewRequest("GET", "http://example.com/pipe", nil)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "hello /pipe", string(b); e != a {
+		t.Errorf("expect %q body, got %q", e, a)
+	}
+	if e, a := 1, dialed; e != a {
+		t.Errorf("expect %v dials, got %v", e, a)
+	}
+	if err := <-serverErr; err != nil {
+		t.Errorf("expect no server error, got %v", err)
+	}
+}
+
+func TestBuildableClient_WithResponseHeaderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := NewBuildableClient().WithResponseHeaderTimeout(50 * time.Millisecond)
+	if e, a := 50*time.Millisecond, client.GetTransport().ResponseHeaderTimeout; e != a {
+		t.Errorf("expect %v response header timeout, got %v", e, a)
+	}
+	if e, a := time.Duration(0), client.GetTimeout(); e != a {
+		t.Errorf("expect %v client timeout, got %v", e, a)
+	}
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	resp, err := client.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("expect error, got none")
+	}
+	if !strings.Contains(err.Error(), "timeout awaiting response headers") {
+		t.Errorf("expect response header timeout error, got %v", err)
+	}
+}
+
+func TestBuildableClient_WithTLSHandshakeTimeout(t *testing.T) {
+	// TCP server that accepts connections, but never completes the TLS
+	// handshake.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	defer listener.Close()
+
+	release := make(chan struct{})
+	defer close(release)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				<-release
+				conn.Close()
+			}()
+		}
+	}()
+
+	base := NewBuildableClient()
+	client := base.WithTLSHandshakeTimeout(50 * time.Millisecond)
+	if e, a := 50*time.Millisecond, client.GetTransport().TLSHandshakeTimeout; e != a {
+		t.Errorf("expect %v TLS handshake timeout, got %v", e, a)
+	}
+	if e, a := DefaultHTTPTransportTLSHandshakeTimeout, base.GetTransport().TLSHandshakeTimeout; e != a {
+		t.Errorf("expect %v original TLS handshake timeout, got %v", e, a)
+	}
+
+	req, err := http.NewRequest("GET", "https://"+listener.Addr().String(), nil)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	resp, err := client.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatalf("expect error, got none")
+	}
+	if !strings.Contains(err.Error(), "TLS handshake timeout") {
+		t.Errorf("expect TLS handshake timeout error, got %v", err)
+	}
+}
+
+type upperCaseBody struct {
+	io.ReadCloser
+	closed bool
+}
+
+func (b *upperCaseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	copy(p[:n], bytes.ToUpper(p[:n]))
+	return n, err
+}
+
+// Close does not close the underlying body, to assert the original body is
+// closed regardless.
+func (b *upperCaseBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
+
+func TestBuildableClient_WithResponseBodyWrapper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello world"))
+	}))
+	defer server.Close()
+
+	var wrappers []*upperCaseBody
+	client := NewBuildableClient().
+		WithResponseBodyWrapper(func(body io.ReadCloser) io.ReadCloser {
+			w := &upperCaseBody{ReadCloser: body}
+			wrappers = append(wrappers, w)
+			return w
+		})
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "HELLO WORLD", string(b); e != a {
+		t.Errorf("expect %v body, got %v", e, a)
+	}
+
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := 1, len(wrappers); e != a {
+		t.Fatalf("expect %v wrapper, got %v", e, a)
+	}
+	if !wrappers[0].closed {
+		t.Errorf("expect wrapper to be closed")
+	}
+}
+
+func TestBodyWrappingRoundTripper_Close(t *testing.T) {
+	original := &closeTrackingReader{Reader: strings.NewReader("hello world")}
+
+	rt := newBodyWrappingRoundTripper(
+		roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: 200, Body: original}, nil
+		}),
+		[]ResponseBodyWrapper{
+			func(body io.ReadCloser) io.ReadCloser { return &upperCaseBody{ReadCloser: body} },
+			func(body io.ReadCloser) io.ReadCloser { return &upperCaseBody{ReadCloser: body} },
+		},
+	)
+
+	resp, err := rt.RoundTrip(&http.Request{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if !original.closed {
+		t.Errorf("expect original body to be closed")
+	}
+}
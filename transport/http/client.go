@@ -3,7 +3,9 @@ package http
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"sync"
 
 	smithy "github.com/aws/smithy-go"
 	"github.com/aws/smithy-go/middleware"
@@ -23,6 +25,24 @@ func (fn ClientDoFunc) Do(r *http.Request) (*http.Response, error) {
 	return fn(r)
 }
 
+type clientOverrideKey struct{}
+
+// WithClientOverride returns a copy of the Context with the HTTP client that
+// will be used by the ClientHandler to send the request, instead of the
+// handler's client. This allows individual requests, (e.g. long-polling
+// operations), to be sent with a client configured differently, such as with a
+// larger timeout.
+func WithClientOverride(ctx context.Context, client ClientDo) context.Context {
+	return context.WithValue(ctx, clientOverrideKey{}, client)
+}
+
+// GetClientOverride returns the HTTP client override set on the Context, or
+// nil if no override was set.
+func GetClientOverride(ctx context.Context) ClientDo {
+	v, _ := ctx.Value(clientOverrideKey{}).(ClientDo)
+	return v
+}
+
 // ClientHandler wraps a client that implements the HTTP Do method. Standard
 // implementation is http.Client.
 type ClientHandler struct {
@@ -37,7 +57,8 @@ func NewClientHandler(client ClientDo) ClientHandler {
 }
 
 // Handle implements the middleware Handler interface, that will invoke the
-// underlying HTTP client. Requires the input to be a Smithy *Request. Returns
+// underlying HTTP client, or the client override set on the Context with
+// WithClientOverride. Requires the input to be a Smithy *Request. Returns
 // a smithy *Response, or error if the request failed.
 func (c ClientHandler) Handle(ctx context.Context, input interface{}) (
 	out interface{}, metadata middleware.Metadata, err error,
@@ -52,7 +73,18 @@ func (c ClientHandler) Handle(ctx context.Context, input interface{}) (
 		return nil, metadata, err
 	}
 
-	resp, err := c.client.Do(builtRequest)
+	client := c.client
+	if override := GetClientOverride(ctx); override != nil {
+		client = override
+	}
+
+	var body *bodyReadErrorRecorder
+	if builtRequest.Body != nil && builtRequest.Body != http.NoBody {
+		body = &bodyReadErrorRecorder{ReadCloser: builtRequest.Body}
+		builtRequest.Body = body
+	}
+
+	resp, err := client.Do(builtRequest)
 	if resp == nil {
 		// Ensure a http response value is always present to prevent unexpected
 		// panics.
@@ -62,7 +94,13 @@ func (c ClientHandler) Handle(ctx context.Context, input interface{}) (
 		}
 	}
 	if err != nil {
-		err = &RequestSendError{Err: err}
+		// Errors reading the request body are returned instead of the send
+		// failure they caused, so that the body reader's error is surfaced.
+		if bodyErr := body.Err(); bodyErr != nil {
+			err = &RequestBodyReadError{Err: bodyErr}
+		} else {
+			err = &RequestSendError{Err: err}
+		}
 
 		// Override the error with a context canceled error, if that was canceled.
 		select {
@@ -72,6 +110,13 @@ func (c ClientHandler) Handle(ctx context.Context, input interface{}) (
 		}
 	}
 
+	// Responses that must not have a body are given an empty body, so that
+	// deserializers do not fail reading an unexpected EOF.
+	if err == nil && IsNoBodyResponse(builtRequest.Method, resp.StatusCode) && resp.Body != http.NoBody {
+		_ = DrainAndClose(resp.Body, DefaultMaxResponseBodyDrain)
+		resp.Body = http.NoBody
+	}
+
 	// HTTP RoundTripper *should* close the request body. But this may not happen in a timely manner.
 	// So instead Smithy *Request Build wraps the body to be sent in a safe closer that will clear the
 	// stream reference so that it can be safely reused.
@@ -82,6 +127,22 @@ func (c ClientHandler) Handle(ctx context.Context, input interface{}) (
 	return &Response{Response: resp}, metadata, err
 }
 
+// IsNoBodyResponse returns if a response to the request method with the status
+// code must not have a body. Responses to HEAD requests, and responses with
+// the status codes 204, and 304 do not have a body. Informational responses
+// are not included, since the body of a 101 Switching Protocols response is
+// the upgraded connection.
+func IsNoBodyResponse(method string, statusCode int) bool {
+	switch {
+	case method == http.MethodHead:
+		return true
+	case statusCode == http.StatusNoContent, statusCode == http.StatusNotModified:
+		return true
+	default:
+		return false
+	}
+}
+
 // RequestSendError provides a generic request transport error. This error
 // should wrap errors making HTTP client requests.
 //
@@ -106,6 +167,58 @@ func (e *RequestSendError) Error() string {
 	return fmt.Sprintf("request send failed, %v", e.Err)
 }
 
+// RequestBodyReadError provides the error returned by the ClientHandler when
+// the request failed because reading the request body failed, (e.g. the
+// body's source errored mid-send). The error wraps the body reader's error.
+//
+// Unlike RequestSendError, the error is not a connection error, since the
+// failure was not caused by the network.
+type RequestBodyReadError struct {
+	Err error
+}
+
+// Unwrap returns the body reader's error.
+func (e *RequestBodyReadError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RequestBodyReadError) Error() string {
+	return fmt.Sprintf("request body read failed, %v", e.Err)
+}
+
+// bodyReadErrorRecorder records the first error, other than io.EOF, returned
+// by reads of the request body. Safe for concurrent use, since the HTTP
+// transport may read the request body in a separate goroutine.
+type bodyReadErrorRecorder struct {
+	io.ReadCloser
+
+	mu  sync.Mutex
+	err error
+}
+
+func (r *bodyReadErrorRecorder) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		r.mu.Lock()
+		if r.err == nil {
+			r.err = err
+		}
+		r.mu.Unlock()
+	}
+	return n, err
+}
+
+// Err returns the recorded read error, if any. Returns nil if r is nil.
+func (r *bodyReadErrorRecorder) Err() error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
 // NopClient provides a client that ignores the request, and returns an empty
 // successful HTTP response value.
 type NopClient struct{}
@@ -4,7 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	smithy "github.com/aws/smithy-go"
@@ -91,3 +96,171 @@ func TestClientHandler_Handle(t *testing.T) {
 	}
 
 }
+
+func TestClientHandler_ClientOverride(t *testing.T) {
+	var defaultCalls, overrideCalls int
+	handler := NewClientHandler(ClientDoFunc(func(*http.Request) (*http.Response, error) {
+		defaultCalls++
+		return &http.Response{StatusCode: 200}, nil
+	}))
+	override := ClientDoFunc(func(*http.Request) (*http.Response, error) {
+		overrideCalls++
+		return &http.Response{StatusCode: 202}, nil
+	})
+
+	resp, _, err := handler.Handle(WithClientOverride(context.Background(), override), NewStackRequest())
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := 202, resp.(*Response).StatusCode; e != a {
+		t.Errorf("expect %v status code, got %v", e, a)
+	}
+	if e, a := 1, overrideCalls; e != a {
+		t.Errorf("expect override client called %v times, got %v", e, a)
+	}
+
+	resp, _, err = handler.Handle(context.Background(), NewStackRequest())
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := 200, resp.(*Response).StatusCode; e != a {
+		t.Errorf("expect %v status code, got %v", e, a)
+	}
+	if e, a := 1, defaultCalls; e != a {
+		t.Errorf("expect default client called %v times, got %v", e, a)
+	}
+	if e, a := 1, overrideCalls; e != a {
+		t.Errorf("expect override client called %v times, got %v", e, a)
+	}
+}
+
+func TestClientHandler_NoBodyResponse(t *testing.T) {
+	cases := map[string]struct {
+		Method       string
+		StatusCode   int
+		ExpectNoBody bool
+	}{
+		"204": {
+			Method:       "GET",
+			StatusCode:   204,
+			ExpectNoBody: true,
+		},
+		"304": {
+			Method:       "GET",
+			StatusCode:   304,
+			ExpectNoBody: true,
+		},
+		"HEAD": {
+			Method:       "HEAD",
+			StatusCode:   200,
+			ExpectNoBody: true,
+		},
+		"200": {
+			Method:     "GET",
+			StatusCode: 200,
+		},
+		"101": {
+			Method:     "GET",
+			StatusCode: 101,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			body := &closeTrackingReader{Reader: strings.NewReader("unexpected")}
+			handler := NewClientHandler(ClientDoFunc(func(*http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: c.StatusCode,
+					Header:     http.Header{},
+					Body:       body,
+				}, nil
+			}))
+
+			req := NewStackRequest().(*Request)
+			req.Method = c.Method
+
+			result, _, err := handler.Handle(context.Background(), req)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			b, err := ioutil.ReadAll(result.(*Response).Body)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if c.ExpectNoBody {
+				if e, a := 0, len(b); e != a {
+					t.Errorf("expect %v body length, got %v", e, a)
+				}
+				if !body.closed {
+					t.Errorf("expect response body to be closed")
+				}
+			} else {
+				if e, a := "unexpected", string(b); e != a {
+					t.Errorf("expect %q body, got %q", e, a)
+				}
+				if body.closed {
+					t.Errorf("expect response body not to be closed")
+				}
+			}
+		})
+	}
+}
+
+// failingReader returns err after reading n bytes.
+type failingReader struct {
+	n   int
+	err error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.n <= 0 {
+		return 0, r.err
+	}
+	if len(p) > r.n {
+		p = p[:r.n]
+	}
+	for i := range p {
+		p[i] = 'a'
+	}
+	r.n -= len(p)
+	return len(p), nil
+}
+
+func TestClientHandler_RequestBodyReadError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(ioutil.Discard, r.Body)
+		w.WriteHeader(200)
+	}))
+	defer server.Close()
+
+	readErr := errors.New("body source failed")
+
+	req := NewStackRequest().(*Request)
+	req.Method = "PUT"
+	req.URL, _ = url.Parse(server.URL)
+	req, err := req.SetStream(&failingReader{n: 1024, err: readErr})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	handler := NewClientHandler(NewBuildableClient())
+	_, _, err = handler.Handle(context.Background(), req)
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+
+	var bodyErr *RequestBodyReadError
+	if !errors.As(err, &bodyErr) {
+		t.Fatalf("expect %T error, got %v", bodyErr, err)
+	}
+	if !errors.Is(err, readErr) {
+		t.Errorf("expect body reader error in chain, got %v", err)
+	}
+
+	var sendErr *RequestSendError
+	if errors.As(err, &sendErr) {
+		t.Errorf("expect not %T error, got %v", sendErr, err)
+	}
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+)
+
+// ConnectionBytes is the number of bytes read from, and written to the
+// network connections of a client.
+type ConnectionBytes struct {
+	Read    int64
+	Written int64
+}
+
+// WithConnectionBytesReporter copies the BuildableClient and returns it with
+// the network connections dialed by its transport wrapped to count the bytes
+// read from, and written to each connection at the socket level, including
+// protocol framing, headers, and TLS overhead.
+//
+// report is called after each read and write with the cumulative counts of
+// all the client's connections. report must be safe for concurrent use, and
+// should return quickly, since it is called on the connection's I/O path.
+// Connections dialed with the transport's DialTLSContext are not counted.
+func (b *BuildableClient) WithConnectionBytesReporter(report func(ConnectionBytes)) *BuildableClient {
+	cpy := b.clone()
+	cpy.connectionBytesReporter = report
+	return cpy
+}
+
+// connectionBytesCounter aggregates the bytes read from, and written to a
+// client's connections.
+type connectionBytesCounter struct {
+	read    int64
+	written int64
+	report  func(ConnectionBytes)
+}
+
+func (c *connectionBytesCounter) add(read, written int64) {
+	r := atomic.AddInt64(&c.read, read)
+	w := atomic.AddInt64(&c.written, written)
+	c.report(ConnectionBytes{Read: r, Written: w})
+}
+
+// dialContext wraps the dial function, counting the bytes of the connections
+// it returns.
+func (c *connectionBytesCounter) dialContext(dial DialContextFunc) DialContextFunc {
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		conn, err := dial(ctx, network, address)
+		if err != nil {
+			return nil, err
+		}
+		return &countingConn{Conn: conn, counter: c}, nil
+	}
+}
+
+// countingConn counts the bytes read from, and written to the connection.
+type countingConn struct {
+	net.Conn
+	counter *connectionBytesCounter
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		c.counter.add(int64(n), 0)
+	}
+	return n, err
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if n > 0 {
+		c.counter.add(0, int64(n))
+	}
+	return n, err
+}
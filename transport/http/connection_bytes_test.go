@@ -0,0 +1,84 @@
+package http
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestBuildableClient_WithConnectionBytesReporter(t *testing.T) {
+	const response = "HTTP/1.1 200 OK\r\nContent-Length: 5\r\nConnection: close\r\n\r\nhello"
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	defer listener.Close()
+
+	requestBytes := make(chan int, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// read the request, which has no body, until the end of its headers.
+		var req []byte
+		buf := make([]byte, 1024)
+		for !bytes.Contains(req, []byte("\r\n\r\n")) {
+			n, err := conn.Read(buf)
+			req = append(req, buf[:n]...)
+			if err != nil {
+				break
+			}
+		}
+		requestBytes <- len(req)
+
+		conn.Write([]byte(response))
+	}()
+
+	var mu sync.Mutex
+	var last ConnectionBytes
+	client := NewBuildableClient().WithConnectionBytesReporter(func(b ConnectionBytes) {
+		mu.Lock()
+		defer mu.Unlock()
+		last = b
+	})
+
+	resp, err := client.Do(mustNewRequest(t, "GET", "http://"+listener.Addr().String()+"/"))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "hello", string(body); e != a {
+		t.Errorf("expect %v body, got %v", e, a)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if e, a := int64(<-requestBytes), last.Written; e != a {
+		t.Errorf("expect %v bytes written, got %v", e, a)
+	}
+	if e, a := int64(len(response)), last.Read; e != a {
+		t.Errorf("expect %v bytes read, got %v", e, a)
+	}
+}
+
+func mustNewRequest(t *testing.T, method, url string) *http.Request {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	return req
+}
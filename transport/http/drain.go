@@ -0,0 +1,39 @@
+package http
+
+import (
+	"io"
+	"io/ioutil"
+)
+
+// DefaultMaxResponseBodyDrain is the number of bytes of an unread response
+// body drained before the body is closed, when the response is discarded.
+const DefaultMaxResponseBodyDrain = 64 * 1024
+
+// DrainAndClose reads and discards up to maxDrain bytes of the body, then
+// closes it. Draining a response body before closing it allows the HTTP
+// client to reuse the body's connection. Bodies with more than maxDrain bytes
+// unread are closed without being fully drained, since reading a large body
+// costs more than creating a new connection. A maxDrain of zero or less closes
+// the body without draining it.
+//
+// The body is always closed. Returns the error draining the body, if any,
+// otherwise the error closing it.
+func DrainAndClose(rc io.ReadCloser, maxDrain int64) error {
+	if rc == nil {
+		return nil
+	}
+
+	var drainErr error
+	if maxDrain > 0 {
+		_, drainErr = io.CopyN(ioutil.Discard, rc, maxDrain)
+		if drainErr == io.EOF {
+			drainErr = nil
+		}
+	}
+
+	closeErr := rc.Close()
+	if drainErr != nil {
+		return drainErr
+	}
+	return closeErr
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type drainTrackingBody struct {
+	*strings.Reader
+	readErr  error
+	closeErr error
+	closed   bool
+}
+
+func (b *drainTrackingBody) Read(p []byte) (int, error) {
+	if b.readErr != nil {
+		return 0, b.readErr
+	}
+	return b.Reader.Read(p)
+}
+
+func (b *drainTrackingBody) Close() error {
+	b.closed = true
+	return b.closeErr
+}
+
+func TestDrainAndClose(t *testing.T) {
+	readErr := errors.New("read failed")
+	closeErr := errors.New("close failed")
+
+	cases := map[string]struct {
+		Body         string
+		MaxDrain     int64
+		ReadErr      error
+		CloseErr     error
+		ExpectRemain int
+		ExpectErr    error
+	}{
+		"drains full body": {
+			Body:     "hello world",
+			MaxDrain: 64,
+		},
+		"drains up to limit": {
+			Body:         "hello world",
+			MaxDrain:     5,
+			ExpectRemain: 6,
+		},
+		"no drain": {
+			Body:         "hello world",
+			ExpectRemain: 11,
+		},
+		"read error": {
+			Body:         "hello world",
+			MaxDrain:     64,
+			ReadErr:      readErr,
+			CloseErr:     closeErr,
+			ExpectRemain: 11,
+			ExpectErr:    readErr,
+		},
+		"close error": {
+			Body:      "hello world",
+			MaxDrain:  64,
+			CloseErr:  closeErr,
+			ExpectErr: closeErr,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			body := &drainTrackingBody{
+				Reader:   strings.NewReader(c.Body),
+				readErr:  c.ReadErr,
+				closeErr: c.CloseErr,
+			}
+
+			err := DrainAndClose(body, c.MaxDrain)
+			if e, a := c.ExpectErr, err; e != a {
+				t.Errorf("expect %v error, got %v", e, a)
+			}
+			if !body.closed {
+				t.Errorf("expect body to be closed")
+			}
+			if e, a := c.ExpectRemain, body.Len(); e != a {
+				t.Errorf("expect %v bytes remaining, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestDrainAndClose_Nil(t *testing.T) {
+	if err := DrainAndClose(nil, 64); err != nil {
+		t.Errorf("expect no error, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// jsonErrorCodeMembers are the members of a JSON error document checked for
+// an error code, in order of precedence.
+var jsonErrorCodeMembers = []string{"__type", "code", "Code", "errorCode", "error", "Error"}
+
+// DeriveErrorCode returns a stable error code for an error response the
+// client has no modeled error for. The code is parsed from common error
+// document shapes of the body:
+//
+//	{"__type": "namespace#Code"}, {"code": "Code"}, {"error": {"code": "Code"}}
+//	<Error><Code>Code</Code></Error>
+//
+// If the body does not contain an error code the code is derived from the
+// HTTP status code, (e.g. NotFound for 404), or HTTP<status> if the status code
+// is not known.
+func DeriveErrorCode(statusCode int, body []byte) string {
+	if code := sanitizeDerivedErrorCode(parseErrorCode(body)); len(code) != 0 {
+		return code
+	}
+
+	text := http.StatusText(statusCode)
+	if len(text) == 0 {
+		return fmt.Sprintf("HTTP%d", statusCode)
+	}
+	return strings.NewReplacer(" ", "", "-", "", "'", "").Replace(text)
+}
+
+func parseErrorCode(body []byte) string {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return ""
+	}
+
+	switch body[0] {
+	case '{':
+		return parseJSONErrorCode(body)
+	case '<':
+		return parseXMLErrorCode(body)
+	}
+	return ""
+}
+
+func parseJSONErrorCode(body []byte) string {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return ""
+	}
+
+	for _, member := range jsonErrorCodeMembers {
+		v, ok := doc[member]
+		if !ok {
+			continue
+		}
+
+		var code string
+		if err := json.Unmarshal(v, &code); err == nil {
+			if len(code) != 0 {
+				return code
+			}
+			continue
+		}
+
+		var nested struct {
+			Code      string `json:"code"`
+			ErrorCode string `json:"errorCode"`
+		}
+		if err := json.Unmarshal(v, &nested); err == nil {
+			if len(nested.Code) != 0 {
+				return nested.Code
+			}
+			if len(nested.ErrorCode) != 0 {
+				return nested.ErrorCode
+			}
+		}
+	}
+
+	return ""
+}
+
+func parseXMLErrorCode(body []byte) string {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		t, err := decoder.Token()
+		if err != nil {
+			return ""
+		}
+
+		start, ok := t.(xml.StartElement)
+		if !ok || start.Name.Local != "Code" {
+			continue
+		}
+
+		var code string
+		if err := decoder.DecodeElement(&code, &start); err != nil {
+			return ""
+		}
+		return code
+	}
+}
+
+// sanitizeDerivedErrorCode removes the namespace prefix, and any URI suffix
+// from the error code, (e.g. aws.protocoltests#FooError:http://... becomes
+// FooError).
+func sanitizeDerivedErrorCode(code string) string {
+	code = strings.TrimSpace(code)
+	if i := strings.Index(code, ":"); i != -1 {
+		code = code[:i]
+	}
+	if i := strings.LastIndex(code, "#"); i != -1 {
+		code = code[i+1:]
+	}
+	return code
+}
@@ -0,0 +1,73 @@
+package http
+
+import "testing"
+
+func TestDeriveErrorCode(t *testing.T) {
+	cases := map[string]struct {
+		StatusCode int
+		Body       string
+		Expect     string
+	}{
+		"json code": {
+			StatusCode: 400,
+			Body:       `{"code": "InvalidThing", "message": "thing is invalid"}`,
+			Expect:     "InvalidThing",
+		},
+		"json type with namespace": {
+			StatusCode: 400,
+			Body:       `{"__type": "com.example#InvalidThing:http://internal.example.com/"}`,
+			Expect:     "InvalidThing",
+		},
+		"json nested error": {
+			StatusCode: 409,
+			Body:       `{"error": {"code": "Conflict", "message": "conflict"}}`,
+			Expect:     "Conflict",
+		},
+		"json error string": {
+			StatusCode: 400,
+			Body:       `{"error": "BadThing"}`,
+			Expect:     "BadThing",
+		},
+		"xml code": {
+			StatusCode: 403,
+			Body:       `<ErrorResponse><Error><Type>Sender</Type><Code>AccessDenied</Code></Error></ErrorResponse>`,
+			Expect:     "AccessDenied",
+		},
+		"json without code": {
+			StatusCode: 404,
+			Body:       `{"message": "not here"}`,
+			Expect:     "NotFound",
+		},
+		"xml without code": {
+			StatusCode: 500,
+			Body:       `<Error><Message>oops</Message></Error>`,
+			Expect:     "InternalServerError",
+		},
+		"unstructured body": {
+			StatusCode: 502,
+			Body:       `Bad Gateway`,
+			Expect:     "BadGateway",
+		},
+		"empty body": {
+			StatusCode: 503,
+			Expect:     "ServiceUnavailable",
+		},
+		"empty body unknown status": {
+			StatusCode: 599,
+			Expect:     "HTTP599",
+		},
+		"malformed json": {
+			StatusCode: 400,
+			Body:       `{"code": "Inval`,
+			Expect:     "BadRequest",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if e, a := c.Expect, DeriveErrorCode(c.StatusCode, []byte(c.Body)); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
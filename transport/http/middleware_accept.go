@@ -0,0 +1,73 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// SetAccept provides a build middleware that sets the request's Accept header
+// to the media types the protocol can deserialize. The header is not modified
+// if already set on the request.
+type SetAccept struct {
+	value string
+}
+
+// NewSetAccept returns an initialized SetAccept middleware for the media
+// types, in order of preference. When multiple media types are provided each
+// type after the first is given a q-value weight decreasing by 0.1, to a
+// minimum of 0.1. e.g.
+//
+//	application/json, application/xml;q=0.9
+func NewSetAccept(mediaTypes ...string) *SetAccept {
+	values := make([]string, 0, len(mediaTypes))
+	for i, mediaType := range mediaTypes {
+		if i == 0 {
+			values = append(values, mediaType)
+			continue
+		}
+
+		q := 10 - i
+		if q < 1 {
+			q = 1
+		}
+		values = append(values, mediaType+";q=0."+strconv.Itoa(q))
+	}
+
+	return &SetAccept{
+		value: strings.Join(values, ", "),
+	}
+}
+
+// AddSetAcceptMiddleware adds the SetAccept middleware to the end of the
+// stack's Build step. Returns an error if no media types are provided.
+func AddSetAcceptMiddleware(stack *middleware.Stack, mediaTypes ...string) error {
+	if len(mediaTypes) == 0 {
+		return fmt.Errorf("accept media types must be set")
+	}
+	return stack.Build.Add(NewSetAccept(mediaTypes...), middleware.After)
+}
+
+// ID returns the identifier for the SetAccept middleware.
+func (m *SetAccept) ID() string { return "SetAccept" }
+
+// HandleBuild sets the Accept header on the request if not already set.
+func (m *SetAccept) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	if len(m.value) != 0 && len(req.Header.Get("Accept")) == 0 {
+		req.Header.Set("Accept", m.value)
+	}
+
+	return next.HandleBuild(ctx, in)
+}
@@ -0,0 +1,60 @@
+package http
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestSetAccept(t *testing.T) {
+	cases := map[string]struct {
+		MediaTypes []string
+		Existing   string
+		Expect     string
+	}{
+		"one media type": {
+			MediaTypes: []string{"application/json"},
+			Expect:     "application/json",
+		},
+		"multiple media types": {
+			MediaTypes: []string{"application/cbor", "application/json", "application/xml"},
+			Expect:     "application/cbor, application/json;q=0.9, application/xml;q=0.8",
+		},
+		"minimum weight": {
+			MediaTypes: []string{"a/0", "a/1", "a/2", "a/3", "a/4", "a/5", "a/6", "a/7", "a/8", "a/9", "a/10"},
+			Expect: "a/0, a/1;q=0.9, a/2;q=0.8, a/3;q=0.7, a/4;q=0.6, a/5;q=0.5, " +
+				"a/6;q=0.4, a/7;q=0.3, a/8;q=0.2, a/9;q=0.1, a/10;q=0.1",
+		},
+		"already set": {
+			MediaTypes: []string{"application/json"},
+			Existing:   "text/plain",
+			Expect:     "text/plain",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			if len(c.Existing) != 0 {
+				req.Header.Set("Accept", c.Existing)
+			}
+
+			_, _, err := NewSetAccept(c.MediaTypes...).HandleBuild(context.Background(),
+				middleware.BuildInput{Request: req},
+				middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+					out middleware.BuildOutput, metadata middleware.Metadata, err error,
+				) {
+					r := in.Request.(*Request)
+					if e, a := c.Expect, r.Header.Get("Accept"); e != a {
+						t.Errorf("expect %q Accept header, got %q", e, a)
+					}
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+type clientCertificateKey struct{}
+
+// WithClientCertificate returns a copy of the Context with the TLS client
+// certificate that the ClientCertificate middleware will ensure is presented
+// for the operation's requests.
+func WithClientCertificate(ctx context.Context, cert *tls.Certificate) context.Context {
+	return context.WithValue(ctx, clientCertificateKey{}, cert)
+}
+
+// GetClientCertificate returns the TLS client certificate set on the Context,
+// or nil if no certificate was set.
+func GetClientCertificate(ctx context.Context) *tls.Certificate {
+	v, _ := ctx.Value(clientCertificateKey{}).(*tls.Certificate)
+	return v
+}
+
+// DefaultClientCertificateMaxClients is the default maximum number of clients
+// the ClientCertificate middleware retains, one for each certificate.
+const DefaultClientCertificateMaxClients = 8
+
+// ClientCertificate provides a finalize middleware that ensures the TLS client
+// certificate set on the Context with WithClientCertificate is presented by
+// the HTTP client for the operation's requests. Requests without a client
+// certificate on the Context are sent with the client's default.
+//
+// Since the HTTP transport selects the client certificate when a connection
+// is established, and reuses connections between requests, the middleware
+// sends the requests with a copy of the base client whose TLS configuration's
+// GetClientCertificate returns the certificate. A client is created for each
+// certificate, identified by the fingerprint of its leaf certificate, and
+// reused by requests with the same certificate, (e.g. after the certificate
+// is reloaded). Up to MaxClients clients are retained. The least recently used
+// client is evicted when the limit is exceeded, (e.g. after a certificate is
+// rotated), and its idle connections are closed. The middleware uses
+// WithClientOverride to select the client, and must be used with the
+// ClientHandler.
+type ClientCertificate struct {
+	// MaxClients is the maximum number of clients retained. Defaults to
+	// DefaultClientCertificateMaxClients if zero.
+	MaxClients int
+
+	client *BuildableClient
+
+	mu      sync.Mutex
+	clients map[string]*clientCertificateEntry
+	uses    uint64
+}
+
+type clientCertificateEntry struct {
+	client   *BuildableClient
+	lastUsed uint64
+}
+
+// NewClientCertificate returns an initialized ClientCertificate middleware
+// that creates the clients for each certificate from the base client.
+func NewClientCertificate(client *BuildableClient) *ClientCertificate {
+	return &ClientCertificate{
+		client:  client,
+		clients: map[string]*clientCertificateEntry{},
+	}
+}
+
+// AddClientCertificateMiddleware adds the ClientCertificate middleware to the
+// end of the stack's Finalize step.
+func AddClientCertificateMiddleware(stack *middleware.Stack, client *BuildableClient) error {
+	return stack.Finalize.Add(NewClientCertificate(client), middleware.After)
+}
+
+// ID returns the identifier for the ClientCertificate middleware.
+func (m *ClientCertificate) ID() string { return "ClientCertificate" }
+
+// HandleFinalize selects the client presenting the Context's client
+// certificate to send the request with.
+func (m *ClientCertificate) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	cert := GetClientCertificate(ctx)
+	if cert == nil {
+		return next.HandleFinalize(ctx, in)
+	}
+
+	ctx = WithClientOverride(ctx, m.clientFor(cert))
+	return next.HandleFinalize(ctx, in)
+}
+
+func (m *ClientCertificate) clientFor(cert *tls.Certificate) ClientDo {
+	key := certificateFingerprint(cert)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.uses++
+	if entry, ok := m.clients[key]; ok {
+		entry.lastUsed = m.uses
+		return entry.client
+	}
+
+	client := m.client.WithTransportOptions(func(tr *http.Transport) {
+		if tr.TLSClientConfig == nil {
+			tr.TLSClientConfig = &tls.Config{}
+		} else {
+			tr.TLSClientConfig = tr.TLSClientConfig.Clone()
+		}
+		tr.TLSClientConfig.Certificates = nil
+		tr.TLSClientConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return cert, nil
+		}
+	})
+	m.clients[key] = &clientCertificateEntry{client: client, lastUsed: m.uses}
+	m.evict()
+
+	return client
+}
+
+// evict removes the least recently used clients until at most MaxClients
+// remain, closing their idle connections. Must be called with the lock held.
+func (m *ClientCertificate) evict() {
+	max := m.MaxClients
+	if max <= 0 {
+		max = DefaultClientCertificateMaxClients
+	}
+
+	for len(m.clients) > max {
+		var oldestKey string
+		var oldest *clientCertificateEntry
+		for key, entry := range m.clients {
+			if oldest == nil || entry.lastUsed < oldest.lastUsed {
+				oldestKey, oldest = key, entry
+			}
+		}
+		delete(m.clients, oldestKey)
+		oldest.client.CloseIdleConnections()
+	}
+}
+
+// certificateFingerprint returns the identity of the certificate, the SHA-256
+// fingerprint of its leaf certificate. Certificates without a leaf are
+// identified by their address.
+func certificateFingerprint(cert *tls.Certificate) string {
+	if len(cert.Certificate) == 0 {
+		return fmt.Sprintf("%p", cert)
+	}
+	sum := sha256.Sum256(cert.Certificate[0])
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,191 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestClientCertificate(t *testing.T) {
+	peers := make(chan string, 1)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var name string
+		if len(r.TLS.PeerCertificates) != 0 {
+			name = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		peers <- name
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	client := NewBuildableClient().WithRootCAs(pool)
+
+	m := NewClientCertificate(client)
+	handler := NewClientHandler(client)
+
+	cert := newTestClientCertificate(t, "operation-client")
+
+	cases := map[string]struct {
+		Context    context.Context
+		ExpectPeer string
+	}{
+		"default certificate": {
+			Context: context.Background(),
+		},
+		"operation certificate": {
+			Context:    WithClientCertificate(context.Background(), cert),
+			ExpectPeer: "operation-client",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			req.URL, _ = url.Parse(server.URL)
+
+			_, _, err := m.HandleFinalize(c.Context,
+				middleware.FinalizeInput{Request: req},
+				middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+					out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+				) {
+					out.Result, metadata, err = handler.Handle(ctx, in.Request)
+					return out, metadata, err
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.ExpectPeer, <-peers; e != a {
+				t.Errorf("expect %q client certificate, got %q", e, a)
+			}
+		})
+	}
+
+	if e, a := m.clientFor(cert), m.clientFor(cert); e != a {
+		t.Errorf("expect client to be reused for certificate")
+	}
+}
+
+func TestClientCertificate_ClientReuse(t *testing.T) {
+	m := NewClientCertificate(NewBuildableClient())
+	m.MaxClients = 2
+
+	cert := newTestClientCertificate(t, "first")
+	reloaded := &tls.Certificate{
+		Certificate: cert.Certificate,
+		PrivateKey:  cert.PrivateKey,
+	}
+
+	first := m.clientFor(cert)
+	if e, a := first, m.clientFor(reloaded); e != a {
+		t.Errorf("expect client to be reused for reloaded certificate")
+	}
+
+	secondCert := newTestClientCertificate(t, "second")
+	second := m.clientFor(secondCert)
+	if second == first {
+		t.Errorf("expect new client for different certificate")
+	}
+
+	// Using the first certificate again makes the second least recently used.
+	m.clientFor(cert)
+	m.clientFor(newTestClientCertificate(t, "third"))
+
+	if e, a := 2, len(m.clients); e != a {
+		t.Fatalf("expect %v clients retained, got %v", e, a)
+	}
+	if e, a := first, m.clientFor(cert); e != a {
+		t.Errorf("expect recently used client to be retained")
+	}
+	if _, ok := m.clients[certificateFingerprint(secondCert)]; ok {
+		t.Errorf("expect least recently used client to be evicted")
+	}
+}
+
+func TestClientCertificate_EvictClosesIdleConnections(t *testing.T) {
+	closed := make(chan struct{}, 1)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			select {
+			case closed <- struct{}{}:
+			default:
+			}
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := NewBuildableClient().
+		WithTransportRetriesDisabled().
+		WithWireObserver(WireObserverFunc(func(WireObservation) {}), 0).
+		WithResponseBodyWrapper(func(body io.ReadCloser) io.ReadCloser { return body })
+
+	m := NewClientCertificate(client)
+	m.MaxClients = 1
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	resp, err := m.clientFor(newTestClientCertificate(t, "first")).Do(req)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	m.clientFor(newTestClientCertificate(t, "second"))
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Errorf("expect evicted client's idle connection to be closed")
+	}
+}
+
+func newTestClientCertificate(t *testing.T, commonName string) *tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	}
+}
@@ -29,10 +29,10 @@ func (m *errorCloseResponseBodyMiddleware) HandleDeserialize(
 	out, metadata, err := next.HandleDeserialize(ctx, input)
 	if err != nil {
 		if resp, ok := out.RawResponse.(*Response); ok && resp != nil && resp.Body != nil {
-			// Consume the full body to prevent TCP connection resets on some platforms
-			_, _ = io.Copy(ioutil.Discard, resp.Body)
-			// Do not validate that the response closes successfully.
-			resp.Body.Close()
+			// Drain the body to prevent TCP connection resets on some
+			// platforms. Do not validate that the response closes
+			// successfully.
+			_ = DrainAndClose(resp.Body, DefaultMaxResponseBodyDrain)
 		}
 	}
 
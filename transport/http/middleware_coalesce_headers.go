@@ -0,0 +1,144 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// CoalesceHeaders provides a finalize middleware that removes duplicate
+// tokens from the configured request headers, preserving the order of the
+// first occurrence of each token. Middleware that append to a header may add
+// the same token multiple times, such as when a request is retried.
+//
+// The User-Agent header's tokens are products separated by whitespace. A
+// product's comments, (e.g. "(Linux; x86_64)"), are kept with the product
+// they follow. All other headers are treated as comma separated lists. The
+// values of a header with multiple field lines are combined into a single
+// value.
+type CoalesceHeaders struct {
+	// Headers is the list of header names whose duplicate tokens will be
+	// removed.
+	Headers []string
+}
+
+// AddCoalesceHeadersMiddleware adds the CoalesceHeaders middleware to the end
+// of the stack's Finalize step, for the headers provided.
+func AddCoalesceHeadersMiddleware(stack *middleware.Stack, headers ...string) error {
+	return stack.Finalize.Add(&CoalesceHeaders{Headers: headers}, middleware.After)
+}
+
+// ID returns the identifier for the CoalesceHeaders middleware.
+func (m *CoalesceHeaders) ID() string { return "CoalesceHeaders" }
+
+// HandleFinalize removes the duplicate tokens from the configured headers.
+func (m *CoalesceHeaders) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	for _, name := range m.Headers {
+		values := req.Header.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+
+		if strings.EqualFold(name, "User-Agent") {
+			req.Header.Set(name, strings.Join(uniqueTokens(values, splitUserAgentValue), " "))
+		} else {
+			req.Header.Set(name, strings.Join(uniqueTokens(values, splitHeaderListValue), ", "))
+		}
+	}
+
+	return next.HandleFinalize(ctx, in)
+}
+
+// uniqueTokens returns the tokens of the header values split by split, with
+// duplicates removed, in the order they first occur.
+func uniqueTokens(values []string, split func(string) []string) []string {
+	var tokens []string
+	seen := map[string]struct{}{}
+	for _, v := range values {
+		for _, token := range split(v) {
+			if _, ok := seen[token]; ok {
+				continue
+			}
+			seen[token] = struct{}{}
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+func splitHeaderListValue(v string) []string {
+	var tokens []string
+	for _, token := range strings.Split(v, ",") {
+		if token = strings.TrimSpace(token); len(token) != 0 {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// splitUserAgentValue splits the User-Agent value into its product tokens.
+// Comments, which may contain whitespace, nested comments, and escaped
+// characters, are included in the token of the product they follow.
+func splitUserAgentValue(v string) []string {
+	var tokens []string
+	var token strings.Builder
+	var depth int
+	var escaped bool
+
+	flush := func() {
+		if token.Len() != 0 {
+			tokens = append(tokens, token.String())
+			token.Reset()
+		}
+	}
+
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case escaped:
+			escaped = false
+		case depth > 0 && c == '\\':
+			escaped = true
+		case c == '(':
+			if depth == 0 && token.Len() != 0 {
+				// The comment is part of the preceding product's token.
+				token.WriteByte(' ')
+			}
+			depth++
+		case depth > 0 && c == ')':
+			depth--
+		case depth == 0 && (c == ' ' || c == '\t'):
+			if nextNonSpace(v, i) == '(' && token.Len() != 0 {
+				continue
+			}
+			flush()
+			continue
+		}
+		token.WriteByte(c)
+	}
+	flush()
+
+	return tokens
+}
+
+// nextNonSpace returns the first non whitespace character after index i of
+// v, or 0 if there is none.
+func nextNonSpace(v string, i int) byte {
+	for i++; i < len(v); i++ {
+		if v[i] != ' ' && v[i] != '\t' {
+			return v[i]
+		}
+	}
+	return 0
+}
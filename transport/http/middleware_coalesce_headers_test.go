@@ -0,0 +1,103 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestCoalesceHeaders(t *testing.T) {
+	cases := map[string]struct {
+		Headers []string
+		Header  http.Header
+		Expect  http.Header
+	}{
+		"duplicate user agent tokens": {
+			Headers: []string{"User-Agent"},
+			Header: http.Header{
+				"User-Agent": []string{"smithy-go/1.0 os/linux smithy-go/1.0 lang/go os/linux"},
+			},
+			Expect: http.Header{
+				"User-Agent": []string{"smithy-go/1.0 os/linux lang/go"},
+			},
+		},
+		"user agent multiple values": {
+			Headers: []string{"user-agent"},
+			Header: http.Header{
+				"User-Agent": []string{"smithy-go/1.0 os/linux", "os/linux retry/1"},
+			},
+			Expect: http.Header{
+				"User-Agent": []string{"smithy-go/1.0 os/linux retry/1"},
+			},
+		},
+		"user agent comments": {
+			Headers: []string{"User-Agent"},
+			Header: http.Header{
+				"User-Agent": []string{"app/1.0 (Linux; x86_64) smithy-go/1.0 app/1.0 (Linux; x86_64)"},
+			},
+			Expect: http.Header{
+				"User-Agent": []string{"app/1.0 (Linux; x86_64) smithy-go/1.0"},
+			},
+		},
+		"user agent comments differ by product": {
+			Headers: []string{"User-Agent"},
+			Header: http.Header{
+				"User-Agent": []string{"a/1 (x; y) b/2 (x; y) (nested (comment \\) here)) a/1 (x; y)"},
+			},
+			Expect: http.Header{
+				"User-Agent": []string{"a/1 (x; y) b/2 (x; y) (nested (comment \\) here))"},
+			},
+		},
+		"comma separated list": {
+			Headers: []string{"Accept-Encoding"},
+			Header: http.Header{
+				"Accept-Encoding": []string{"gzip, br", "gzip,deflate"},
+			},
+			Expect: http.Header{
+				"Accept-Encoding": []string{"gzip, br, deflate"},
+			},
+		},
+		"unconfigured header unmodified": {
+			Headers: []string{"User-Agent"},
+			Header: http.Header{
+				"User-Agent":      []string{"a/1 a/1"},
+				"Accept-Encoding": []string{"gzip, gzip"},
+			},
+			Expect: http.Header{
+				"User-Agent":      []string{"a/1"},
+				"Accept-Encoding": []string{"gzip, gzip"},
+			},
+		},
+		"missing header": {
+			Headers: []string{"User-Agent"},
+			Header:  http.Header{},
+			Expect:  http.Header{},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			req.Header = c.Header
+
+			m := CoalesceHeaders{Headers: c.Headers}
+			_, _, err := m.HandleFinalize(context.Background(),
+				middleware.FinalizeInput{Request: req},
+				middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+					out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+				) {
+					if e, a := c.Expect, in.Request.(*Request).Header; !reflect.DeepEqual(e, a) {
+						t.Errorf("expect %v headers, got %v", e, a)
+					}
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+		})
+	}
+}
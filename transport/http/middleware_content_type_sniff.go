@@ -0,0 +1,81 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// sniffLen is the maximum number of bytes http.DetectContentType considers.
+const sniffLen = 512
+
+// ContentTypeSniff provides a build middleware that sets the Content-Type
+// header of requests with a body, but no Content-Type, to the media type
+// detected from the start of the body with http.DetectContentType. A
+// Content-Type already set on the request is not modified.
+//
+// Seekable request streams are rewound after the start of the body is read.
+// Streams that are not seekable are replaced by a stream that replays the
+// bytes read.
+type ContentTypeSniff struct{}
+
+// NewContentTypeSniff returns an initialized ContentTypeSniff middleware.
+func NewContentTypeSniff() *ContentTypeSniff {
+	return &ContentTypeSniff{}
+}
+
+// AddContentTypeSniffMiddleware adds the ContentTypeSniff middleware to the
+// end of the stack's Build step.
+func AddContentTypeSniffMiddleware(stack *middleware.Stack) error {
+	return stack.Build.Add(NewContentTypeSniff(), middleware.After)
+}
+
+// ID returns the identifier for the ContentTypeSniff middleware.
+func (*ContentTypeSniff) ID() string { return "ContentTypeSniff" }
+
+// HandleBuild sets the request's Content-Type header from the request body,
+// if not already set.
+func (m *ContentTypeSniff) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	stream := req.GetStream()
+	if stream == nil || len(req.Header.Values("Content-Type")) != 0 {
+		return next.HandleBuild(ctx, in)
+	}
+
+	b := make([]byte, sniffLen)
+	n, err := io.ReadFull(stream, b)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return out, metadata, fmt.Errorf("failed to read request stream for content type, %w", err)
+	}
+	b = b[:n]
+
+	if req.IsStreamSeekable() {
+		if err := req.RewindStream(); err != nil {
+			return out, metadata, fmt.Errorf("failed to rewind request stream, %w", err)
+		}
+	} else {
+		req, err = req.SetStream(io.MultiReader(bytes.NewReader(b), stream))
+		if err != nil {
+			return out, metadata, fmt.Errorf("failed to set request stream, %w", err)
+		}
+		in.Request = req
+	}
+
+	if n != 0 {
+		req.Header.Set("Content-Type", http.DetectContentType(b))
+	}
+
+	return next.HandleBuild(ctx, in)
+}
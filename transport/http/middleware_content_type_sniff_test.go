@@ -0,0 +1,97 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestContentTypeSniff(t *testing.T) {
+	cases := map[string]struct {
+		Body        io.Reader
+		ContentType string
+		Expect      string
+		ExpectBody  string
+	}{
+		"json": {
+			Body:       strings.NewReader(`{"foo":"bar"}`),
+			Expect:     "text/plain; charset=utf-8",
+			ExpectBody: `{"foo":"bar"}`,
+		},
+		"binary": {
+			Body:       bytes.NewReader([]byte{0x00, 0x01, 0x02, 0xff}),
+			Expect:     "application/octet-stream",
+			ExpectBody: "\x00\x01\x02\xff",
+		},
+		"png": {
+			Body:       strings.NewReader("\x89PNG\x0D\x0A\x1A\x0Aimage"),
+			Expect:     "image/png",
+			ExpectBody: "\x89PNG\x0D\x0A\x1A\x0Aimage",
+		},
+		"not seekable": {
+			Body:       ioutil.NopCloser(strings.NewReader("<html><body></body></html>")),
+			Expect:     "text/html; charset=utf-8",
+			ExpectBody: "<html><body></body></html>",
+		},
+		"not seekable larger than sniff length": {
+			Body:       ioutil.NopCloser(strings.NewReader(strings.Repeat("a", 1024))),
+			Expect:     "text/plain; charset=utf-8",
+			ExpectBody: strings.Repeat("a", 1024),
+		},
+		"explicit type preserved": {
+			Body:        strings.NewReader(`{"foo":"bar"}`),
+			ContentType: "application/json",
+			Expect:      "application/json",
+			ExpectBody:  `{"foo":"bar"}`,
+		},
+		"no body": {},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			if len(c.ContentType) != 0 {
+				req.Header.Set("Content-Type", c.ContentType)
+			}
+			if c.Body != nil {
+				var err error
+				req, err = req.SetStream(c.Body)
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+			}
+
+			_, _, err := NewContentTypeSniff().HandleBuild(context.Background(),
+				middleware.BuildInput{Request: req},
+				middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+					out middleware.BuildOutput, metadata middleware.Metadata, err error,
+				) {
+					r := in.Request.(*Request)
+					if e, a := c.Expect, r.Header.Get("Content-Type"); e != a {
+						t.Errorf("expect %q content type, got %q", e, a)
+					}
+
+					var body []byte
+					if stream := r.GetStream(); stream != nil {
+						body, err = ioutil.ReadAll(stream)
+						if err != nil {
+							t.Fatalf("expect no error, got %v", err)
+						}
+					}
+					if e, a := c.ExpectBody, string(body); e != a {
+						t.Errorf("expect %q body, got %q", e, a)
+					}
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// CorrelationMismatchError is returned by the ValidateCorrelation middleware
+// when the correlation ID echoed by the service in the response does not match
+// the correlation ID sent with the request.
+type CorrelationMismatchError struct {
+	Header   string
+	Expected string
+	Actual   string
+}
+
+func (e *CorrelationMismatchError) Error() string {
+	return fmt.Sprintf("response %s header %q does not match request correlation ID %q",
+		e.Header, e.Actual, e.Expected)
+}
+
+// ValidateCorrelation provides a deserialize middleware that verifies the
+// correlation ID a service echoes in the response header matches the
+// correlation ID the request was sent with in the same header. A mismatch,
+// (e.g. a response routed to the wrong request by a proxy), fails the attempt
+// with a CorrelationMismatchError.
+//
+// Requests without the correlation header are not validated. Responses
+// without the correlation header are not validated, unless Required is set.
+type ValidateCorrelation struct {
+	// Header is the name of the HTTP header carrying the correlation ID.
+	Header string
+
+	// Required fails the attempt if the response does not include the
+	// correlation header.
+	Required bool
+}
+
+// NewValidateCorrelation returns an initialized ValidateCorrelation
+// middleware validating the correlation ID of the header.
+func NewValidateCorrelation(header string) *ValidateCorrelation {
+	return &ValidateCorrelation{Header: header}
+}
+
+// AddValidateCorrelationMiddleware adds the ValidateCorrelation middleware
+// for the header to the end of the stack's Deserialize step.
+func AddValidateCorrelationMiddleware(stack *middleware.Stack, header string) error {
+	return stack.Deserialize.Add(NewValidateCorrelation(header), middleware.After)
+}
+
+// ID returns the identifier for the ValidateCorrelation middleware.
+func (*ValidateCorrelation) ID() string { return "ValidateCorrelation" }
+
+// HandleDeserialize records the request's correlation ID, and compares it to
+// the correlation ID of the response returned by the next handler.
+func (m *ValidateCorrelation) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+	expected := req.Header.Get(m.Header)
+
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil || len(expected) == 0 {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok || resp == nil || resp.Response == nil {
+		return out, metadata, err
+	}
+
+	values := resp.Header.Values(m.Header)
+	if len(values) == 0 && !m.Required {
+		return out, metadata, nil
+	}
+
+	var actual string
+	if len(values) != 0 {
+		actual = values[0]
+	}
+	if actual != expected {
+		return out, metadata, &CorrelationMismatchError{
+			Header:   m.Header,
+			Expected: expected,
+			Actual:   actual,
+		}
+	}
+
+	return out, metadata, nil
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestValidateCorrelation(t *testing.T) {
+	cases := map[string]struct {
+		RequestID      string
+		ResponseID     string
+		Required       bool
+		ExpectMismatch bool
+	}{
+		"matching": {
+			RequestID:  "abc-123",
+			ResponseID: "abc-123",
+		},
+		"mismatching": {
+			RequestID:      "abc-123",
+			ResponseID:     "def-456",
+			ExpectMismatch: true,
+		},
+		"response without correlation": {
+			RequestID: "abc-123",
+		},
+		"response without correlation required": {
+			RequestID:      "abc-123",
+			Required:       true,
+			ExpectMismatch: true,
+		},
+		"request without correlation": {
+			ResponseID: "def-456",
+			Required:   true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			if len(c.RequestID) != 0 {
+				req.Header.Set("X-Correlation-Id", c.RequestID)
+			}
+
+			m := NewValidateCorrelation("X-Correlation-Id")
+			m.Required = c.Required
+
+			_, _, err := m.HandleDeserialize(context.Background(),
+				middleware.DeserializeInput{Request: req},
+				middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					resp := &http.Response{StatusCode: 200, Header: http.Header{}}
+					if len(c.ResponseID) != 0 {
+						resp.Header.Set("X-Correlation-Id", c.ResponseID)
+					}
+					out.RawResponse = &Response{Response: resp}
+					return out, metadata, nil
+				}),
+			)
+
+			var mismatch *CorrelationMismatchError
+			if e, a := c.ExpectMismatch, errors.As(err, &mismatch); e != a {
+				t.Fatalf("expect mismatch %v, got %v", e, a)
+			}
+			if !c.ExpectMismatch {
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				return
+			}
+			if e, a := c.RequestID, mismatch.Expected; e != a {
+				t.Errorf("expect %v expected ID, got %v", e, a)
+			}
+			if e, a := c.ResponseID, mismatch.Actual; e != a {
+				t.Errorf("expect %v actual ID, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DownloadProgress provides a deserialize middleware that reports the
+// progress of reading the response body, invoking the callback as bytes of the
+// body are read by the caller. The response's Content-Length is used as the
+// total, or -1 if unknown.
+type DownloadProgress struct {
+	callback ProgressFunc
+}
+
+// NewDownloadProgress returns an initialized DownloadProgress middleware
+// invoking cb with the bytes read, and total bytes of the response body.
+func NewDownloadProgress(cb func(bytesRead, total int64)) *DownloadProgress {
+	return &DownloadProgress{
+		callback: cb,
+	}
+}
+
+// AddDownloadProgressMiddleware adds the DownloadProgress middleware to the
+// end of the stack's Deserialize step, so that the response body is wrapped
+// before it is read by the deserializers.
+func AddDownloadProgressMiddleware(stack *middleware.Stack, cb func(bytesRead, total int64)) error {
+	return stack.Deserialize.Add(NewDownloadProgress(cb), middleware.After)
+}
+
+// ID returns the identifier for the DownloadProgress middleware.
+func (*DownloadProgress) ID() string { return "DownloadProgress" }
+
+// HandleDeserialize wraps the response body to report the download progress.
+func (m *DownloadProgress) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok || resp == nil || resp.Body == nil || resp.Body == http.NoBody || m.callback == nil {
+		return out, metadata, err
+	}
+
+	total := resp.ContentLength
+	if total < 0 {
+		total = -1
+	}
+
+	resp.Body = &progressReadCloser{
+		progressReader: &progressReader{
+			Reader:   resp.Body,
+			total:    total,
+			callback: m.callback,
+		},
+		Closer: resp.Body,
+	}
+
+	return out, metadata, err
+}
+
+type progressReadCloser struct {
+	*progressReader
+	io.Closer
+}
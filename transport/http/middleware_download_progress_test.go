@@ -0,0 +1,86 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestDownloadProgress(t *testing.T) {
+	const body = "hello world"
+
+	cases := map[string]struct {
+		ContentLength int64
+		ExpectTotal   int64
+	}{
+		"known length": {
+			ContentLength: int64(len(body)),
+			ExpectTotal:   int64(len(body)),
+		},
+		"unknown length": {
+			ContentLength: -1,
+			ExpectTotal:   -1,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var read []int64
+			m := NewDownloadProgress(func(bytesRead, total int64) {
+				if e, a := c.ExpectTotal, total; e != a {
+					t.Errorf("expect %v total, got %v", e, a)
+				}
+				read = append(read, bytesRead)
+			})
+
+			closed := &closeTrackingReader{Reader: strings.NewReader(body)}
+			out, _, err := m.HandleDeserialize(context.Background(), middleware.DeserializeInput{},
+				middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					out.RawResponse = &Response{Response: &http.Response{
+						StatusCode:    200,
+						ContentLength: c.ContentLength,
+						Body:          closed,
+					}}
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if len(read) != 0 {
+				t.Errorf("expect no progress before the body is read, got %v", read)
+			}
+
+			respBody := out.RawResponse.(*Response).Body
+			b, err := ioutil.ReadAll(iotest.OneByteReader(respBody))
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := body, string(b); e != a {
+				t.Errorf("expect %v body, got %v", e, a)
+			}
+
+			if e, a := len(body), len(read); e != a {
+				t.Fatalf("expect %v progress callbacks, got %v", e, a)
+			}
+			for i, n := range read {
+				if e, a := int64(i+1), n; e != a {
+					t.Errorf("expect callback %d to report %v bytes read, got %v", i, e, a)
+				}
+			}
+
+			respBody.Close()
+			if !closed.closed {
+				t.Errorf("expect response body to be closed")
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// hopByHopHeaders are the headers, defined by RFC 7230, that are meaningful
+// only for a single transport-level connection, and must not be forwarded by
+// proxies.
+var hopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// StripHopByHopHeaders provides a build middleware that removes hop-by-hop
+// headers from the outgoing request. The headers removed are Connection,
+// Keep-Alive, TE, Trailer, Transfer-Encoding, Upgrade, all headers prefixed
+// with Proxy-, and any header named by the Connection header.
+type StripHopByHopHeaders struct {
+	// Allow is the list of hop-by-hop header names that will not be removed
+	// from the request.
+	Allow []string
+}
+
+// AddStripHopByHopHeadersMiddleware adds the StripHopByHopHeaders middleware
+// to the end of the stack's Build step. The allow header names will not be
+// removed from the request.
+func AddStripHopByHopHeadersMiddleware(stack *middleware.Stack, allow ...string) error {
+	return stack.Build.Add(&StripHopByHopHeaders{Allow: allow}, middleware.After)
+}
+
+// ID returns the identifier for the StripHopByHopHeaders middleware.
+func (m *StripHopByHopHeaders) ID() string { return "StripHopByHopHeaders" }
+
+// HandleBuild removes the hop-by-hop headers from the request.
+func (m *StripHopByHopHeaders) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	allowed := make(map[string]struct{}, len(m.Allow))
+	for _, name := range m.Allow {
+		allowed[http.CanonicalHeaderKey(name)] = struct{}{}
+	}
+
+	remove := func(name string) {
+		if _, ok := allowed[http.CanonicalHeaderKey(name)]; !ok {
+			req.Header.Del(name)
+		}
+	}
+
+	// Headers listed by the Connection header are also hop-by-hop.
+	for _, v := range req.Header.Values("Connection") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); len(name) != 0 {
+				remove(name)
+			}
+		}
+	}
+
+	for _, name := range hopByHopHeaders {
+		remove(name)
+	}
+
+	for name := range req.Header {
+		if strings.HasPrefix(name, "Proxy-") {
+			remove(name)
+		}
+	}
+
+	return next.HandleBuild(ctx, in)
+}
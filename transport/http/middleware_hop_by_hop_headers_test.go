@@ -0,0 +1,92 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestStripHopByHopHeaders(t *testing.T) {
+	cases := map[string]struct {
+		Allow  []string
+		Header http.Header
+		Expect http.Header
+	}{
+		"no hop-by-hop headers": {
+			Header: http.Header{
+				"Content-Type": []string{"application/json"},
+			},
+			Expect: http.Header{
+				"Content-Type": []string{"application/json"},
+			},
+		},
+		"strips hop-by-hop headers": {
+			Header: http.Header{
+				"Connection":          []string{"keep-alive"},
+				"Keep-Alive":          []string{"timeout=5"},
+				"Proxy-Authorization": []string{"Basic abc"},
+				"Proxy-Connection":    []string{"keep-alive"},
+				"Te":                  []string{"trailers"},
+				"Trailer":             []string{"Expires"},
+				"Transfer-Encoding":   []string{"chunked"},
+				"Upgrade":             []string{"h2c"},
+				"Content-Type":        []string{"application/json"},
+				"X-Amz-Foo":           []string{"bar"},
+			},
+			Expect: http.Header{
+				"Content-Type": []string{"application/json"},
+				"X-Amz-Foo":    []string{"bar"},
+			},
+		},
+		"strips headers named by connection": {
+			Header: http.Header{
+				"Connection": []string{"x-hop, X-Other"},
+				"X-Hop":      []string{"a"},
+				"X-Other":    []string{"b"},
+				"X-Keep":     []string{"c"},
+			},
+			Expect: http.Header{
+				"X-Keep": []string{"c"},
+			},
+		},
+		"allowed headers": {
+			Allow: []string{"upgrade", "Proxy-Authorization"},
+			Header: http.Header{
+				"Connection":          []string{"Upgrade"},
+				"Upgrade":             []string{"h2c"},
+				"Proxy-Authorization": []string{"Basic abc"},
+				"Proxy-Connection":    []string{"keep-alive"},
+			},
+			Expect: http.Header{
+				"Upgrade":             []string{"h2c"},
+				"Proxy-Authorization": []string{"Basic abc"},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			req.Header = c.Header
+
+			m := StripHopByHopHeaders{Allow: c.Allow}
+			_, _, err := m.HandleBuild(context.Background(),
+				middleware.BuildInput{Request: req},
+				middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+					out middleware.BuildOutput, metadata middleware.Metadata, err error,
+				) {
+					if e, a := c.Expect, in.Request.(*Request).Header; !reflect.DeepEqual(e, a) {
+						t.Errorf("expect %v headers, got %v", e, a)
+					}
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+		})
+	}
+}
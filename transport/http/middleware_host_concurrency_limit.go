@@ -0,0 +1,88 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// HostConcurrencyLimit provides a finalize middleware that limits how many
+// requests may be in flight concurrently to each destination host. Requests
+// beyond the limit wait until an in-flight request to the same host completes,
+// or the Context is canceled. Requests to different hosts do not wait on each
+// other.
+//
+// A request is in flight until the response has been deserialized. A single
+// HostConcurrencyLimit should be shared by all operation stacks of a client,
+// so that the limit applies client-wide. A semaphore is retained for each host
+// the client has sent a request to.
+type HostConcurrencyLimit struct {
+	limit int
+
+	mu   sync.Mutex
+	sems map[string]chan struct{}
+}
+
+// NewHostConcurrencyLimit returns an initialized HostConcurrencyLimit
+// middleware permitting up to limit concurrent requests per host. Returns an
+// error if limit is not greater than zero.
+func NewHostConcurrencyLimit(limit int) (*HostConcurrencyLimit, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("host concurrency limit must be greater than zero, got %v", limit)
+	}
+	return &HostConcurrencyLimit{
+		limit: limit,
+		sems:  map[string]chan struct{}{},
+	}, nil
+}
+
+// AddHostConcurrencyLimitMiddleware adds the HostConcurrencyLimit middleware
+// to the end of the stack's Finalize step, so that each attempt of the
+// operation is limited.
+func AddHostConcurrencyLimitMiddleware(stack *middleware.Stack, m *HostConcurrencyLimit) error {
+	return stack.Finalize.Add(m, middleware.After)
+}
+
+// ID returns the identifier for the HostConcurrencyLimit middleware.
+func (*HostConcurrencyLimit) ID() string { return "HostConcurrencyLimit" }
+
+// HandleFinalize waits for the request's host concurrency limit to permit the
+// request.
+func (m *HostConcurrencyLimit) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	host := strings.ToLower(req.URL.Host)
+	sem := m.semaphore(host)
+
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return out, metadata, fmt.Errorf("failed waiting for host concurrency limit of %v, %w",
+			host, ctx.Err())
+	}
+	defer func() { <-sem }()
+
+	return next.HandleFinalize(ctx, in)
+}
+
+func (m *HostConcurrencyLimit) semaphore(host string) chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sem, ok := m.sems[host]
+	if !ok {
+		sem = make(chan struct{}, m.limit)
+		m.sems[host] = sem
+	}
+	return sem
+}
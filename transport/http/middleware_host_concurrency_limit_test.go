@@ -0,0 +1,125 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestHostConcurrencyLimit(t *testing.T) {
+	const (
+		limit    = 2
+		requests = 6
+	)
+
+	m, err := NewHostConcurrencyLimit(limit)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	hosts := []string{"a.example.com", "b.example.com"}
+
+	var mu sync.Mutex
+	inflight := map[string]int{}
+	maxInflight := map[string]int{}
+	track := func(host string, delta int) {
+		mu.Lock()
+		defer mu.Unlock()
+		inflight[host] += delta
+		if inflight[host] > maxInflight[host] {
+			maxInflight[host] = inflight[host]
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, host := range hosts {
+		for i := 0; i < requests; i++ {
+			wg.Add(1)
+			go func(host string) {
+				defer wg.Done()
+
+				req := NewStackRequest().(*Request)
+				req.URL, _ = url.Parse("https://" + host + "/path")
+
+				_, _, err := m.HandleFinalize(context.Background(),
+					middleware.FinalizeInput{Request: req},
+					middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+						out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+					) {
+						track(host, 1)
+						defer track(host, -1)
+						time.Sleep(10 * time.Millisecond)
+						return out, metadata, nil
+					}),
+				)
+				if err != nil {
+					t.Errorf("expect no error, got %v", err)
+				}
+			}(host)
+		}
+	}
+	wg.Wait()
+
+	for _, host := range hosts {
+		if e, a := limit, maxInflight[host]; e != a {
+			t.Errorf("expect %v max in-flight requests to %v, got %v", e, host, a)
+		}
+	}
+}
+
+func TestHostConcurrencyLimit_ContextCanceled(t *testing.T) {
+	m, err := NewHostConcurrencyLimit(1)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	newRequest := func() *Request {
+		req := NewStackRequest().(*Request)
+		req.URL, _ = url.Parse("https://example.com/path")
+		return req
+	}
+
+	release := make(chan struct{})
+	acquired := make(chan struct{})
+	go func() {
+		m.HandleFinalize(context.Background(),
+			middleware.FinalizeInput{Request: newRequest()},
+			middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+				out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+			) {
+				close(acquired)
+				<-release
+				return out, metadata, nil
+			}),
+		)
+	}()
+	defer close(release)
+	<-acquired
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, _, err = m.HandleFinalize(ctx,
+		middleware.FinalizeInput{Request: newRequest()},
+		middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+			out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+		) {
+			t.Errorf("expect request not to be sent")
+			return out, metadata, nil
+		}),
+	)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expect deadline exceeded error, got %v", err)
+	}
+}
+
+func TestNewHostConcurrencyLimit_Invalid(t *testing.T) {
+	if _, err := NewHostConcurrencyLimit(0); err == nil {
+		t.Fatalf("expect error, got none")
+	}
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"context"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// HTTPProtocol is the HTTP protocol version of a response, (e.g. "HTTP/2.0").
+type HTTPProtocol struct {
+	Proto      string
+	ProtoMajor int
+	ProtoMinor int
+}
+
+type httpProtocolKey struct{}
+
+// GetHTTPProtocol returns the HTTP protocol version of the operation's
+// response, recorded by the RecordHTTPProtocol middleware. Returns false if
+// the protocol was not recorded, (e.g. no response was received).
+func GetHTTPProtocol(metadata middleware.Metadata) (HTTPProtocol, bool) {
+	v, ok := metadata.Get(httpProtocolKey{}).(HTTPProtocol)
+	return v, ok
+}
+
+// RecordHTTPProtocol provides a deserialize middleware that records the HTTP
+// protocol version of the response in the operation's metadata, retrievable
+// with GetHTTPProtocol. Useful for debugging behavior that differs between
+// HTTP/1.1 and HTTP/2.
+type RecordHTTPProtocol struct{}
+
+// AddRecordHTTPProtocolMiddleware adds the RecordHTTPProtocol middleware to
+// the end of the stack's Deserialize step.
+func AddRecordHTTPProtocolMiddleware(stack *middleware.Stack) error {
+	return stack.Deserialize.Add(&RecordHTTPProtocol{}, middleware.After)
+}
+
+// ID returns the identifier for the RecordHTTPProtocol middleware.
+func (*RecordHTTPProtocol) ID() string { return "RecordHTTPProtocol" }
+
+// HandleDeserialize records the protocol version of the response returned by
+// the next handler.
+func (*RecordHTTPProtocol) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+
+	if resp, ok := out.RawResponse.(*Response); ok && resp != nil && resp.Response != nil {
+		metadata.Set(httpProtocolKey{}, HTTPProtocol{
+			Proto:      resp.Proto,
+			ProtoMajor: resp.ProtoMajor,
+			ProtoMinor: resp.ProtoMinor,
+		})
+	}
+
+	return out, metadata, err
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestRecordHTTPProtocol(t *testing.T) {
+	cases := map[string]struct {
+		Response *http.Response
+		Err      error
+		Expect   HTTPProtocol
+		ExpectOK bool
+	}{
+		"HTTP/1.1": {
+			Response: &http.Response{Proto: "HTTP/1.1", ProtoMajor: 1, ProtoMinor: 1},
+			Expect:   HTTPProtocol{Proto: "HTTP/1.1", ProtoMajor: 1, ProtoMinor: 1},
+			ExpectOK: true,
+		},
+		"HTTP/2": {
+			Response: &http.Response{Proto: "HTTP/2.0", ProtoMajor: 2},
+			Expect:   HTTPProtocol{Proto: "HTTP/2.0", ProtoMajor: 2},
+			ExpectOK: true,
+		},
+		"error response": {
+			Response: &http.Response{StatusCode: 500, Proto: "HTTP/2.0", ProtoMajor: 2},
+			Err:      fmt.Errorf("internal failure"),
+			Expect:   HTTPProtocol{Proto: "HTTP/2.0", ProtoMajor: 2},
+			ExpectOK: true,
+		},
+		"no response": {
+			Err: fmt.Errorf("connection reset"),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, metadata, err := (&RecordHTTPProtocol{}).HandleDeserialize(context.Background(),
+				middleware.DeserializeInput{},
+				middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					if c.Response != nil {
+						out.RawResponse = &Response{Response: c.Response}
+					}
+					return out, metadata, c.Err
+				}),
+			)
+			if e, a := c.Err, err; e != a {
+				t.Errorf("expect %v error, got %v", e, a)
+			}
+
+			protocol, ok := GetHTTPProtocol(metadata)
+			if e, a := c.ExpectOK, ok; e != a {
+				t.Fatalf("expect recorded %v, got %v", e, a)
+			}
+			if e, a := c.Expect, protocol; e != a {
+				t.Errorf("expect %v protocol, got %v", e, a)
+			}
+		})
+	}
+}
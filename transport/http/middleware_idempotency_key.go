@@ -0,0 +1,116 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// IdempotencyKeyValidationOptions provides the constraints the idempotency key
+// header value of a request must meet.
+type IdempotencyKeyValidationOptions struct {
+	// Header is the name of the HTTP header carrying the idempotency key.
+	Header string
+
+	// MinLength is the minimum length of the key. Zero means no minimum.
+	MinLength int
+
+	// MaxLength is the maximum length of the key. Zero means no maximum.
+	MaxLength int
+
+	// Pattern, if set, must match the full key value.
+	Pattern *regexp.Regexp
+}
+
+// InvalidIdempotencyKeyError is returned when the idempotency key provided for
+// a request does not meet the configured constraints.
+type InvalidIdempotencyKeyError struct {
+	Header string
+	Reason string
+}
+
+// Error returns the error message.
+func (e *InvalidIdempotencyKeyError) Error() string {
+	return fmt.Sprintf("invalid idempotency key in %s header, %s", e.Header, e.Reason)
+}
+
+// ValidateIdempotencyKey provides a build middleware that validates the
+// idempotency key header of a request, if present, before the request is
+// sent.
+type ValidateIdempotencyKey struct {
+	options IdempotencyKeyValidationOptions
+
+	// pattern is the options' Pattern anchored to match the full key.
+	pattern *regexp.Regexp
+}
+
+// NewValidateIdempotencyKey returns an initialized ValidateIdempotencyKey
+// middleware validating keys with the options. Returns an error if the header
+// name is not set.
+func NewValidateIdempotencyKey(options IdempotencyKeyValidationOptions) (*ValidateIdempotencyKey, error) {
+	if len(options.Header) == 0 {
+		return nil, fmt.Errorf("idempotency key header name is required")
+	}
+
+	m := &ValidateIdempotencyKey{options: options}
+	if options.Pattern != nil {
+		pattern, err := regexp.Compile(`^(?:` + options.Pattern.String() + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid idempotency key pattern, %w", err)
+		}
+		m.pattern = pattern
+	}
+	return m, nil
+}
+
+// AddValidateIdempotencyKeyMiddleware adds ValidateIdempotencyKey to the
+// middleware stack's Build step.
+func AddValidateIdempotencyKeyMiddleware(stack *middleware.Stack, options IdempotencyKeyValidationOptions) error {
+	m, err := NewValidateIdempotencyKey(options)
+	if err != nil {
+		return err
+	}
+	return stack.Build.Add(m, middleware.After)
+}
+
+// ID returns the middleware identifier.
+func (m *ValidateIdempotencyKey) ID() string { return "ValidateIdempotencyKey" }
+
+// HandleBuild validates the idempotency key header value, if one was provided
+// for the request.
+func (m *ValidateIdempotencyKey) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	for _, v := range req.Header.Values(m.options.Header) {
+		if err := m.validate(v); err != nil {
+			return out, metadata, err
+		}
+	}
+
+	return next.HandleBuild(ctx, in)
+}
+
+func (m *ValidateIdempotencyKey) validate(key string) error {
+	o := m.options
+	switch {
+	case o.MinLength > 0 && len(key) < o.MinLength:
+		return &InvalidIdempotencyKeyError{Header: o.Header,
+			Reason: fmt.Sprintf("length %d is less than minimum %d", len(key), o.MinLength)}
+	case o.MaxLength > 0 && len(key) > o.MaxLength:
+		return &InvalidIdempotencyKeyError{Header: o.Header,
+			Reason: fmt.Sprintf("length %d exceeds maximum %d", len(key), o.MaxLength)}
+	case m.pattern != nil && !m.pattern.MatchString(key):
+		return &InvalidIdempotencyKeyError{Header: o.Header,
+			Reason: fmt.Sprintf("value does not match pattern %q", o.Pattern.String())}
+	}
+	return nil
+}
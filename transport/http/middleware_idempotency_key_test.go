@@ -0,0 +1,117 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+	"github.com/aws/smithy-go/ptr"
+)
+
+func TestValidateIdempotencyKey(t *testing.T) {
+	options := IdempotencyKeyValidationOptions{
+		Header:    "Idempotency-Key",
+		MinLength: 4,
+		MaxLength: 16,
+		Pattern:   regexp.MustCompile(`[A-Za-z0-9-]+`),
+	}
+
+	cases := map[string]struct {
+		Options   *IdempotencyKeyValidationOptions
+		Key       *string
+		ExpectErr string
+	}{
+		"no key": {},
+		"valid key": {
+			Key: ptr.String("abc-123"),
+		},
+		"overlong key": {
+			Key:       ptr.String(strings.Repeat("a", 17)),
+			ExpectErr: "exceeds maximum 16",
+		},
+		"short key": {
+			Key:       ptr.String("abc"),
+			ExpectErr: "less than minimum 4",
+		},
+		"invalid character key": {
+			Key:       ptr.String("abc_123"),
+			ExpectErr: "does not match pattern",
+		},
+		"alternation full match": {
+			Options: &IdempotencyKeyValidationOptions{
+				Header:  "Idempotency-Key",
+				Pattern: regexp.MustCompile(`a|ab`),
+			},
+			Key: ptr.String("ab"),
+		},
+		"alternation partial match": {
+			Options: &IdempotencyKeyValidationOptions{
+				Header:  "Idempotency-Key",
+				Pattern: regexp.MustCompile(`a|ab`),
+			},
+			Key:       ptr.String("abc"),
+			ExpectErr: "does not match pattern",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			if c.Key != nil {
+				req.Header.Set("Idempotency-Key", *c.Key)
+			}
+
+			o := options
+			if c.Options != nil {
+				o = *c.Options
+			}
+			m, err := NewValidateIdempotencyKey(o)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var called bool
+			_, _, err = m.HandleBuild(context.Background(),
+				middleware.BuildInput{Request: req},
+				middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+					out middleware.BuildOutput, metadata middleware.Metadata, err error,
+				) {
+					called = true
+					return out, metadata, nil
+				}),
+			)
+
+			if len(c.ExpectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				var keyErr *InvalidIdempotencyKeyError
+				if !errors.As(err, &keyErr) {
+					t.Fatalf("expect %T error, got %v", keyErr, err)
+				}
+				if e, a := c.ExpectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expect %q in error, got %q", e, a)
+				}
+				if called {
+					t.Errorf("expect next handler not to be called")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if !called {
+				t.Errorf("expect next handler to be called")
+			}
+		})
+	}
+}
+
+func TestNewValidateIdempotencyKey_NoHeader(t *testing.T) {
+	if _, err := NewValidateIdempotencyKey(IdempotencyKeyValidationOptions{}); err == nil {
+		t.Fatalf("expect error, got none")
+	}
+}
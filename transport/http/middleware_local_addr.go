@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http/httptrace"
+	"sync"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+type localAddrKey struct{}
+
+// GetLocalAddr returns the local network address of the connection the
+// operation's request was sent on, recorded by the RecordLocalAddr
+// middleware. Returns nil if the address was not recorded, (e.g. the request
+// failed before a connection was obtained).
+func GetLocalAddr(metadata middleware.Metadata) net.Addr {
+	v, _ := metadata.Get(localAddrKey{}).(net.Addr)
+	return v
+}
+
+// RecordLocalAddr provides a finalize middleware that records the local
+// network address of the connection the request was sent on in the
+// operation's metadata, retrievable with GetLocalAddr. Useful for debugging
+// which network interface requests are sent from on multi-homed hosts.
+//
+// The address is captured with an httptrace.ClientTrace added to the request's
+// Context, for both new and pooled connections. The HTTP client must honor the
+// request's client trace, as the http.Client does.
+type RecordLocalAddr struct{}
+
+// AddRecordLocalAddrMiddleware adds the RecordLocalAddr middleware to the end
+// of the stack's Finalize step.
+func AddRecordLocalAddrMiddleware(stack *middleware.Stack) error {
+	return stack.Finalize.Add(&RecordLocalAddr{}, middleware.After)
+}
+
+// ID returns the identifier for the RecordLocalAddr middleware.
+func (*RecordLocalAddr) ID() string { return "RecordLocalAddr" }
+
+// HandleFinalize records the local address of the connection the request is
+// sent on in the operation's metadata.
+func (*RecordLocalAddr) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	var mu sync.Mutex
+	var localAddr net.Addr
+
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Conn == nil {
+				return
+			}
+			mu.Lock()
+			localAddr = info.Conn.LocalAddr()
+			mu.Unlock()
+		},
+	})
+
+	out, metadata, err = next.HandleFinalize(ctx, in)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if localAddr != nil {
+		metadata.Set(localAddrKey{}, localAddr)
+	}
+
+	return out, metadata, err
+}
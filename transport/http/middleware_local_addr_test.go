@@ -0,0 +1,75 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestRecordLocalAddr(t *testing.T) {
+	var remoteAddrs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remoteAddrs = append(remoteAddrs, r.RemoteAddr)
+	}))
+	defer server.Close()
+
+	handler := NewClientHandler(server.Client())
+
+	// Second request is sent on the pooled connection of the first.
+	for i := 0; i < 2; i++ {
+		req := NewStackRequest().(*Request)
+		req.URL, _ = url.Parse(server.URL)
+
+		_, metadata, err := (&RecordLocalAddr{}).HandleFinalize(context.Background(),
+			middleware.FinalizeInput{Request: req},
+			middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+				out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+			) {
+				resp, metadata, err := handler.Handle(ctx, in.Request)
+				if err != nil {
+					return out, metadata, err
+				}
+				resp.(*Response).Body.Close()
+				out.Result = resp
+				return out, metadata, nil
+			}),
+		)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+
+		addr := GetLocalAddr(metadata)
+		if addr == nil {
+			t.Fatalf("expect local address to be recorded")
+		}
+		if e, a := remoteAddrs[i], addr.String(); e != a {
+			t.Errorf("expect %v local address, got %v", e, a)
+		}
+		if host, _, err := net.SplitHostPort(addr.String()); err != nil || host != "127.0.0.1" {
+			t.Errorf("expect loopback local address, got %v, %v", addr, err)
+		}
+	}
+}
+
+func TestRecordLocalAddr_NoConnection(t *testing.T) {
+	_, metadata, err := (&RecordLocalAddr{}).HandleFinalize(context.Background(),
+		middleware.FinalizeInput{Request: NewStackRequest()},
+		middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+			out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+		) {
+			return out, metadata, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if addr := GetLocalAddr(metadata); addr != nil {
+		t.Errorf("expect no local address, got %v", addr)
+	}
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// TooManyHeadersError is returned by the MaxHeaderCount middleware when the
+// request has more header fields than allowed.
+type TooManyHeadersError struct {
+	Max   int
+	Count int
+}
+
+func (e *TooManyHeadersError) Error() string {
+	return fmt.Sprintf("request has %d header fields, exceeds maximum of %d", e.Count, e.Max)
+}
+
+// RetryableError returns false, since the request will not be changed by a
+// retry attempt.
+func (*TooManyHeadersError) RetryableError() bool { return false }
+
+// MaxHeaderCount provides a build middleware that returns a
+// TooManyHeadersError if the request has more than the maximum number of
+// header fields. Each value of a multi-valued header is counted as an
+// individual header field, as it would be sent on the wire.
+type MaxHeaderCount struct {
+	max int
+}
+
+// NewMaxHeaderCount returns an initialized MaxHeaderCount middleware
+// permitting at most max header fields on a request.
+func NewMaxHeaderCount(max int) *MaxHeaderCount {
+	return &MaxHeaderCount{
+		max: max,
+	}
+}
+
+// AddMaxHeaderCountMiddleware adds the MaxHeaderCount middleware to the end
+// of the stack's Build step.
+func AddMaxHeaderCountMiddleware(stack *middleware.Stack, max int) error {
+	return stack.Build.Add(NewMaxHeaderCount(max), middleware.After)
+}
+
+// ID returns the identifier for the MaxHeaderCount middleware.
+func (*MaxHeaderCount) ID() string { return "MaxHeaderCount" }
+
+// HandleBuild validates the number of header fields of the request.
+func (m *MaxHeaderCount) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	var count int
+	for _, vs := range req.Header {
+		count += len(vs)
+	}
+	if count > m.max {
+		return out, metadata, &TooManyHeadersError{Max: m.max, Count: count}
+	}
+
+	return next.HandleBuild(ctx, in)
+}
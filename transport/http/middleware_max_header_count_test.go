@@ -0,0 +1,79 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestMaxHeaderCount(t *testing.T) {
+	cases := map[string]struct {
+		Headers   int
+		Extra     []string
+		ExpectErr bool
+	}{
+		"under limit": {
+			Headers: 4,
+		},
+		"at limit": {
+			Headers: 5,
+		},
+		"over limit": {
+			Headers:   6,
+			ExpectErr: true,
+		},
+		"multi-value header over limit": {
+			Headers:   4,
+			Extra:     []string{"a", "b"},
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			for i := 0; i < c.Headers; i++ {
+				req.Header.Set("X-Header-"+strconv.Itoa(i), "value")
+			}
+			for _, v := range c.Extra {
+				req.Header.Add("X-Multi", v)
+			}
+
+			var called bool
+			_, _, err := NewMaxHeaderCount(5).HandleBuild(context.Background(),
+				middleware.BuildInput{Request: req},
+				middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+					out middleware.BuildOutput, metadata middleware.Metadata, err error,
+				) {
+					called = true
+					return out, metadata, nil
+				}),
+			)
+			if c.ExpectErr {
+				var tooMany *TooManyHeadersError
+				if !errors.As(err, &tooMany) {
+					t.Fatalf("expect too many headers error, got %v", err)
+				}
+				if e, a := 5, tooMany.Max; e != a {
+					t.Errorf("expect %v max, got %v", e, a)
+				}
+				if e, a := c.Headers+len(c.Extra), tooMany.Count; e != a {
+					t.Errorf("expect %v count, got %v", e, a)
+				}
+				if called {
+					t.Errorf("expect next handler not to be called")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if !called {
+				t.Errorf("expect next handler to be called")
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// OperationDefaults provides a build middleware that sets default headers on
+// the request, registered per operation, for the operation name from the
+// Context. Headers already set on the request are not modified. Operations
+// without registered defaults are not modified.
+//
+// A single OperationDefaults can be shared by all operation stacks of a
+// client.
+type OperationDefaults struct {
+	defaults map[string]map[string]string
+}
+
+// NewOperationDefaults returns an initialized OperationDefaults middleware
+// for the default headers, keyed by operation name, then header name.
+func NewOperationDefaults(defaults map[string]map[string]string) *OperationDefaults {
+	cpy := make(map[string]map[string]string, len(defaults))
+	for operation, headers := range defaults {
+		hcpy := make(map[string]string, len(headers))
+		for k, v := range headers {
+			hcpy[k] = v
+		}
+		cpy[operation] = hcpy
+	}
+
+	return &OperationDefaults{
+		defaults: cpy,
+	}
+}
+
+// AddOperationDefaultsMiddleware adds the OperationDefaults middleware to the
+// end of the stack's Build step.
+func AddOperationDefaultsMiddleware(stack *middleware.Stack, m *OperationDefaults) error {
+	return stack.Build.Add(m, middleware.After)
+}
+
+// ID returns the identifier for the OperationDefaults middleware.
+func (*OperationDefaults) ID() string { return "OperationDefaults" }
+
+// HandleBuild sets the operation's default headers not already set on the
+// request.
+func (m *OperationDefaults) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	headers, ok := m.defaults[middleware.GetOperationName(ctx)]
+	if !ok {
+		return next.HandleBuild(ctx, in)
+	}
+
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	for k, v := range headers {
+		if len(req.Header.Values(k)) != 0 {
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+
+	return next.HandleBuild(ctx, in)
+}
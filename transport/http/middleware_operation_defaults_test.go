@@ -0,0 +1,82 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestOperationDefaults(t *testing.T) {
+	defaults := map[string]map[string]string{
+		"PutObject": {
+			"x-storage-class": "STANDARD",
+			"X-Priority":      "low",
+		},
+		"GetObject": {
+			"X-Cache": "enabled",
+		},
+	}
+
+	cases := map[string]struct {
+		Operation string
+		Headers   http.Header
+		Expect    http.Header
+	}{
+		"matching operation": {
+			Operation: "PutObject",
+			Headers:   http.Header{},
+			Expect: http.Header{
+				"X-Storage-Class": {"STANDARD"},
+				"X-Priority":      {"low"},
+			},
+		},
+		"caller set value": {
+			Operation: "PutObject",
+			Headers: http.Header{
+				"X-Priority": {"high"},
+			},
+			Expect: http.Header{
+				"X-Storage-Class": {"STANDARD"},
+				"X-Priority":      {"high"},
+			},
+		},
+		"other operation": {
+			Operation: "DeleteObject",
+			Headers: http.Header{
+				"X-Foo": {"bar"},
+			},
+			Expect: http.Header{
+				"X-Foo": {"bar"},
+			},
+		},
+	}
+
+	m := NewOperationDefaults(defaults)
+	// modifying the registry after construction does not affect the middleware.
+	defaults["PutObject"]["X-Priority"] = "modified"
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			req.Header = c.Headers
+
+			ctx := middleware.WithOperationName(context.Background(), c.Operation)
+			_, _, err := m.HandleBuild(ctx, middleware.BuildInput{Request: req},
+				middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+					out middleware.BuildOutput, metadata middleware.Metadata, err error,
+				) {
+					if e, a := c.Expect, in.Request.(*Request).Header; !reflect.DeepEqual(e, a) {
+						t.Errorf("expect %v headers, got %v", e, a)
+					}
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// OriginTag provides a build middleware that tags requests with an origin
+// identifier header, allowing services to filter requests by the client
+// deployment they originated from. An origin header already set on the
+// request is not modified.
+type OriginTag struct {
+	header string
+	origin string
+}
+
+// NewOriginTag returns an initialized OriginTag middleware setting the
+// headerName header of requests to origin.
+func NewOriginTag(headerName, origin string) *OriginTag {
+	return &OriginTag{
+		header: headerName,
+		origin: origin,
+	}
+}
+
+// AddOriginTagMiddleware adds the OriginTag middleware to the end of the
+// stack's Build step.
+func AddOriginTagMiddleware(stack *middleware.Stack, headerName, origin string) error {
+	return stack.Build.Add(NewOriginTag(headerName, origin), middleware.After)
+}
+
+// ID returns the identifier for the OriginTag middleware.
+func (*OriginTag) ID() string { return "OriginTag" }
+
+// HandleBuild sets the request's origin header, if not already set.
+func (m *OriginTag) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	if len(req.Header.Values(m.header)) == 0 {
+		req.Header.Set(m.header, m.origin)
+	}
+
+	return next.HandleBuild(ctx, in)
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestOriginTag(t *testing.T) {
+	cases := map[string]struct {
+		Existing string
+		Expect   string
+	}{
+		"set": {
+			Expect: "batch-worker",
+		},
+		"preserve existing": {
+			Existing: "web-frontend",
+			Expect:   "web-frontend",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			if len(c.Existing) != 0 {
+				req.Header.Set("X-Request-Origin", c.Existing)
+			}
+
+			_, _, err := NewOriginTag("X-Request-Origin", "batch-worker").HandleBuild(context.Background(),
+				middleware.BuildInput{Request: req},
+				middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+					out middleware.BuildOutput, metadata middleware.Metadata, err error,
+				) {
+					values := in.Request.(*Request).Header.Values("X-Request-Origin")
+					if e, a := 1, len(values); e != a {
+						t.Fatalf("expect %v origin header values, got %v", e, a)
+					}
+					if e, a := c.Expect, values[0]; e != a {
+						t.Errorf("expect %v origin, got %v", e, a)
+					}
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// PathPrefix provides a finalize middleware that prepends a prefix to the
+// request's URL path, (e.g. for a gateway that serves the API under /v2/). The
+// prefix and path are joined with a single slash. The request's query is not
+// modified.
+type PathPrefix struct {
+	prefix, rawPrefix string
+}
+
+// NewPathPrefix returns an initialized PathPrefix middleware prepending
+// prefix to request paths. Leading and trailing slashes of the prefix are
+// optional.
+func NewPathPrefix(prefix string) *PathPrefix {
+	prefix = "/" + strings.Trim(prefix, "/")
+	if prefix == "/" {
+		prefix = ""
+	}
+
+	return &PathPrefix{
+		prefix:    prefix,
+		rawPrefix: (&url.URL{Path: prefix}).EscapedPath(),
+	}
+}
+
+// AddPathPrefixMiddleware adds the PathPrefix middleware to the stack's
+// Finalize step before the "Signing" middleware, if present, so the request is
+// signed with the prefixed path. Otherwise the middleware is added to the
+// front of the step.
+func AddPathPrefixMiddleware(stack *middleware.Stack, prefix string) error {
+	m := NewPathPrefix(prefix)
+	if _, ok := stack.Finalize.Get("Signing"); ok {
+		return stack.Finalize.Insert(m, "Signing", middleware.Before)
+	}
+	return stack.Finalize.Add(m, middleware.Before)
+}
+
+// ID returns the identifier for the PathPrefix middleware.
+func (*PathPrefix) ID() string { return "PathPrefix" }
+
+// HandleFinalize prepends the prefix to the URL path of a copy of the request,
+// so the prefix is not prepended again if the request is retried.
+func (m *PathPrefix) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	if len(m.prefix) != 0 {
+		req = req.Clone()
+		req.URL.Path = joinPathPrefix(m.prefix, req.URL.Path)
+		if len(req.URL.RawPath) != 0 {
+			req.URL.RawPath = joinPathPrefix(m.rawPrefix, req.URL.RawPath)
+		}
+		in.Request = req
+	}
+
+	return next.HandleFinalize(ctx, in)
+}
+
+func joinPathPrefix(prefix, path string) string {
+	return prefix + "/" + strings.TrimPrefix(path, "/")
+}
@@ -0,0 +1,152 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestPathPrefix(t *testing.T) {
+	cases := map[string]struct {
+		Prefix        string
+		URL           string
+		ExpectPath    string
+		ExpectRawPath string
+		ExpectQuery   string
+	}{
+		"leading slash": {
+			Prefix:     "/v2/",
+			URL:        "https://example.com/things/abc",
+			ExpectPath: "/v2/things/abc",
+		},
+		"no leading slash": {
+			Prefix:     "v2",
+			URL:        "https://example.com/things",
+			ExpectPath: "/v2/things",
+		},
+		"path without leading slash": {
+			Prefix:     "/v2",
+			URL:        "things",
+			ExpectPath: "/v2/things",
+		},
+		"empty path": {
+			Prefix:     "/v2/",
+			URL:        "https://example.com",
+			ExpectPath: "/v2/",
+		},
+		"query preserved": {
+			Prefix:      "/api/v2",
+			URL:         "https://example.com/things?a=b&c=d",
+			ExpectPath:  "/api/v2/things",
+			ExpectQuery: "a=b&c=d",
+		},
+		"raw path": {
+			Prefix:        "/v2",
+			URL:           "https://example.com/a%2Fb",
+			ExpectPath:    "/v2/a/b",
+			ExpectRawPath: "/v2/a%2Fb",
+		},
+		"empty prefix": {
+			Prefix:     "/",
+			URL:        "https://example.com/things",
+			ExpectPath: "/things",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			req.URL, _ = url.Parse(c.URL)
+
+			_, _, err := NewPathPrefix(c.Prefix).HandleFinalize(context.Background(),
+				middleware.FinalizeInput{Request: req},
+				middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+					out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+				) {
+					u := in.Request.(*Request).URL
+					if e, a := c.ExpectPath, u.Path; e != a {
+						t.Errorf("expect %v path, got %v", e, a)
+					}
+					if e, a := c.ExpectRawPath, u.RawPath; e != a {
+						t.Errorf("expect %v raw path, got %v", e, a)
+					}
+					if e, a := c.ExpectQuery, u.RawQuery; e != a {
+						t.Errorf("expect %v query, got %v", e, a)
+					}
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAddPathPrefixMiddleware_BeforeSigning(t *testing.T) {
+	stack := middleware.NewStack("test", NewStackRequest)
+	stack.Serialize.Add(middleware.SerializeMiddlewareFunc("endpoint",
+		func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+			out middleware.SerializeOutput, metadata middleware.Metadata, err error,
+		) {
+			in.Request.(*Request).URL, _ = url.Parse("https://example.com/things")
+			return next.HandleSerialize(ctx, in)
+		}), middleware.After)
+
+	// mock retry middleware making two attempts with the same request.
+	stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("Retry",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+			out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+		) {
+			for i := 0; i < 2; i++ {
+				out, metadata, err = next.HandleFinalize(ctx, in)
+			}
+			return out, metadata, err
+		}), middleware.After)
+
+	var signedPaths []string
+	stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("Signing",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+			out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+		) {
+			signedPaths = append(signedPaths, in.Request.(*Request).URL.Path)
+			return next.HandleFinalize(ctx, in)
+		}), middleware.After)
+
+	if err := AddPathPrefixMiddleware(stack, "/v2"); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	handler := middleware.DecorateHandler(middleware.HandlerFunc(
+		func(ctx context.Context, input interface{}) (interface{}, middleware.Metadata, error) {
+			return nil, middleware.Metadata{}, nil
+		}), stack)
+	if _, _, err := handler.Handle(context.Background(), struct{}{}); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if e, a := "[/v2/things /v2/things]", fmt.Sprint(signedPaths); e != a {
+		t.Errorf("expect %v signed paths, got %v", e, a)
+	}
+}
+
+func TestAddPathPrefixMiddleware_NoSigning(t *testing.T) {
+	stack := middleware.NewStack("test", NewStackRequest)
+	stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("other",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+			out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+		) {
+			return next.HandleFinalize(ctx, in)
+		}), middleware.After)
+
+	if err := AddPathPrefixMiddleware(stack, "/v2"); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if e, a := "[PathPrefix other]", fmt.Sprint(stack.Finalize.List()); e != a {
+		t.Errorf("expect %v order, got %v", e, a)
+	}
+}
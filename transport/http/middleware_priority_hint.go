@@ -0,0 +1,69 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// PriorityHint provides a build middleware that sets the RFC 9218 Priority
+// header on the request, hinting to servers that honor it the urgency of the
+// response, and if the response can be processed incrementally.
+type PriorityHint struct {
+	urgency     int
+	incremental bool
+}
+
+// NewPriorityHint returns an initialized PriorityHint middleware. urgency is
+// the RFC 9218 urgency of the request, between 0, the highest priority, and 7,
+// the lowest. Returns an error if the urgency is out of range.
+func NewPriorityHint(urgency int, incremental bool) (*PriorityHint, error) {
+	if urgency < 0 || urgency > 7 {
+		return nil, fmt.Errorf("priority urgency must be between 0 and 7, got %d", urgency)
+	}
+
+	return &PriorityHint{
+		urgency:     urgency,
+		incremental: incremental,
+	}, nil
+}
+
+// AddPriorityHintMiddleware adds the PriorityHint middleware to the end of
+// the stack's Build step. Returns an error if the urgency is out of range.
+func AddPriorityHintMiddleware(stack *middleware.Stack, urgency int, incremental bool) error {
+	m, err := NewPriorityHint(urgency, incremental)
+	if err != nil {
+		return err
+	}
+	return stack.Build.Add(m, middleware.After)
+}
+
+// ID returns the identifier for the PriorityHint middleware.
+func (m *PriorityHint) ID() string { return "PriorityHint" }
+
+// HandleBuild sets the Priority header on the request, replacing any
+// existing value.
+func (m *PriorityHint) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	req.Header.Set("Priority", m.headerValue())
+
+	return next.HandleBuild(ctx, in)
+}
+
+func (m *PriorityHint) headerValue() string {
+	v := "u=" + strconv.Itoa(m.urgency)
+	if m.incremental {
+		v += ", i"
+	}
+	return v
+}
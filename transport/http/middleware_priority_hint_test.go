@@ -0,0 +1,66 @@
+package http
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestPriorityHint(t *testing.T) {
+	cases := map[string]struct {
+		Urgency     int
+		Incremental bool
+		Expect      string
+	}{
+		"highest urgency": {
+			Urgency: 0,
+			Expect:  "u=0",
+		},
+		"default urgency incremental": {
+			Urgency:     3,
+			Incremental: true,
+			Expect:      "u=3, i",
+		},
+		"lowest urgency": {
+			Urgency: 7,
+			Expect:  "u=7",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			m, err := NewPriorityHint(c.Urgency, c.Incremental)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			req := NewStackRequest().(*Request)
+			req.Header.Set("Priority", "u=5")
+
+			_, _, err = m.HandleBuild(context.Background(),
+				middleware.BuildInput{Request: req},
+				middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+					out middleware.BuildOutput, metadata middleware.Metadata, err error,
+				) {
+					r := in.Request.(*Request)
+					if e, a := c.Expect, r.Header.Get("Priority"); e != a {
+						t.Errorf("expect %q priority header, got %q", e, a)
+					}
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewPriorityHint_InvalidUrgency(t *testing.T) {
+	for _, urgency := range []int{-1, 8} {
+		if _, err := NewPriorityHint(urgency, false); err == nil {
+			t.Errorf("expect error for urgency %d, got none", urgency)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// defaultMaxRedirects is the number of redirects followed by the http.Client
+// default redirect policy.
+const defaultMaxRedirects = 10
+
+type redirectChainKey struct{}
+
+// GetRedirectChain returns the URLs of the redirects followed by the request,
+// in the order they were followed, recorded by the RedirectChain middleware.
+// The last URL of the chain is the final URL the response was received from.
+// Returns nil if no redirects were followed.
+func GetRedirectChain(metadata middleware.Metadata) []*url.URL {
+	v, _ := metadata.Get(redirectChainKey{}).([]*url.URL)
+	return v
+}
+
+type redirectRecorderKey struct{}
+
+// redirectRecorder records the URLs of the redirects followed by a request
+// attempt.
+type redirectRecorder struct {
+	mu   sync.Mutex
+	urls []*url.URL
+}
+
+func (r *redirectRecorder) record(u *url.URL) {
+	cpy := *u
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.urls = append(r.urls, &cpy)
+}
+
+func (r *redirectRecorder) chain() []*url.URL {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*url.URL(nil), r.urls...)
+}
+
+// RecordRedirectChain returns a redirect policy function for the http.Client
+// CheckRedirect member, or BuildableClient WithCheckRedirect, that records
+// each redirect followed by requests sent with the RedirectChain middleware.
+//
+// The redirect is then checked with checkRedirect. If checkRedirect is nil,
+// the http.Client default policy of following up to 10 redirects is used.
+func RecordRedirectChain(checkRedirect func(req *http.Request, via []*http.Request) error) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if checkRedirect != nil {
+			if err := checkRedirect(req, via); err != nil {
+				return err
+			}
+		} else if len(via) >= defaultMaxRedirects {
+			return errors.New("stopped after 10 redirects")
+		}
+
+		if r, ok := req.Context().Value(redirectRecorderKey{}).(*redirectRecorder); ok {
+			r.record(req.URL)
+		}
+		return nil
+	}
+}
+
+// RedirectChain provides a finalize middleware that records the redirects
+// followed by the request, and stores the chain of redirect URLs in the
+// operation's metadata, retrievable with GetRedirectChain.
+//
+// The redirects are recorded by the HTTP client's redirect policy, which must
+// be wrapped with RecordRedirectChain. e.g.
+//
+//	client := smithyhttp.NewBuildableClient().
+//		WithCheckRedirect(smithyhttp.RecordRedirectChain(nil))
+type RedirectChain struct{}
+
+// AddRedirectChainMiddleware adds the RedirectChain middleware to the end of
+// the stack's Finalize step.
+func AddRedirectChainMiddleware(stack *middleware.Stack) error {
+	return stack.Finalize.Add(&RedirectChain{}, middleware.After)
+}
+
+// ID returns the identifier for the RedirectChain middleware.
+func (*RedirectChain) ID() string { return "RedirectChain" }
+
+// HandleFinalize records the redirects followed by the request.
+func (*RedirectChain) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	recorder := &redirectRecorder{}
+	ctx = context.WithValue(ctx, redirectRecorderKey{}, recorder)
+
+	out, metadata, err = next.HandleFinalize(ctx, in)
+
+	if chain := recorder.chain(); len(chain) != 0 {
+		metadata.Set(redirectChainKey{}, chain)
+	}
+
+	return out, metadata, err
+}
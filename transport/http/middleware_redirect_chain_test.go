@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestRedirectChain(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/hop", http.StatusFound)
+	})
+	mux.HandleFunc("/hop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := NewBuildableClient().
+		WithCheckRedirect(RecordRedirectChain(nil))
+
+	req := NewStackRequest().(*Request)
+	req.URL, _ = url.Parse(server.URL + "/start")
+
+	out, metadata, err := (&RedirectChain{}).HandleFinalize(context.Background(),
+		middleware.FinalizeInput{Request: req},
+		middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+			out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+		) {
+			resp, metadata, err := NewClientHandler(client).Handle(ctx, in.Request)
+			out.Result = resp
+			return out, metadata, err
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	resp := out.Result.(*Response)
+	defer resp.Body.Close()
+
+	chain := GetRedirectChain(metadata)
+	expect := []string{server.URL + "/hop", server.URL + "/final"}
+	if e, a := len(expect), len(chain); e != a {
+		t.Fatalf("expect %v redirects, got %v, %v", e, a, chain)
+	}
+	for i, e := range expect {
+		if a := chain[i].String(); e != a {
+			t.Errorf("expect redirect %d %v, got %v", i, e, a)
+		}
+	}
+
+	if e, a := resp.Request.URL.String(), chain[len(chain)-1].String(); e != a {
+		t.Errorf("expect final URL %v, got %v", e, a)
+	}
+}
+
+func TestRedirectChain_NoRedirect(t *testing.T) {
+	_, metadata, err := (&RedirectChain{}).HandleFinalize(context.Background(),
+		middleware.FinalizeInput{Request: NewStackRequest()},
+		middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+			out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+		) {
+			return out, metadata, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if chain := GetRedirectChain(metadata); chain != nil {
+		t.Errorf("expect no redirect chain, got %v", chain)
+	}
+}
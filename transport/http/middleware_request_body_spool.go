@@ -0,0 +1,81 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// SpoolRequestBody provides a build middleware that buffers request streams
+// that are not seekable, so that they can be read by later middleware, (e.g.
+// to hash or sign the body), and rewound for the request to be sent or
+// retried. Up to MaxMemory bytes of the stream are buffered in memory, larger
+// streams are spooled to a temporary file instead of being held in memory.
+//
+// Seekable request streams do not require buffering, and are not modified.
+// The temporary file, if any, is removed once the operation's handler
+// returns.
+type SpoolRequestBody struct {
+	// MaxMemory is the maximum number of bytes of a request stream buffered
+	// in memory before the stream is spooled to a temporary file.
+	MaxMemory int64
+}
+
+// NewSpoolRequestBody returns an initialized SpoolRequestBody middleware that
+// buffers up to maxMemory bytes of a request stream in memory.
+func NewSpoolRequestBody(maxMemory int64) *SpoolRequestBody {
+	return &SpoolRequestBody{
+		MaxMemory: maxMemory,
+	}
+}
+
+// AddSpoolRequestBodyMiddleware adds the SpoolRequestBody middleware to the
+// end of the stack's Build step. Returns an error if maxMemory is negative.
+func AddSpoolRequestBodyMiddleware(stack *middleware.Stack, maxMemory int64) error {
+	if maxMemory < 0 {
+		return fmt.Errorf("request body max memory must not be negative, got %d", maxMemory)
+	}
+	return stack.Build.Add(NewSpoolRequestBody(maxMemory), middleware.After)
+}
+
+// ID returns the identifier for the SpoolRequestBody middleware.
+func (*SpoolRequestBody) ID() string { return "SpoolRequestBody" }
+
+// HandleBuild replaces a request stream that is not seekable with a seekable
+// copy of the stream, before continuing with the request.
+func (m *SpoolRequestBody) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	stream := req.GetStream()
+	if stream == nil || req.IsStreamSeekable() {
+		return next.HandleBuild(ctx, in)
+	}
+
+	body, err := NewSpooledBody(ioutil.NopCloser(stream), m.MaxMemory)
+	if err != nil {
+		return out, metadata, fmt.Errorf("failed to buffer request stream, %w", err)
+	}
+	defer body.Close()
+
+	req, err = req.SetStream(body)
+	if err != nil {
+		return out, metadata, fmt.Errorf("failed to set request stream, %w", err)
+	}
+	if req.ContentLength < 0 {
+		if err := req.SetContentLength(body.Size()); err != nil {
+			return out, metadata, err
+		}
+	}
+	in.Request = req
+
+	return next.HandleBuild(ctx, in)
+}
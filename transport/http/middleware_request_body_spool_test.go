@@ -0,0 +1,102 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestSpoolRequestBody(t *testing.T) {
+	cases := map[string]struct {
+		Body       io.Reader
+		Expect     string
+		ExpectFile bool
+	}{
+		"small body in memory": {
+			Body:   ioutil.NopCloser(strings.NewReader("hello world")),
+			Expect: "hello world",
+		},
+		"large body spooled to file": {
+			Body:       ioutil.NopCloser(strings.NewReader(strings.Repeat("abcdefgh", 1024))),
+			Expect:     strings.Repeat("abcdefgh", 1024),
+			ExpectFile: true,
+		},
+		"seekable body unmodified": {
+			Body:   strings.NewReader(strings.Repeat("abcdefgh", 1024)),
+			Expect: strings.Repeat("abcdefgh", 1024),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req, err := NewStackRequest().(*Request).SetStream(c.Body)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var spoolName string
+			_, _, err = NewSpoolRequestBody(1024).HandleBuild(context.Background(),
+				middleware.BuildInput{Request: req},
+				middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+					out middleware.BuildOutput, metadata middleware.Metadata, err error,
+				) {
+					r := in.Request.(*Request)
+					if !r.IsStreamSeekable() {
+						t.Fatalf("expect request stream to be seekable")
+					}
+
+					body, isSpooled := r.GetStream().(*SpooledBody)
+					if e, a := c.ExpectFile, isSpooled && body.file != nil; e != a {
+						t.Errorf("expect spooled to file %v, got %v", e, a)
+					}
+					if isSpooled {
+						if e, a := int64(len(c.Expect)), r.ContentLength; e != a {
+							t.Errorf("expect %v content length, got %v", e, a)
+						}
+						if body.file != nil {
+							spoolName = body.file.Name()
+						}
+					}
+
+					expect := sha256.Sum256([]byte(c.Expect))
+					for i := 0; i < 2; i++ {
+						h := sha256.New()
+						if _, err := io.Copy(h, r.GetStream()); err != nil {
+							t.Fatalf("expect no error, got %v", err)
+						}
+						if e, a := expect[:], h.Sum(nil); !bytes.Equal(e, a) {
+							t.Errorf("expect %x hash, got %x", e, a)
+						}
+						if err := r.RewindStream(); err != nil {
+							t.Fatalf("expect no error, got %v", err)
+						}
+					}
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if len(spoolName) != 0 {
+				if _, err := os.Stat(spoolName); !os.IsNotExist(err) {
+					t.Errorf("expect spool file to be removed, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestAddSpoolRequestBodyMiddleware_NegativeMaxMemory(t *testing.T) {
+	stack := middleware.NewStack("test", NewStackRequest)
+	if err := AddSpoolRequestBodyMiddleware(stack, -1); err == nil {
+		t.Fatalf("expect error, got none")
+	}
+}
@@ -0,0 +1,124 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Supported request compression algorithms. Only gzip is supported, since
+// other algorithms, (e.g. zstd), are not provided by the standard library.
+const (
+	CompressionAlgorithmGzip = "gzip"
+)
+
+// RequestCompression provides a build middleware that compresses seekable
+// request bodies whose length is at least MinSize bytes. The Content-Encoding
+// header is updated with the algorithm used, and the request's content length
+// is set to the length of the compressed body.
+//
+// Requests without a body, with a body that is not seekable, or with a body
+// that already has a Content-Encoding, (e.g. gzip, or aws-chunked), are not
+// compressed.
+type RequestCompression struct {
+	// Algorithm is the compression algorithm to apply. Only gzip is
+	// supported.
+	Algorithm string
+
+	// MinSize is the minimum body length, in bytes, a request body must be
+	// for it to be compressed.
+	MinSize int64
+}
+
+// NewRequestCompression returns an initialized RequestCompression middleware
+// compressing request bodies of at least minSize bytes with the algorithm.
+// Returns an error if the algorithm is not supported.
+func NewRequestCompression(algorithm string, minSize int64) (*RequestCompression, error) {
+	switch algorithm {
+	case CompressionAlgorithmGzip:
+	default:
+		return nil, fmt.Errorf("unsupported request compression algorithm, %q", algorithm)
+	}
+
+	return &RequestCompression{
+		Algorithm: algorithm,
+		MinSize:   minSize,
+	}, nil
+}
+
+// AddRequestCompressionMiddleware adds the RequestCompression middleware to
+// the stack's Build step. Returns an error if the algorithm is not supported.
+func AddRequestCompressionMiddleware(stack *middleware.Stack, algorithm string, minSize int64) error {
+	m, err := NewRequestCompression(algorithm, minSize)
+	if err != nil {
+		return err
+	}
+	return stack.Build.Add(m, middleware.After)
+}
+
+// ID returns the identifier for the RequestCompression middleware.
+func (m *RequestCompression) ID() string { return "RequestCompression" }
+
+// HandleBuild compresses the request body if it is seekable, at least MinSize
+// bytes, and not already encoded.
+func (m *RequestCompression) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	stream := req.GetStream()
+	if stream == nil || !req.IsStreamSeekable() {
+		return next.HandleBuild(ctx, in)
+	}
+	if len(req.Header.Values("Content-Encoding")) != 0 {
+		return next.HandleBuild(ctx, in)
+	}
+
+	n, ok, err := req.StreamLength()
+	if err != nil {
+		return out, metadata, fmt.Errorf("failed getting length of request stream, %w", err)
+	}
+	if !ok || n < m.MinSize {
+		return next.HandleBuild(ctx, in)
+	}
+
+	var buf bytes.Buffer
+	w, err := newCompressWriter(m.Algorithm, &buf)
+	if err != nil {
+		return out, metadata, err
+	}
+	if _, err := io.Copy(w, stream); err != nil {
+		return out, metadata, fmt.Errorf("failed to compress request stream, %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return out, metadata, fmt.Errorf("failed to compress request stream, %w", err)
+	}
+
+	req, err = req.SetStream(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return out, metadata, fmt.Errorf("failed to set compressed request stream, %w", err)
+	}
+	req.Header.Set("Content-Encoding", m.Algorithm)
+	req.ContentLength = int64(buf.Len())
+	in.Request = req
+
+	return next.HandleBuild(ctx, in)
+}
+
+func newCompressWriter(algorithm string, w io.Writer) (io.WriteCloser, error) {
+	switch algorithm {
+	case CompressionAlgorithmGzip:
+		return gzip.NewWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unsupported request compression algorithm, %q", algorithm)
+	}
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestRequestCompression(t *testing.T) {
+	largeBody := strings.Repeat("abc123", 100)
+
+	cases := map[string]struct {
+		Body             string
+		ContentEncoding  string
+		MinSize          int64
+		ExpectCompressed bool
+	}{
+		"large body": {
+			Body:             largeBody,
+			MinSize:          128,
+			ExpectCompressed: true,
+		},
+		"small body": {
+			Body:    "abc123",
+			MinSize: 128,
+		},
+		"empty body": {
+			MinSize: 0,
+		},
+		"already encoded": {
+			Body:            largeBody,
+			ContentEncoding: "aws-chunked",
+			MinSize:         128,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			if len(c.ContentEncoding) != 0 {
+				req.Header.Set("Content-Encoding", c.ContentEncoding)
+			}
+			if len(c.Body) != 0 {
+				var err error
+				req, err = req.SetStream(strings.NewReader(c.Body))
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+			}
+
+			m, err := NewRequestCompression(CompressionAlgorithmGzip, c.MinSize)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			var updated *Request
+			_, _, err = m.HandleBuild(context.Background(),
+				middleware.BuildInput{Request: req},
+				middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+					out middleware.BuildOutput, metadata middleware.Metadata, err error,
+				) {
+					updated = in.Request.(*Request)
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if !c.ExpectCompressed {
+				if e, a := c.ContentEncoding, strings.Join(updated.Header.Values("Content-Encoding"), ","); e != a {
+					t.Errorf("expect %q content encoding, got %q", e, a)
+				}
+				if updated.GetStream() == nil {
+					return
+				}
+				actual, err := ioutil.ReadAll(updated.GetStream())
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				if e, a := c.Body, string(actual); e != a {
+					t.Errorf("expect body to be unchanged, got %v", a)
+				}
+				return
+			}
+
+			if e, a := "gzip", updated.Header.Get("Content-Encoding"); e != a {
+				t.Errorf("expect %v content encoding, got %v", e, a)
+			}
+
+			compressed, err := ioutil.ReadAll(updated.GetStream())
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := int64(len(compressed)), updated.ContentLength; e != a {
+				t.Errorf("expect %v content length, got %v", e, a)
+			}
+			if int64(len(compressed)) >= int64(len(c.Body)) {
+				t.Errorf("expect compressed body to be smaller than %v, got %v", len(c.Body), len(compressed))
+			}
+
+			r, err := gzip.NewReader(bytes.NewReader(compressed))
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			actual, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.Body, string(actual); e != a {
+				t.Errorf("expect decompressed body to match, got %v", a)
+			}
+		})
+	}
+}
+
+func TestAddRequestCompressionMiddleware_UnsupportedAlgorithm(t *testing.T) {
+	stack := middleware.NewStack("stack", NewStackRequest)
+	err := AddRequestCompressionMiddleware(stack, "zstd", 0)
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+	if e, a := "unsupported request compression algorithm", err.Error(); !strings.Contains(a, e) {
+		t.Errorf("expect %q in error, got %q", e, a)
+	}
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DefaultRequestSequenceHeader is the default header the RequestSequence
+// middleware sets.
+const DefaultRequestSequenceHeader = "X-Request-Sequence"
+
+// RequestSequence provides a build middleware that assigns each operation
+// invocation an increasing sequence number, and sets it as a header of the
+// request. The first operation is assigned the number 1. Since the Build step
+// is invoked once per operation, all attempts of the operation share the same
+// sequence number.
+//
+// A single RequestSequence should be shared by all operation stacks of a
+// client, so that the sequence is per client. Safe for concurrent use.
+type RequestSequence struct {
+	// seq must be first for 64-bit alignment of atomic operations.
+	seq    uint64
+	header string
+}
+
+// NewRequestSequence returns an initialized RequestSequence middleware
+// setting the header. Defaults to DefaultRequestSequenceHeader if header is
+// empty.
+func NewRequestSequence(header string) *RequestSequence {
+	if len(header) == 0 {
+		header = DefaultRequestSequenceHeader
+	}
+	return &RequestSequence{header: header}
+}
+
+// AddRequestSequenceMiddleware adds the RequestSequence middleware to the end
+// of the stack's Build step.
+func AddRequestSequenceMiddleware(stack *middleware.Stack, m *RequestSequence) error {
+	return stack.Build.Add(m, middleware.After)
+}
+
+// ID returns the identifier for the RequestSequence middleware.
+func (m *RequestSequence) ID() string { return "RequestSequence" }
+
+// HandleBuild assigns the operation the next sequence number, and sets it as
+// the request's header.
+func (m *RequestSequence) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	seq := atomic.AddUint64(&m.seq, 1)
+	req.Header.Set(m.header, strconv.FormatUint(seq, 10))
+
+	return next.HandleBuild(ctx, in)
+}
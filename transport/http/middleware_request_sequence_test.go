@@ -0,0 +1,75 @@
+package http
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestRequestSequence(t *testing.T) {
+	m := NewRequestSequence("")
+
+	const operations = 100
+
+	var mu sync.Mutex
+	seen := map[uint64]struct{}{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < operations; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, _, err := m.HandleBuild(context.Background(),
+				middleware.BuildInput{Request: NewStackRequest()},
+				middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+					out middleware.BuildOutput, metadata middleware.Metadata, err error,
+				) {
+					v := in.Request.(*Request).Header.Get(DefaultRequestSequenceHeader)
+					seq, err := strconv.ParseUint(v, 10, 64)
+					if err != nil {
+						return out, metadata, err
+					}
+
+					mu.Lock()
+					defer mu.Unlock()
+					if _, ok := seen[seq]; ok {
+						t.Errorf("expect unique sequence number, %v already assigned", seq)
+					}
+					seen[seq] = struct{}{}
+
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Errorf("expect no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for seq := uint64(1); seq <= operations; seq++ {
+		if _, ok := seen[seq]; !ok {
+			t.Errorf("expect sequence number %v to be assigned", seq)
+		}
+	}
+
+	// Subsequent operations continue the sequence.
+	req := NewStackRequest().(*Request)
+	_, _, err := m.HandleBuild(context.Background(), middleware.BuildInput{Request: req},
+		middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+			out middleware.BuildOutput, metadata middleware.Metadata, err error,
+		) {
+			return out, metadata, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := strconv.Itoa(operations+1), req.Header.Get(DefaultRequestSequenceHeader); e != a {
+		t.Errorf("expect %v sequence number, got %v", e, a)
+	}
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DefaultSigningMiddlewareIDs are the identifiers of the Finalize step
+// signing middleware that AddRequireHTTPSSigningMiddleware looks for if no
+// identifiers are provided.
+var DefaultSigningMiddlewareIDs = []string{
+	"Signing",
+	"BearerTokenAuthentication",
+}
+
+type insecureSigningAllowedKey struct{}
+
+// WithInsecureSigningAllowed returns a copy of the Context with whether
+// requests may be signed when sent over plaintext HTTP, overriding the
+// RequireHTTPSSigning middleware. Allowing insecure signing may leak the
+// request's credentials, and should only be used for local testing, (e.g. with
+// a local service emulator).
+func WithInsecureSigningAllowed(ctx context.Context, allowed bool) context.Context {
+	return context.WithValue(ctx, insecureSigningAllowedKey{}, allowed)
+}
+
+// GetInsecureSigningAllowed returns if requests may be signed when sent over
+// plaintext HTTP, set on the Context with WithInsecureSigningAllowed.
+func GetInsecureSigningAllowed(ctx context.Context) bool {
+	v, _ := ctx.Value(insecureSigningAllowedKey{}).(bool)
+	return v
+}
+
+// InsecureSigningError is returned by the RequireHTTPSSigning middleware when
+// a request that would be signed is not sent over HTTPS.
+type InsecureSigningError struct {
+	Scheme string
+}
+
+func (e *InsecureSigningError) Error() string {
+	return fmt.Sprintf("refusing to sign request with %q scheme, signed requests require HTTPS", e.Scheme)
+}
+
+// RetryableError returns false, since the request's scheme will not be
+// changed by a retry attempt.
+func (*InsecureSigningError) RetryableError() bool { return false }
+
+// RequireHTTPSSigning provides a finalize middleware that returns an
+// InsecureSigningError if the request is not sent over HTTPS, preventing the
+// request's credentials from being sent in plaintext. The check is skipped if
+// insecure signing is allowed with WithInsecureSigningAllowed.
+//
+// Use AddRequireHTTPSSigningMiddleware to add the middleware only to stacks
+// that sign requests.
+type RequireHTTPSSigning struct{}
+
+// AddRequireHTTPSSigningMiddleware adds the RequireHTTPSSigning middleware to
+// the stack's Finalize step before the first signing middleware found with
+// one of the signingIDs. If no signingIDs are provided,
+// DefaultSigningMiddlewareIDs are used. The stack is not modified if it has
+// no signing middleware, since unsigned requests do not leak credentials.
+//
+// Must be called after the signing middleware is added to the stack.
+func AddRequireHTTPSSigningMiddleware(stack *middleware.Stack, signingIDs ...string) error {
+	if len(signingIDs) == 0 {
+		signingIDs = DefaultSigningMiddlewareIDs
+	}
+
+	for _, id := range stack.Finalize.List() {
+		for _, signingID := range signingIDs {
+			if id == signingID {
+				return stack.Finalize.Insert(&RequireHTTPSSigning{}, id, middleware.Before)
+			}
+		}
+	}
+	return nil
+}
+
+// ID returns the identifier for the RequireHTTPSSigning middleware.
+func (*RequireHTTPSSigning) ID() string { return "RequireHTTPSSigning" }
+
+// HandleFinalize returns an error if the request is not sent over HTTPS,
+// unless insecure signing is allowed.
+func (*RequireHTTPSSigning) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	if !req.IsHTTPS() && !GetInsecureSigningAllowed(ctx) {
+		var scheme string
+		if req.URL != nil {
+			scheme = req.URL.Scheme
+		}
+		return out, metadata, &InsecureSigningError{Scheme: scheme}
+	}
+
+	return next.HandleFinalize(ctx, in)
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestRequireHTTPSSigning(t *testing.T) {
+	cases := map[string]struct {
+		URL           string
+		NoSigning     bool
+		AllowInsecure bool
+		ExpectErr     bool
+		ExpectSigned  bool
+	}{
+		"https signed": {
+			URL:          "https://example.com/path",
+			ExpectSigned: true,
+		},
+		"http signed": {
+			URL:       "http://example.com/path",
+			ExpectErr: true,
+		},
+		"http signed insecure allowed": {
+			URL:           "http://localhost:8000/path",
+			AllowInsecure: true,
+			ExpectSigned:  true,
+		},
+		"http not signed": {
+			URL:       "http://example.com/path",
+			NoSigning: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var signed bool
+			stack := middleware.NewStack("test", NewStackRequest)
+			stack.Serialize.Add(middleware.SerializeMiddlewareFunc("endpoint",
+				func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+					out middleware.SerializeOutput, metadata middleware.Metadata, err error,
+				) {
+					in.Request.(*Request).URL, _ = url.Parse(c.URL)
+					return next.HandleSerialize(ctx, in)
+				}), middleware.After)
+			if !c.NoSigning {
+				stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("Signing",
+					func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+						out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+					) {
+						signed = true
+						return next.HandleFinalize(ctx, in)
+					}), middleware.After)
+			}
+
+			if err := AddRequireHTTPSSigningMiddleware(stack); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			_, added := stack.Finalize.Get("RequireHTTPSSigning")
+			if e, a := !c.NoSigning, added; e != a {
+				t.Fatalf("expect middleware added %v, got %v", e, a)
+			}
+
+			handler := middleware.DecorateHandler(middleware.HandlerFunc(
+				func(ctx context.Context, input interface{}) (interface{}, middleware.Metadata, error) {
+					return nil, middleware.Metadata{}, nil
+				}), stack)
+
+			ctx := context.Background()
+			if c.AllowInsecure {
+				ctx = WithInsecureSigningAllowed(ctx, true)
+			}
+
+			_, _, err := handler.Handle(ctx, struct{}{})
+			if c.ExpectErr {
+				var insecureErr *InsecureSigningError
+				if !errors.As(err, &insecureErr) {
+					t.Fatalf("expect insecure signing error, got %v", err)
+				}
+				if e, a := "http", insecureErr.Scheme; e != a {
+					t.Errorf("expect %v scheme, got %v", e, a)
+				}
+			} else if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.ExpectSigned, signed; e != a {
+				t.Errorf("expect signed %v, got %v", e, a)
+			}
+		})
+	}
+}
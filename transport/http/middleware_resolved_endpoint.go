@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+type resolvedEndpointKey struct{}
+
+// GetResolvedEndpoint returns the URL the operation's request was sent to,
+// recorded by the ResolvedEndpoint middleware. Returns nil if the endpoint was
+// not recorded.
+func GetResolvedEndpoint(metadata middleware.Metadata) *url.URL {
+	v, _ := metadata.Get(resolvedEndpointKey{}).(*url.URL)
+	return v
+}
+
+// ResolvedEndpoint provides a finalize middleware that records the URL of the
+// request sent in the operation's metadata, retrievable with
+// GetResolvedEndpoint. The URL reflects the endpoint after it was resolved,
+// and modified by any middleware before ResolvedEndpoint, (e.g. host
+// prefixing).
+type ResolvedEndpoint struct{}
+
+// AddResolvedEndpointMiddleware adds the ResolvedEndpoint middleware to the
+// end of the stack's Finalize step, so that the URL is recorded after all
+// other modifications of the request.
+func AddResolvedEndpointMiddleware(stack *middleware.Stack) error {
+	return stack.Finalize.Add(&ResolvedEndpoint{}, middleware.After)
+}
+
+// ID returns the identifier for the ResolvedEndpoint middleware.
+func (*ResolvedEndpoint) ID() string { return "ResolvedEndpoint" }
+
+// HandleFinalize records the request's URL in the operation's metadata.
+func (*ResolvedEndpoint) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	var endpoint *url.URL
+	if req.URL != nil {
+		u := *req.URL
+		if u.User != nil {
+			user := *u.User
+			u.User = &user
+		}
+		endpoint = &u
+	}
+
+	out, metadata, err = next.HandleFinalize(ctx, in)
+	if endpoint != nil {
+		metadata.Set(resolvedEndpointKey{}, endpoint)
+	}
+
+	return out, metadata, err
+}
@@ -0,0 +1,67 @@
+package http
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestResolvedEndpoint(t *testing.T) {
+	stack := middleware.NewStack("stack", NewStackRequest)
+
+	// mock endpoint resolution and rewriting middleware.
+	err := stack.Serialize.Add(middleware.SerializeMiddlewareFunc("ResolveEndpoint",
+		func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+			out middleware.SerializeOutput, metadata middleware.Metadata, err error,
+		) {
+			req := in.Request.(*Request)
+			req.URL, _ = url.Parse("https://service.us-west-2.example.com/path?k=v")
+			return next.HandleSerialize(ctx, in)
+		}), middleware.After)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	err = stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("HostPrefix",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+			out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+		) {
+			req := in.Request.(*Request)
+			req.URL.Host = "prefix." + req.URL.Host
+			return next.HandleFinalize(ctx, in)
+		}), middleware.After)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if err := AddResolvedEndpointMiddleware(stack); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	var sentURL string
+	handler := middleware.DecorateHandler(middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+		output interface{}, metadata middleware.Metadata, err error,
+	) {
+		req := input.(*Request)
+		sentURL = req.URL.String()
+		// modifications of the request after it was sent are not recorded.
+		req.URL.Host = "modified.example.com"
+		return nil, metadata, nil
+	}), stack)
+
+	_, metadata, err := handler.Handle(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	endpoint := GetResolvedEndpoint(metadata)
+	if endpoint == nil {
+		t.Fatalf("expect resolved endpoint")
+	}
+	if e, a := "https://prefix.service.us-west-2.example.com/path?k=v", endpoint.String(); e != a {
+		t.Errorf("expect %v endpoint, got %v", e, a)
+	}
+	if e, a := sentURL, endpoint.String(); e != a {
+		t.Errorf("expect endpoint to match sent URL %v, got %v", e, a)
+	}
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ContentLengthMismatchError is returned when reading or closing a response
+// body whose number of bytes read does not match the response's declared
+// Content-Length, (e.g. a truncated download).
+type ContentLengthMismatchError struct {
+	Expected int64
+	Actual   int64
+}
+
+func (e *ContentLengthMismatchError) Error() string {
+	return fmt.Sprintf("response body length mismatch, expected %d bytes, read %d",
+		e.Expected, e.Actual)
+}
+
+// ValidateResponseContentLength provides a deserialize middleware that
+// verifies the number of bytes read from the response body matches the
+// response's Content-Length. When the body reaches EOF, or is closed, with a
+// different number of bytes read, a ContentLengthMismatchError is returned
+// instead.
+//
+// Responses without a known Content-Length are not validated. Closing the
+// body before it has been read to the end is reported as a mismatch.
+type ValidateResponseContentLength struct{}
+
+// AddValidateResponseContentLengthMiddleware adds the
+// ValidateResponseContentLength middleware to the end of the stack's
+// Deserialize step, so that the response body is wrapped before it is read by
+// the deserializers.
+func AddValidateResponseContentLengthMiddleware(stack *middleware.Stack) error {
+	return stack.Deserialize.Add(&ValidateResponseContentLength{}, middleware.After)
+}
+
+// ID returns the identifier for the ValidateResponseContentLength middleware.
+func (*ValidateResponseContentLength) ID() string { return "ValidateResponseContentLength" }
+
+// HandleDeserialize wraps the response body to validate its length against
+// the response's Content-Length.
+func (m *ValidateResponseContentLength) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok || resp == nil || resp.Body == nil || resp.Body == http.NoBody || resp.ContentLength < 0 {
+		return out, metadata, err
+	}
+
+	resp.Body = &contentLengthReadCloser{
+		ReadCloser: resp.Body,
+		expected:   resp.ContentLength,
+	}
+
+	return out, metadata, err
+}
+
+type contentLengthReadCloser struct {
+	io.ReadCloser
+	expected int64
+	read     int64
+}
+
+func (r *contentLengthReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+
+	if (err == io.EOF && r.read != r.expected) || r.read > r.expected {
+		return n, r.mismatch()
+	}
+
+	return n, err
+}
+
+func (r *contentLengthReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	if err == nil && r.read != r.expected {
+		return r.mismatch()
+	}
+	return err
+}
+
+func (r *contentLengthReadCloser) mismatch() error {
+	return &ContentLengthMismatchError{
+		Expected: r.expected,
+		Actual:   r.read,
+	}
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestValidateResponseContentLength(t *testing.T) {
+	cases := map[string]struct {
+		Body           string
+		ContentLength  int64
+		ExpectErr      bool
+		ExpectExpected int64
+		ExpectActual   int64
+	}{
+		"matching length": {
+			Body:          "hello world",
+			ContentLength: 11,
+		},
+		"truncated body": {
+			Body:           "hello",
+			ContentLength:  11,
+			ExpectErr:      true,
+			ExpectExpected: 11,
+			ExpectActual:   5,
+		},
+		"body longer than declared": {
+			Body:           "hello world",
+			ContentLength:  5,
+			ExpectErr:      true,
+			ExpectExpected: 5,
+			ExpectActual:   11,
+		},
+		"unknown length": {
+			Body:          "hello world",
+			ContentLength: -1,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			resp := invokeValidateResponseContentLength(t, c.Body, c.ContentLength)
+
+			b, err := ioutil.ReadAll(resp.Body)
+			if c.ExpectErr {
+				var mismatch *ContentLengthMismatchError
+				if !errors.As(err, &mismatch) {
+					t.Fatalf("expect mismatch error, got %v", err)
+				}
+				if e, a := c.ExpectExpected, mismatch.Expected; e != a {
+					t.Errorf("expect %v expected length, got %v", e, a)
+				}
+				if e, a := c.ExpectActual, mismatch.Actual; e != a {
+					t.Errorf("expect %v actual length, got %v", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.Body, string(b); e != a {
+				t.Errorf("expect %v body, got %v", e, a)
+			}
+			if err := resp.Body.Close(); err != nil {
+				t.Errorf("expect no close error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateResponseContentLength_CloseBeforeEOF(t *testing.T) {
+	resp := invokeValidateResponseContentLength(t, "hello world", 11)
+
+	p := make([]byte, 5)
+	if _, err := resp.Body.Read(p); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	var mismatch *ContentLengthMismatchError
+	if err := resp.Body.Close(); !errors.As(err, &mismatch) {
+		t.Fatalf("expect mismatch error, got %v", err)
+	}
+	if e, a := int64(5), mismatch.Actual; e != a {
+		t.Errorf("expect %v actual length, got %v", e, a)
+	}
+}
+
+func invokeValidateResponseContentLength(t *testing.T, body string, contentLength int64) *Response {
+	t.Helper()
+
+	out, _, err := (&ValidateResponseContentLength{}).HandleDeserialize(context.Background(),
+		middleware.DeserializeInput{},
+		middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+			out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+		) {
+			out.RawResponse = &Response{Response: &http.Response{
+				StatusCode:    200,
+				ContentLength: contentLength,
+				Body:          ioutil.NopCloser(strings.NewReader(body)),
+			}}
+			return out, metadata, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	return out.RawResponse.(*Response)
+}
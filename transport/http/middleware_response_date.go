@@ -0,0 +1,107 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ResponseClockSkewError is returned by the ValidateResponseDate middleware
+// when the server time of the response's Date header differs from the local
+// clock by more than the maximum skew.
+type ResponseClockSkewError struct {
+	ServerTime time.Time
+	LocalTime  time.Time
+	MaxSkew    time.Duration
+}
+
+func (e *ResponseClockSkewError) Error() string {
+	return fmt.Sprintf("response server time %v skewed from local time %v by %v, exceeds maximum skew %v",
+		e.ServerTime, e.LocalTime, e.Skew(), e.MaxSkew)
+}
+
+// Skew returns the absolute difference between the server and local time.
+func (e *ResponseClockSkewError) Skew() time.Duration {
+	skew := e.LocalTime.Sub(e.ServerTime)
+	if skew < 0 {
+		return -skew
+	}
+	return skew
+}
+
+// ValidateResponseDate provides a deserialize middleware that fails
+// successful attempts whose response Date header is skewed from the local
+// clock by more than MaxSkew, with a ResponseClockSkewError. Useful for time
+// sensitive operations, (e.g. when the response's content is only valid for a
+// limited time).
+//
+// Responses without a Date header, or with a Date header that cannot be
+// parsed, are not validated.
+type ValidateResponseDate struct {
+	// MaxSkew is the maximum difference allowed between the server and local
+	// time.
+	MaxSkew time.Duration
+
+	// Now returns the local time. Defaults to time.Now if nil.
+	Now func() time.Time
+}
+
+// NewValidateResponseDate returns an initialized ValidateResponseDate
+// middleware allowing the maximum skew.
+func NewValidateResponseDate(maxSkew time.Duration) *ValidateResponseDate {
+	return &ValidateResponseDate{MaxSkew: maxSkew}
+}
+
+// AddValidateResponseDateMiddleware adds the ValidateResponseDate middleware
+// to the end of the stack's Deserialize step.
+func AddValidateResponseDateMiddleware(stack *middleware.Stack, maxSkew time.Duration) error {
+	return stack.Deserialize.Add(NewValidateResponseDate(maxSkew), middleware.After)
+}
+
+// ID returns the identifier for the ValidateResponseDate middleware.
+func (*ValidateResponseDate) ID() string { return "ValidateResponseDate" }
+
+// HandleDeserialize compares the Date header of the response returned by the
+// next handler to the local clock.
+func (m *ValidateResponseDate) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok || resp == nil || resp.Response == nil {
+		return out, metadata, nil
+	}
+
+	date := resp.Header.Get("Date")
+	if len(date) == 0 {
+		return out, metadata, nil
+	}
+	serverTime, parseErr := ParseTime(date)
+	if parseErr != nil {
+		return out, metadata, nil
+	}
+
+	now := time.Now
+	if m.Now != nil {
+		now = m.Now
+	}
+
+	skewErr := &ResponseClockSkewError{
+		ServerTime: serverTime,
+		LocalTime:  now(),
+		MaxSkew:    m.MaxSkew,
+	}
+	if skewErr.Skew() > m.MaxSkew {
+		return out, metadata, skewErr
+	}
+
+	return out, metadata, nil
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestValidateResponseDate(t *testing.T) {
+	local := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := map[string]struct {
+		Date       string
+		Err        error
+		ExpectSkew bool
+	}{
+		"in range": {
+			Date: "Thu, 02 Jan 2020 03:08:05 GMT",
+		},
+		"in range behind": {
+			Date: "Thu, 02 Jan 2020 03:00:05 GMT",
+		},
+		"ahead out of range": {
+			Date:       "Thu, 02 Jan 2020 03:10:06 GMT",
+			ExpectSkew: true,
+		},
+		"behind out of range": {
+			Date:       "Thu, 02 Jan 2020 02:00:00 GMT",
+			ExpectSkew: true,
+		},
+		"no date": {},
+		"invalid date": {
+			Date: "not a date",
+		},
+		"error response": {
+			Date: "Thu, 02 Jan 2020 02:00:00 GMT",
+			Err:  errors.New("service error"),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := NewValidateResponseDate(5 * time.Minute)
+			m.Now = func() time.Time { return local }
+
+			_, _, err := m.HandleDeserialize(context.Background(), middleware.DeserializeInput{},
+				middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					resp := &http.Response{StatusCode: 200, Header: http.Header{}}
+					if len(c.Date) != 0 {
+						resp.Header.Set("Date", c.Date)
+					}
+					out.RawResponse = &Response{Response: resp}
+					return out, metadata, c.Err
+				}),
+			)
+
+			var skewErr *ResponseClockSkewError
+			if e, a := c.ExpectSkew, errors.As(err, &skewErr); e != a {
+				t.Fatalf("expect skew error %v, got %v", e, err)
+			}
+			if c.ExpectSkew {
+				if e, a := local, skewErr.LocalTime; !e.Equal(a) {
+					t.Errorf("expect %v local time, got %v", e, a)
+				}
+				return
+			}
+			if e, a := c.Err, err; e != a {
+				t.Errorf("expect %v error, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ResponseDecompression provides a deserialize middleware that decompresses
+// response bodies encoded with a supported Content-Encoding. Only gzip is
+// currently supported. When the body is decompressed the Content-Encoding and
+// Content-Length headers are removed from the response, and the response's
+// content length is set to unknown, -1.
+//
+// Responses with an unsupported, or multiple, content encodings are left
+// unmodified, as are responses that do not have a body, (e.g. responses to
+// HEAD requests, or with status 204 or 304). An empty compressed body is
+// replaced with an empty, uncompressed, body.
+type ResponseDecompression struct {
+	// AllowedContentTypes is the list of media types, (e.g.
+	// "application/json"), of responses that will be decompressed. If empty,
+	// responses of all content types are decompressed. Responses whose
+	// Content-Type is not in the list are left compressed, with their
+	// Content-Encoding header intact.
+	AllowedContentTypes []string
+}
+
+// AddResponseDecompressionMiddleware adds the ResponseDecompression
+// middleware to the end of the stack's Deserialize step, so the body is
+// decompressed before other deserialize middleware read it. Only responses
+// with a content type in allowedContentTypes are decompressed. If no
+// allowedContentTypes are provided, responses of all content types are
+// decompressed.
+func AddResponseDecompressionMiddleware(stack *middleware.Stack, allowedContentTypes ...string) error {
+	return stack.Deserialize.Add(&ResponseDecompression{
+		AllowedContentTypes: allowedContentTypes,
+	}, middleware.After)
+}
+
+// ID returns the identifier for the ResponseDecompression middleware.
+func (m *ResponseDecompression) ID() string { return "ResponseDecompression" }
+
+// HandleDeserialize decompresses the response body if its content encoding
+// is supported, and its content type is allowed.
+func (m *ResponseDecompression) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", out.RawResponse)
+	}
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return out, metadata, err
+	}
+	if req, ok := in.Request.(*Request); ok && IsNoBodyResponse(req.Method, resp.StatusCode) {
+		return out, metadata, err
+	}
+
+	encodings := resp.Header.Values("Content-Encoding")
+	if len(encodings) != 1 || !strings.EqualFold(strings.TrimSpace(encodings[0]), CompressionAlgorithmGzip) {
+		return out, metadata, err
+	}
+	if !m.isContentTypeAllowed(resp.Header.Get("Content-Type")) {
+		return out, metadata, err
+	}
+
+	r, err := gzip.NewReader(resp.Body)
+	if err == io.EOF {
+		// The compressed body is empty, there is nothing to decompress.
+		resp.Body.Close()
+		resp.Body = http.NoBody
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = 0
+		resp.Uncompressed = true
+		return out, metadata, nil
+	}
+	if err != nil {
+		resp.Body.Close()
+		return out, metadata, fmt.Errorf("failed to decompress response body, %w", err)
+	}
+	resp.Body = &gzipReadCloser{Reader: r, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+
+	return out, metadata, nil
+}
+
+func (m *ResponseDecompression) isContentTypeAllowed(contentType string) bool {
+	if len(m.AllowedContentTypes) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range m.AllowedContentTypes {
+		if strings.EqualFold(mediaType, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipReadCloser closes both the gzip reader, and the underlying compressed
+// body when closed.
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.Closer
+}
+
+func (r *gzipReadCloser) Close() error {
+	err := r.Reader.Close()
+	if bodyErr := r.body.Close(); bodyErr != nil {
+		return bodyErr
+	}
+	return err
+}
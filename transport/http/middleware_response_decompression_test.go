@@ -0,0 +1,212 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestResponseDecompression(t *testing.T) {
+	const payload = `{"foo":"bar"}`
+
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	w.Write([]byte(payload))
+	w.Close()
+
+	cases := map[string]struct {
+		Allowed         []string
+		ContentType     string
+		ContentEncoding string
+		ExpectBody      []byte
+		ExpectEncoding  string
+	}{
+		"no allowlist": {
+			ContentType:     "application/octet-stream",
+			ContentEncoding: "gzip",
+			ExpectBody:      []byte(payload),
+		},
+		"allowed content type": {
+			Allowed:         []string{"text/plain", "application/json"},
+			ContentType:     "application/json; charset=utf-8",
+			ContentEncoding: "gzip",
+			ExpectBody:      []byte(payload),
+		},
+		"disallowed content type": {
+			Allowed:         []string{"application/json"},
+			ContentType:     "application/octet-stream",
+			ContentEncoding: "gzip",
+			ExpectBody:      compressed.Bytes(),
+			ExpectEncoding:  "gzip",
+		},
+		"missing content type": {
+			Allowed:         []string{"application/json"},
+			ContentEncoding: "gzip",
+			ExpectBody:      compressed.Bytes(),
+			ExpectEncoding:  "gzip",
+		},
+		"unsupported encoding": {
+			ContentType:     "application/json",
+			ContentEncoding: "br",
+			ExpectBody:      compressed.Bytes(),
+			ExpectEncoding:  "br",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			if len(c.ContentType) != 0 {
+				header.Set("Content-Type", c.ContentType)
+			}
+			header.Set("Content-Encoding", c.ContentEncoding)
+			header.Set("Content-Length", strconv.Itoa(compressed.Len()))
+
+			m := ResponseDecompression{AllowedContentTypes: c.Allowed}
+			out, _, err := m.HandleDeserialize(context.Background(),
+				middleware.DeserializeInput{},
+				middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					out.RawResponse = &Response{Response: &http.Response{
+						StatusCode:    200,
+						Header:        header,
+						ContentLength: int64(compressed.Len()),
+						Body:          ioutil.NopCloser(bytes.NewReader(compressed.Bytes())),
+					}}
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			resp := out.RawResponse.(*Response)
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.ExpectBody, b; !bytes.Equal(e, a) {
+				t.Errorf("expect %q body, got %q", e, a)
+			}
+			if e, a := c.ExpectEncoding, resp.Header.Get("Content-Encoding"); e != a {
+				t.Errorf("expect %q content encoding, got %q", e, a)
+			}
+
+			// The compressed length is stale once the body is decompressed.
+			expectLength := int64(compressed.Len())
+			expectLengthHeader := strconv.Itoa(compressed.Len())
+			if len(c.ExpectEncoding) == 0 {
+				expectLength = -1
+				expectLengthHeader = ""
+			}
+			if e, a := expectLength, resp.ContentLength; e != a {
+				t.Errorf("expect %v content length, got %v", e, a)
+			}
+			if e, a := expectLengthHeader, resp.Header.Get("Content-Length"); e != a {
+				t.Errorf("expect %q content length header, got %q", e, a)
+			}
+		})
+	}
+}
+
+func TestResponseDecompression_NoBody(t *testing.T) {
+	cases := map[string]struct {
+		Method       string
+		StatusCode   int
+		Body         func() io.ReadCloser
+		ExpectErr    bool
+		ExpectClosed bool
+		ExpectNoBody bool
+	}{
+		"no body": {
+			Method:     http.MethodGet,
+			StatusCode: 200,
+			Body:       func() io.ReadCloser { return http.NoBody },
+		},
+		"HEAD": {
+			Method:     http.MethodHead,
+			StatusCode: 200,
+			Body:       func() io.ReadCloser { return ioutil.NopCloser(strings.NewReader("")) },
+		},
+		"not modified": {
+			Method:     http.MethodGet,
+			StatusCode: 304,
+			Body:       func() io.ReadCloser { return ioutil.NopCloser(strings.NewReader("")) },
+		},
+		"no content": {
+			Method:     http.MethodPut,
+			StatusCode: 204,
+			Body:       func() io.ReadCloser { return ioutil.NopCloser(strings.NewReader("")) },
+		},
+		"empty body": {
+			Method:       http.MethodGet,
+			StatusCode:   200,
+			Body:         func() io.ReadCloser { return &closeTrackingReader{Reader: strings.NewReader("")} },
+			ExpectClosed: true,
+			ExpectNoBody: true,
+		},
+		"invalid body": {
+			Method:       http.MethodGet,
+			StatusCode:   200,
+			Body:         func() io.ReadCloser { return &closeTrackingReader{Reader: strings.NewReader("not gzip")} },
+			ExpectErr:    true,
+			ExpectClosed: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			req.Method = c.Method
+
+			body := c.Body()
+			resp := &Response{Response: &http.Response{
+				StatusCode: c.StatusCode,
+				Header:     http.Header{"Content-Encoding": []string{"gzip"}},
+				Body:       body,
+			}}
+
+			_, _, err := (&ResponseDecompression{}).HandleDeserialize(context.Background(),
+				middleware.DeserializeInput{Request: req},
+				middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					out.RawResponse = resp
+					return out, metadata, nil
+				}),
+			)
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+			} else if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if tracker, ok := body.(*closeTrackingReader); ok {
+				if e, a := c.ExpectClosed, tracker.closed; e != a {
+					t.Errorf("expect body closed %v, got %v", e, a)
+				}
+			}
+			if c.ExpectNoBody {
+				if resp.Body != http.NoBody {
+					t.Errorf("expect no body, got %T", resp.Body)
+				}
+				if v := resp.Header.Get("Content-Encoding"); len(v) != 0 {
+					t.Errorf("expect no content encoding, got %q", v)
+				}
+			} else if !c.ExpectErr && resp.Body != body {
+				t.Errorf("expect body unmodified")
+			}
+		})
+	}
+}
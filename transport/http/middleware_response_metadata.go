@@ -0,0 +1,139 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ResponseMetadata is the normalized metadata of an operation's response,
+// collecting the values callers commonly inspect after an operation call
+// into a single value.
+type ResponseMetadata struct {
+	// RequestID is the service's identifier of the request, if the response
+	// included one.
+	RequestID string
+
+	// ExtendedRequestID is the service's secondary identifier of the request,
+	// (e.g. a host ID), if the response included one.
+	ExtendedRequestID string
+
+	// StatusCode is the status code of the response.
+	StatusCode int
+
+	// Header is a copy of the response's headers.
+	Header http.Header
+}
+
+type responseMetadataKey struct{}
+
+// GetResponseMetadata returns the response metadata recorded by the
+// RecordResponseMetadata middleware, and if the response metadata was
+// recorded.
+func GetResponseMetadata(metadata middleware.Metadata) (ResponseMetadata, bool) {
+	v, ok := metadata.Get(responseMetadataKey{}).(ResponseMetadata)
+	return v, ok
+}
+
+// GetRequestID returns the service's request ID of the response recorded by
+// the RecordResponseMetadata middleware. Returns an empty string if not
+// recorded.
+func GetRequestID(metadata middleware.Metadata) string {
+	v, _ := GetResponseMetadata(metadata)
+	return v.RequestID
+}
+
+// GetExtendedRequestID returns the service's extended request ID of the
+// response recorded by the RecordResponseMetadata middleware. Returns an
+// empty string if not recorded.
+func GetExtendedRequestID(metadata middleware.Metadata) string {
+	v, _ := GetResponseMetadata(metadata)
+	return v.ExtendedRequestID
+}
+
+// DefaultRequestIDHeaders are the response headers the RecordResponseMetadata
+// middleware reads the request ID from, in order of precedence.
+var DefaultRequestIDHeaders = []string{
+	"X-Amzn-Requestid",
+	"X-Amz-Request-Id",
+	"X-Request-Id",
+}
+
+// DefaultExtendedRequestIDHeaders are the response headers the
+// RecordResponseMetadata middleware reads the extended request ID from, in
+// order of precedence.
+var DefaultExtendedRequestIDHeaders = []string{
+	"X-Amz-Id-2",
+}
+
+// RecordResponseMetadata provides a deserialize middleware that records the
+// normalized metadata of the response in the operation's metadata,
+// retrievable with GetResponseMetadata. The metadata is recorded for
+// both successful and error responses, but not if no response was received.
+//
+// Request IDs are read from the first of the headers with a non-empty value,
+// with surrounding whitespace removed.
+type RecordResponseMetadata struct {
+	// RequestIDHeaders are the headers the request ID is read from. Defaults
+	// to DefaultRequestIDHeaders if empty.
+	RequestIDHeaders []string
+
+	// ExtendedRequestIDHeaders are the headers the extended request ID is
+	// read from. Defaults to DefaultExtendedRequestIDHeaders if empty.
+	ExtendedRequestIDHeaders []string
+}
+
+// AddRecordResponseMetadataMiddleware adds the RecordResponseMetadata
+// middleware to the end of the stack's Deserialize step.
+func AddRecordResponseMetadataMiddleware(stack *middleware.Stack) error {
+	return stack.Deserialize.Add(&RecordResponseMetadata{}, middleware.After)
+}
+
+// ID returns the identifier for the RecordResponseMetadata middleware.
+func (*RecordResponseMetadata) ID() string { return "RecordResponseMetadata" }
+
+// HandleDeserialize records the metadata of the response returned by the next
+// handler.
+func (m *RecordResponseMetadata) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok || resp == nil || resp.Response == nil {
+		return out, metadata, err
+	}
+
+	requestIDHeaders := m.RequestIDHeaders
+	if len(requestIDHeaders) == 0 {
+		requestIDHeaders = DefaultRequestIDHeaders
+	}
+	extendedRequestIDHeaders := m.ExtendedRequestIDHeaders
+	if len(extendedRequestIDHeaders) == 0 {
+		extendedRequestIDHeaders = DefaultExtendedRequestIDHeaders
+	}
+
+	metadata.Set(responseMetadataKey{}, ResponseMetadata{
+		RequestID:         firstHeaderValue(resp, requestIDHeaders),
+		ExtendedRequestID: firstHeaderValue(resp, extendedRequestIDHeaders),
+		StatusCode:        resp.StatusCode,
+		Header:            resp.Header.Clone(),
+	})
+
+	return out, metadata, err
+}
+
+// firstHeaderValue returns the value of the first of the response headers
+// with a non-empty value, with surrounding whitespace removed.
+func firstHeaderValue(resp *Response, headers []string) string {
+	for _, h := range headers {
+		if v := strings.TrimSpace(resp.Header.Get(h)); len(v) != 0 {
+			return v
+		}
+	}
+	return ""
+}
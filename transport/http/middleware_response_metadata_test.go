@@ -0,0 +1,163 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestRecordResponseMetadata(t *testing.T) {
+	cases := map[string]struct {
+		Middleware *RecordResponseMetadata
+		Response   *http.Response
+		Err        error
+		Expect     ResponseMetadata
+		ExpectOK   bool
+	}{
+		"all fields": {
+			Middleware: &RecordResponseMetadata{},
+			Response: &http.Response{
+				StatusCode: 200,
+				Header: http.Header{
+					"X-Amzn-Requestid": []string{" abc123 "},
+					"X-Amz-Id-2":       []string{"def456"},
+					"Content-Type":     []string{"application/json"},
+				},
+			},
+			Expect: ResponseMetadata{
+				RequestID:         "abc123",
+				ExtendedRequestID: "def456",
+				StatusCode:        200,
+				Header: http.Header{
+					"X-Amzn-Requestid": []string{" abc123 "},
+					"X-Amz-Id-2":       []string{"def456"},
+					"Content-Type":     []string{"application/json"},
+				},
+			},
+			ExpectOK: true,
+		},
+		"fallback request ID header": {
+			Middleware: &RecordResponseMetadata{},
+			Response: &http.Response{
+				StatusCode: 200,
+				Header: http.Header{
+					"X-Amzn-Requestid": []string{""},
+					"X-Amz-Request-Id": []string{"abc123"},
+				},
+			},
+			Expect: ResponseMetadata{
+				RequestID:  "abc123",
+				StatusCode: 200,
+				Header: http.Header{
+					"X-Amzn-Requestid": []string{""},
+					"X-Amz-Request-Id": []string{"abc123"},
+				},
+			},
+			ExpectOK: true,
+		},
+		"custom headers": {
+			Middleware: &RecordResponseMetadata{
+				RequestIDHeaders:         []string{"X-Trace-Id"},
+				ExtendedRequestIDHeaders: []string{"X-Host-Id"},
+			},
+			Response: &http.Response{
+				StatusCode: 204,
+				Header: http.Header{
+					"X-Amzn-Requestid": []string{"ignored"},
+					"X-Trace-Id":       []string{"abc123"},
+					"X-Host-Id":        []string{"def456"},
+				},
+			},
+			Expect: ResponseMetadata{
+				RequestID:         "abc123",
+				ExtendedRequestID: "def456",
+				StatusCode:        204,
+				Header: http.Header{
+					"X-Amzn-Requestid": []string{"ignored"},
+					"X-Trace-Id":       []string{"abc123"},
+					"X-Host-Id":        []string{"def456"},
+				},
+			},
+			ExpectOK: true,
+		},
+		"error response": {
+			Middleware: &RecordResponseMetadata{},
+			Response: &http.Response{
+				StatusCode: 500,
+				Header:     http.Header{"X-Amz-Request-Id": []string{"abc123"}},
+			},
+			Err: fmt.Errorf("internal failure"),
+			Expect: ResponseMetadata{
+				RequestID:  "abc123",
+				StatusCode: 500,
+				Header:     http.Header{"X-Amz-Request-Id": []string{"abc123"}},
+			},
+			ExpectOK: true,
+		},
+		"no response": {
+			Middleware: &RecordResponseMetadata{},
+			Err:        fmt.Errorf("connection reset"),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, metadata, err := c.Middleware.HandleDeserialize(context.Background(),
+				middleware.DeserializeInput{},
+				middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					if c.Response != nil {
+						out.RawResponse = &Response{Response: c.Response}
+					}
+					return out, metadata, c.Err
+				}),
+			)
+			if e, a := c.Err, err; e != a {
+				t.Errorf("expect %v error, got %v", e, a)
+			}
+
+			actual, ok := GetResponseMetadata(metadata)
+			if e, a := c.ExpectOK, ok; e != a {
+				t.Fatalf("expect recorded %v, got %v", e, a)
+			}
+			if !reflect.DeepEqual(c.Expect, actual) {
+				t.Errorf("expect %v, got %v", c.Expect, actual)
+			}
+			if e, a := c.Expect.RequestID, GetRequestID(metadata); e != a {
+				t.Errorf("expect %v request ID, got %v", e, a)
+			}
+			if e, a := c.Expect.ExtendedRequestID, GetExtendedRequestID(metadata); e != a {
+				t.Errorf("expect %v extended request ID, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestRecordResponseMetadata_HeaderCopied(t *testing.T) {
+	header := http.Header{"X-Amz-Request-Id": []string{"abc123"}}
+
+	_, metadata, err := (&RecordResponseMetadata{}).HandleDeserialize(context.Background(),
+		middleware.DeserializeInput{},
+		middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+			out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+		) {
+			out.RawResponse = &Response{Response: &http.Response{StatusCode: 200, Header: header}}
+			return out, metadata, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	header.Set("X-Amz-Request-Id", "modified")
+
+	actual, _ := GetResponseMetadata(metadata)
+	if e, a := "abc123", actual.Header.Get("X-Amz-Request-Id"); e != a {
+		t.Errorf("expect %v header, got %v", e, a)
+	}
+}
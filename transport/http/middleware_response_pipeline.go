@@ -0,0 +1,64 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ResponsePipeline provides a deserialize middleware that applies a pipeline
+// of transformations to the response, (e.g. decrypting the body, or
+// unwrapping an envelope), before the response is deserialized. The stages
+// are run in order, stopping at the first stage that returns an error.
+//
+// Stages may modify the response in place, such as replacing its Body. The
+// stages are not run if no response was received.
+type ResponsePipeline struct {
+	stages []func(*http.Response) error
+}
+
+// NewResponsePipeline returns an initialized ResponsePipeline middleware
+// running the stages in order.
+func NewResponsePipeline(stages ...func(*http.Response) error) *ResponsePipeline {
+	return &ResponsePipeline{
+		stages: append([]func(*http.Response) error(nil), stages...),
+	}
+}
+
+// AddResponsePipelineMiddleware adds the ResponsePipeline middleware to the
+// end of the stack's Deserialize step, so that the response is transformed
+// before it is read by the deserializers.
+func AddResponsePipelineMiddleware(stack *middleware.Stack, stages ...func(*http.Response) error) error {
+	return stack.Deserialize.Add(NewResponsePipeline(stages...), middleware.After)
+}
+
+// ID returns the identifier for the ResponsePipeline middleware.
+func (*ResponsePipeline) ID() string { return "ResponsePipeline" }
+
+// HandleDeserialize runs the pipeline's stages on the response returned by
+// the next handler.
+func (m *ResponsePipeline) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok || resp == nil || resp.Response == nil {
+		return out, metadata, err
+	}
+
+	for i, stage := range m.stages {
+		if err := stage(resp.Response); err != nil {
+			return out, metadata, fmt.Errorf("response pipeline stage %d failed, %w", i, err)
+		}
+	}
+
+	return out, metadata, nil
+}
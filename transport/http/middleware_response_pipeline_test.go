@@ -0,0 +1,116 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestResponsePipeline(t *testing.T) {
+	stageErr := errors.New("stage failed")
+
+	cases := map[string]struct {
+		FirstErr    error
+		ExpectOrder []string
+		ExpectBody  string
+		ExpectErr   error
+	}{
+		"stages in order": {
+			ExpectOrder: []string{"unwrap", "upper"},
+			ExpectBody:  "PAYLOAD",
+		},
+		"short-circuit on error": {
+			FirstErr:    stageErr,
+			ExpectOrder: []string{"unwrap"},
+			ExpectErr:   stageErr,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var order []string
+			m := NewResponsePipeline(
+				func(resp *http.Response) error {
+					order = append(order, "unwrap")
+					if c.FirstErr != nil {
+						return c.FirstErr
+					}
+					b, err := ioutil.ReadAll(resp.Body)
+					if err != nil {
+						return err
+					}
+					unwrapped := strings.TrimSuffix(strings.TrimPrefix(string(b), "envelope("), ")")
+					resp.Body = ioutil.NopCloser(strings.NewReader(unwrapped))
+					return nil
+				},
+				func(resp *http.Response) error {
+					order = append(order, "upper")
+					b, err := ioutil.ReadAll(resp.Body)
+					if err != nil {
+						return err
+					}
+					resp.Body = ioutil.NopCloser(strings.NewReader(strings.ToUpper(string(b))))
+					return nil
+				},
+			)
+
+			out, _, err := m.HandleDeserialize(context.Background(), middleware.DeserializeInput{},
+				middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					out.RawResponse = &Response{Response: &http.Response{
+						StatusCode: 200,
+						Body:       ioutil.NopCloser(strings.NewReader("envelope(payload)")),
+					}}
+					return out, metadata, nil
+				}),
+			)
+
+			if e, a := strings.Join(c.ExpectOrder, ","), strings.Join(order, ","); e != a {
+				t.Errorf("expect %v stages, got %v", e, a)
+			}
+
+			if c.ExpectErr != nil {
+				if !errors.Is(err, c.ExpectErr) {
+					t.Fatalf("expect %v error, got %v", c.ExpectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			b, err := ioutil.ReadAll(out.RawResponse.(*Response).Body)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.ExpectBody, string(b); e != a {
+				t.Errorf("expect %v body, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestResponsePipeline_NoResponse(t *testing.T) {
+	sendErr := errors.New("send failed")
+	m := NewResponsePipeline(func(*http.Response) error {
+		t.Errorf("expect stage not to be run")
+		return nil
+	})
+
+	_, _, err := m.HandleDeserialize(context.Background(), middleware.DeserializeInput{},
+		middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+			out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+		) {
+			return out, metadata, sendErr
+		}),
+	)
+	if e, a := sendErr, err; e != a {
+		t.Errorf("expect %v error, got %v", e, a)
+	}
+}
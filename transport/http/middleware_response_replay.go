@@ -0,0 +1,110 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ReplayableBody provides a response body that has been fully buffered in
+// memory, and can be rewound to be read from the start multiple times.
+type ReplayableBody struct {
+	*bytes.Reader
+}
+
+// NewReplayableBody reads the body into memory and closes it, returning a
+// ReplayableBody with the buffered content. Returns an error if the body
+// could not be read, or its length exceeds maxSize bytes.
+func NewReplayableBody(body io.ReadCloser, maxSize int64) (*ReplayableBody, error) {
+	defer body.Close()
+
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, io.LimitReader(body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to buffer response body, %w", err)
+	}
+	if n > maxSize {
+		return nil, fmt.Errorf("response body exceeds maximum replay buffer size of %d bytes", maxSize)
+	}
+
+	return &ReplayableBody{Reader: bytes.NewReader(buf.Bytes())}, nil
+}
+
+// Rewind resets the body to be read from the start.
+func (b *ReplayableBody) Rewind() error {
+	_, err := b.Seek(0, io.SeekStart)
+	return err
+}
+
+// Close is a no-op, the buffered content remains available to be rewound and
+// read again.
+func (b *ReplayableBody) Close() error { return nil }
+
+// RewindResponseBody rewinds the response's body to the start, if the body is a
+// ReplayableBody. Deserialize middleware that need to read a body that may
+// have been read by other middleware should call this before reading the body.
+//
+// Returns false if the body cannot be rewound.
+func RewindResponseBody(resp *Response) (bool, error) {
+	body, ok := resp.Body.(*ReplayableBody)
+	if !ok {
+		return false, nil
+	}
+	if err := body.Rewind(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ResponseBodyReplay provides a deserialize middleware that buffers the
+// response body into a ReplayableBody, allowing multiple deserialize
+// middleware to each read the body from the start.
+type ResponseBodyReplay struct {
+	// MaxSize is the maximum number of bytes of the response body that will
+	// be buffered. Responses with larger bodies will fail with an error.
+	MaxSize int64
+}
+
+// AddResponseBodyReplayMiddleware adds the ResponseBodyReplay middleware to
+// the end of the stack's Deserialize step, so the body is buffered before any
+// other deserialize middleware reads it.
+func AddResponseBodyReplayMiddleware(stack *middleware.Stack, maxSize int64) error {
+	return stack.Deserialize.Add(&ResponseBodyReplay{MaxSize: maxSize}, middleware.After)
+}
+
+// ID returns the identifier for the ResponseBodyReplay middleware.
+func (m *ResponseBodyReplay) ID() string { return "ResponseBodyReplay" }
+
+// HandleDeserialize buffers the body of the response into a ReplayableBody.
+func (m *ResponseBodyReplay) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err != nil {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown transport type %T", out.RawResponse)
+	}
+	if resp.Body == nil {
+		return out, metadata, err
+	}
+	if _, ok := resp.Body.(*ReplayableBody); ok {
+		return out, metadata, err
+	}
+
+	body, err := NewReplayableBody(resp.Body, m.MaxSize)
+	if err != nil {
+		return out, metadata, err
+	}
+	resp.Body = body
+
+	return out, metadata, nil
+}
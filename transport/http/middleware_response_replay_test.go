@@ -0,0 +1,111 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+type closeTrackingReader struct {
+	*strings.Reader
+	closed bool
+}
+
+func (r *closeTrackingReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestResponseBodyReplay(t *testing.T) {
+	cases := map[string]struct {
+		Body      string
+		MaxSize   int64
+		ExpectErr string
+	}{
+		"within limit": {
+			Body:    "hello world",
+			MaxSize: 11,
+		},
+		"empty body": {
+			MaxSize: 11,
+		},
+		"exceeds limit": {
+			Body:      "hello world",
+			MaxSize:   10,
+			ExpectErr: "exceeds maximum replay buffer size of 10 bytes",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			original := &closeTrackingReader{Reader: strings.NewReader(c.Body)}
+
+			m := &ResponseBodyReplay{MaxSize: c.MaxSize}
+			out, _, err := m.HandleDeserialize(context.Background(),
+				middleware.DeserializeInput{},
+				middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					out.RawResponse = &Response{Response: &http.Response{
+						StatusCode: 200,
+						Body:       original,
+					}}
+					return out, metadata, nil
+				}),
+			)
+			if !original.closed {
+				t.Errorf("expect original body to be closed")
+			}
+
+			if len(c.ExpectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				if e, a := c.ExpectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expect %q in error, got %q", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			resp := out.RawResponse.(*Response)
+			for i := 0; i < 2; i++ {
+				ok, err := RewindResponseBody(resp)
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				if !ok {
+					t.Fatalf("expect body to be rewound")
+				}
+
+				b, err := ioutil.ReadAll(resp.Body)
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				if err := resp.Body.Close(); err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				if e, a := c.Body, string(b); e != a {
+					t.Errorf("expect read %d body %q, got %q", i, e, a)
+				}
+			}
+		})
+	}
+}
+
+func TestRewindResponseBody_NotReplayable(t *testing.T) {
+	resp := &Response{Response: &http.Response{Body: ioutil.NopCloser(strings.NewReader("abc"))}}
+	ok, err := RewindResponseBody(resp)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if ok {
+		t.Errorf("expect body to not be rewindable")
+	}
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	smithy "github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ParseRetryAfter parses the value of an HTTP Retry-After header, either a
+// number of seconds or an HTTP date, returning the duration to wait relative
+// to now. A date in the past returns a zero duration.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, fmt.Errorf("invalid negative Retry-After seconds, %v", seconds)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	t, err := ParseTime(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Retry-After value %q, %w", value, err)
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, nil
+	}
+	return 0, nil
+}
+
+// ErrorRetryAfter provides a deserialize middleware that annotates operation
+// errors with the duration of the response's Retry-After header, if present.
+// The retry-after duration of the returned error can be retrieved with
+// smithy.GetRetryAfter. The error's message and chain are otherwise unchanged.
+//
+// Errors that already carry a retry-after hint, and Retry-After values that
+// cannot be parsed, are not modified.
+type ErrorRetryAfter struct{}
+
+// AddErrorRetryAfterMiddleware adds the ErrorRetryAfter middleware to the
+// front of the stack's Deserialize step, so that it observes the errors
+// returned by the deserializers.
+func AddErrorRetryAfterMiddleware(stack *middleware.Stack) error {
+	return stack.Deserialize.Add(&ErrorRetryAfter{}, middleware.Before)
+}
+
+// ID returns the identifier for the ErrorRetryAfter middleware.
+func (*ErrorRetryAfter) ID() string { return "ErrorRetryAfter" }
+
+// HandleDeserialize annotates the error returned by the next handler with the
+// response's Retry-After header.
+func (m *ErrorRetryAfter) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+	if err == nil {
+		return out, metadata, err
+	}
+	if _, ok := smithy.GetRetryAfter(err); ok {
+		return out, metadata, err
+	}
+
+	resp, ok := out.RawResponse.(*Response)
+	if !ok || resp == nil || resp.Response == nil {
+		return out, metadata, err
+	}
+	value := resp.Header.Get("Retry-After")
+	if len(value) == 0 {
+		return out, metadata, err
+	}
+
+	retryAfter, perr := ParseRetryAfter(value, time.Now())
+	if perr != nil {
+		return out, metadata, err
+	}
+
+	return out, metadata, &retryAfterError{Err: err, retryAfter: retryAfter}
+}
+
+// retryAfterError annotates an error with a retry-after duration, without
+// modifying the error's message.
+type retryAfterError struct {
+	Err        error
+	retryAfter time.Duration
+}
+
+func (e *retryAfterError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the annotated error.
+func (e *retryAfterError) Unwrap() error { return e.Err }
+
+// RetryAfter returns the duration the service requested to wait before
+// retrying.
+func (e *retryAfterError) RetryAfter() time.Duration { return e.retryAfter }
@@ -0,0 +1,148 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	smithy "github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		Value     string
+		Expect    time.Duration
+		ExpectErr bool
+	}{
+		"seconds": {
+			Value:  "120",
+			Expect: 2 * time.Minute,
+		},
+		"zero seconds": {
+			Value: "0",
+		},
+		"http date": {
+			Value:  "Wed, 21 Oct 2015 07:28:30 GMT",
+			Expect: 30 * time.Second,
+		},
+		"http date in past": {
+			Value: "Wed, 21 Oct 2015 07:00:00 GMT",
+		},
+		"negative seconds": {
+			Value:     "-1",
+			ExpectErr: true,
+		},
+		"invalid": {
+			Value:     "soon",
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParseRetryAfter(c.Value, now)
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.Expect, actual; e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestErrorRetryAfter(t *testing.T) {
+	cases := map[string]struct {
+		Header      string
+		Err         error
+		Expect      time.Duration
+		ExpectFound bool
+	}{
+		"retry after seconds": {
+			Header:      "5",
+			Err:         &smithy.GenericAPIError{Code: "Throttling"},
+			Expect:      5 * time.Second,
+			ExpectFound: true,
+		},
+		"no header": {
+			Err: &smithy.GenericAPIError{Code: "Throttling"},
+		},
+		"invalid header": {
+			Header: "later",
+			Err:    &smithy.GenericAPIError{Code: "Throttling"},
+		},
+		"no error": {
+			Header: "5",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Simulate the operation's error wrapping of the deserialize error.
+			stack := middleware.NewStack("test", NewStackRequest)
+			stack.Deserialize.Add(&ErrorRetryAfter{}, middleware.Before)
+			stack.Deserialize.Add(middleware.DeserializeMiddlewareFunc("deserialize",
+				func(ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					header := http.Header{}
+					if len(c.Header) != 0 {
+						header.Set("Retry-After", c.Header)
+					}
+					out.RawResponse = &Response{Response: &http.Response{
+						StatusCode: 503,
+						Header:     header,
+						Body:       http.NoBody,
+					}}
+					return out, metadata, c.Err
+				}), middleware.After)
+
+			handler := middleware.DecorateHandler(middleware.HandlerFunc(
+				func(ctx context.Context, input interface{}) (interface{}, middleware.Metadata, error) {
+					return nil, middleware.Metadata{}, nil
+				}), stack)
+
+			_, _, err := handler.Handle(context.Background(), struct{}{})
+			if c.Err == nil {
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				return
+			}
+
+			err = &smithy.OperationError{
+				ServiceID:     "service",
+				OperationName: "operation",
+				Err:           fmt.Errorf("wrapped, %w", err),
+			}
+
+			actual, found := smithy.GetRetryAfter(err)
+			if e, a := c.ExpectFound, found; e != a {
+				t.Fatalf("expect %v found, got %v", e, a)
+			}
+			if e, a := c.Expect, actual; e != a {
+				t.Errorf("expect %v retry after, got %v", e, a)
+			}
+
+			var apiErr smithy.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expect API error in chain, got %v", err)
+			}
+			if e, a := c.Err.Error(), errors.Unwrap(errors.Unwrap(err)).Error(); e != a {
+				t.Errorf("expect %q error message, got %q", e, a)
+			}
+		})
+	}
+}
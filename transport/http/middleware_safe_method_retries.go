@@ -0,0 +1,99 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+type unsafeMethodRetriesAllowedKey struct{}
+
+// WithUnsafeMethodRetriesAllowed returns a copy of the Context with whether
+// requests with an HTTP method that is not idempotent, (e.g. POST), may be
+// retried after a network error, overriding the SafeMethodRetries middleware.
+func WithUnsafeMethodRetriesAllowed(ctx context.Context, allowed bool) context.Context {
+	return context.WithValue(ctx, unsafeMethodRetriesAllowedKey{}, allowed)
+}
+
+// GetUnsafeMethodRetriesAllowed returns if requests with an HTTP method that
+// is not idempotent may be retried after a network error, set on the Context
+// with WithUnsafeMethodRetriesAllowed.
+func GetUnsafeMethodRetriesAllowed(ctx context.Context) bool {
+	v, _ := ctx.Value(unsafeMethodRetriesAllowedKey{}).(bool)
+	return v
+}
+
+// UnsafeMethodRetryError wraps the network error of a request attempt whose
+// HTTP method is not idempotent, preventing the attempt from being retried.
+// The request may have been received by the service before the network error
+// occurred.
+type UnsafeMethodRetryError struct {
+	Method string
+	Err    error
+}
+
+func (e *UnsafeMethodRetryError) Error() string {
+	return fmt.Sprintf("not retrying %s request after network error, %v", e.Method, e.Err)
+}
+
+// Unwrap returns the attempt's error.
+func (e *UnsafeMethodRetryError) Unwrap() error { return e.Err }
+
+// RetryableError returns false, since the request is not safe to retry.
+func (e *UnsafeMethodRetryError) RetryableError() bool { return false }
+
+// SafeMethodRetries provides a finalize middleware that only allows requests
+// with an idempotent HTTP method, (GET, HEAD, PUT, DELETE, OPTIONS), to be
+// retried after a network error. The network errors of other requests are
+// wrapped in an UnsafeMethodRetryError, unless allowed with
+// WithUnsafeMethodRetriesAllowed.
+//
+// Errors other than network errors, (e.g. service errors), are not modified.
+type SafeMethodRetries struct{}
+
+// AddSafeMethodRetriesMiddleware adds the SafeMethodRetries middleware to the
+// Finalize step after the "Retry" middleware, if present, otherwise to the end
+// of the step.
+func AddSafeMethodRetriesMiddleware(stack *middleware.Stack) error {
+	return middleware.InsertAfterRetry(stack, &SafeMethodRetries{})
+}
+
+// ID returns the identifier for the SafeMethodRetries middleware.
+func (*SafeMethodRetries) ID() string { return "SafeMethodRetries" }
+
+// HandleFinalize wraps the attempt's network error if the request's method is
+// not safe to retry.
+func (*SafeMethodRetries) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	out, metadata, err = next.HandleFinalize(ctx, in)
+	if err == nil || isSafeRetryMethod(req.Method) || GetUnsafeMethodRetriesAllowed(ctx) {
+		return out, metadata, err
+	}
+
+	var connErr interface{ ConnectionError() bool }
+	if !errors.As(err, &connErr) || !connErr.ConnectionError() {
+		return out, metadata, err
+	}
+
+	return out, metadata, &UnsafeMethodRetryError{Method: req.Method, Err: err}
+}
+
+func isSafeRetryMethod(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
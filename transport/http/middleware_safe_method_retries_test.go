@@ -0,0 +1,108 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestSafeMethodRetries(t *testing.T) {
+	cases := map[string]struct {
+		Method         string
+		Err            error
+		WithContext    func(context.Context) context.Context
+		ExpectAttempts int
+		ExpectWrapped  bool
+	}{
+		"GET network error": {
+			Method:         "GET",
+			Err:            &RequestSendError{Err: errors.New("connection reset")},
+			ExpectAttempts: 3,
+		},
+		"POST network error": {
+			Method:         "POST",
+			Err:            &RequestSendError{Err: errors.New("connection reset")},
+			ExpectAttempts: 1,
+			ExpectWrapped:  true,
+		},
+		"POST network error allowed": {
+			Method: "POST",
+			Err:    &RequestSendError{Err: errors.New("connection reset")},
+			WithContext: func(ctx context.Context) context.Context {
+				return WithUnsafeMethodRetriesAllowed(ctx, true)
+			},
+			ExpectAttempts: 3,
+		},
+		"POST service error": {
+			Method:         "POST",
+			Err:            errors.New("throttled"),
+			ExpectAttempts: 3,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			stack := middleware.NewStack("stack", NewStackRequest)
+
+			// mock retry middleware retrying up to 3 attempts, unless the
+			// error is not retryable.
+			err := stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("Retry",
+				func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+					out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+				) {
+					for i := 0; i < 3; i++ {
+						out, metadata, err = next.HandleFinalize(ctx, in)
+						var retryable interface{ RetryableError() bool }
+						if err == nil || (errors.As(err, &retryable) && !retryable.RetryableError()) {
+							break
+						}
+					}
+					return out, metadata, err
+				}), middleware.After)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if err := AddSafeMethodRetriesMiddleware(stack); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			err = stack.Serialize.Add(middleware.SerializeMiddlewareFunc("method",
+				func(ctx context.Context, in middleware.SerializeInput, next middleware.SerializeHandler) (
+					out middleware.SerializeOutput, metadata middleware.Metadata, err error,
+				) {
+					in.Request.(*Request).Method = c.Method
+					return next.HandleSerialize(ctx, in)
+				}), middleware.After)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			var attempts int
+			handler := middleware.DecorateHandler(middleware.HandlerFunc(func(ctx context.Context, input interface{}) (
+				output interface{}, metadata middleware.Metadata, err error,
+			) {
+				attempts++
+				return nil, metadata, c.Err
+			}), stack)
+
+			ctx := context.Background()
+			if c.WithContext != nil {
+				ctx = c.WithContext(ctx)
+			}
+
+			_, _, err = handler.Handle(ctx, struct{}{})
+			if !errors.Is(err, c.Err) {
+				t.Fatalf("expect attempt error, got %v", err)
+			}
+			if e, a := c.ExpectAttempts, attempts; e != a {
+				t.Errorf("expect %v attempts, got %v", e, a)
+			}
+
+			var unsafeErr *UnsafeMethodRetryError
+			if e, a := c.ExpectWrapped, errors.As(err, &unsafeErr); e != a {
+				t.Errorf("expect wrapped %v, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// SchemaVersion provides a build middleware that sets a header declaring the
+// schema version of the API the request was serialized for. Any existing value
+// of the header is replaced.
+type SchemaVersion struct {
+	header  string
+	version string
+}
+
+// NewSchemaVersion returns an initialized SchemaVersion middleware that sets
+// the headerName header to version.
+func NewSchemaVersion(headerName, version string) *SchemaVersion {
+	return &SchemaVersion{
+		header:  headerName,
+		version: version,
+	}
+}
+
+// AddSchemaVersionMiddleware adds the SchemaVersion middleware to the end of
+// the stack's Build step. Returns an error if headerName or version is empty.
+func AddSchemaVersionMiddleware(stack *middleware.Stack, headerName, version string) error {
+	if len(headerName) == 0 {
+		return fmt.Errorf("schema version header name must be set")
+	}
+	if len(version) == 0 {
+		return fmt.Errorf("schema version must be set")
+	}
+	return stack.Build.Add(NewSchemaVersion(headerName, version), middleware.After)
+}
+
+// ID returns the identifier for the SchemaVersion middleware.
+func (m *SchemaVersion) ID() string { return "SchemaVersion" }
+
+// HandleBuild sets the schema version header on the request.
+func (m *SchemaVersion) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	req.Header.Set(m.header, m.version)
+
+	return next.HandleBuild(ctx, in)
+}
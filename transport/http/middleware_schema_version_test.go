@@ -0,0 +1,59 @@
+package http
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestSchemaVersion(t *testing.T) {
+	cases := map[string]struct {
+		Existing []string
+	}{
+		"no header": {},
+		"stale value": {
+			Existing: []string{"2019-01-01"},
+		},
+		"multiple stale values": {
+			Existing: []string{"2019-01-01", "2020-01-01"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			for _, v := range c.Existing {
+				req.Header.Add("X-Api-Version", v)
+			}
+
+			m := NewSchemaVersion("x-api-version", "2023-06-01")
+			_, _, err := m.HandleBuild(context.Background(),
+				middleware.BuildInput{Request: req},
+				middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+					out middleware.BuildOutput, metadata middleware.Metadata, err error,
+				) {
+					r := in.Request.(*Request)
+					if e, a := []string{"2023-06-01"}, r.Header.Values("X-Api-Version"); !reflect.DeepEqual(e, a) {
+						t.Errorf("expect %v header, got %v", e, a)
+					}
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAddSchemaVersionMiddleware_Invalid(t *testing.T) {
+	stack := middleware.NewStack("stack", NewStackRequest)
+	if err := AddSchemaVersionMiddleware(stack, "", "2023-06-01"); err == nil {
+		t.Errorf("expect error for empty header name, got none")
+	}
+	if err := AddSchemaVersionMiddleware(stack, "X-Api-Version", ""); err == nil {
+		t.Errorf("expect error for empty version, got none")
+	}
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DefaultSecurityTokenHeader is the header the RequireSecurityToken
+// middleware validates if no header is configured.
+const DefaultSecurityTokenHeader = "X-Amz-Security-Token"
+
+type securityTokenExpectedKey struct{}
+
+// WithSecurityTokenExpected returns a copy of the Context with whether the
+// operation's request is expected to have a security token header, (e.g. the
+// operation is signed with temporary credentials). Used by the
+// RequireSecurityToken middleware.
+func WithSecurityTokenExpected(ctx context.Context, expected bool) context.Context {
+	return context.WithValue(ctx, securityTokenExpectedKey{}, expected)
+}
+
+// GetSecurityTokenExpected returns whether the operation's request is expected
+// to have a security token header. Returns false if not set on the Context.
+func GetSecurityTokenExpected(ctx context.Context) bool {
+	v, _ := ctx.Value(securityTokenExpectedKey{}).(bool)
+	return v
+}
+
+// MissingSecurityTokenError is returned when a request expected to have a
+// security token header does not, or the header's value is empty.
+type MissingSecurityTokenError struct {
+	Header string
+}
+
+func (e *MissingSecurityTokenError) Error() string {
+	return fmt.Sprintf("request expected to have security token, %s header missing or empty", e.Header)
+}
+
+// RetryableError returns false, since retrying the request will not add the
+// security token.
+func (e *MissingSecurityTokenError) RetryableError() bool { return false }
+
+// RequireSecurityToken provides a finalize middleware that validates the
+// request has a non-empty security token header, if the operation expects one.
+// Whether the token is expected is configured per operation with
+// WithSecurityTokenExpected. Requests not expecting the token are not
+// validated.
+type RequireSecurityToken struct {
+	// Header is the security token header name. Defaults to
+	// DefaultSecurityTokenHeader if empty.
+	Header string
+}
+
+// AddRequireSecurityTokenMiddleware adds the RequireSecurityToken middleware
+// to the end of the stack's Finalize step, so that the header is validated
+// after the request is signed.
+func AddRequireSecurityTokenMiddleware(stack *middleware.Stack, header string) error {
+	return stack.Finalize.Add(&RequireSecurityToken{Header: header}, middleware.After)
+}
+
+// ID returns the identifier for the RequireSecurityToken middleware.
+func (*RequireSecurityToken) ID() string { return "RequireSecurityToken" }
+
+// HandleFinalize validates the request's security token header.
+func (m *RequireSecurityToken) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	if !GetSecurityTokenExpected(ctx) {
+		return next.HandleFinalize(ctx, in)
+	}
+
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	header := m.Header
+	if len(header) == 0 {
+		header = DefaultSecurityTokenHeader
+	}
+	if len(strings.TrimSpace(req.Header.Get(header))) == 0 {
+		return out, metadata, &MissingSecurityTokenError{Header: header}
+	}
+
+	return next.HandleFinalize(ctx, in)
+}
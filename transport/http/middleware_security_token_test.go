@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestRequireSecurityToken(t *testing.T) {
+	cases := map[string]struct {
+		Header      string
+		Expected    bool
+		Headers     map[string]string
+		ExpectErr   bool
+		ErrorHeader string
+	}{
+		"present": {
+			Expected: true,
+			Headers:  map[string]string{"X-Amz-Security-Token": "token"},
+		},
+		"missing": {
+			Expected:    true,
+			ExpectErr:   true,
+			ErrorHeader: "X-Amz-Security-Token",
+		},
+		"empty": {
+			Expected:    true,
+			Headers:     map[string]string{"X-Amz-Security-Token": " "},
+			ExpectErr:   true,
+			ErrorHeader: "X-Amz-Security-Token",
+		},
+		"custom header missing": {
+			Header:      "X-Session-Token",
+			Expected:    true,
+			Headers:     map[string]string{"X-Amz-Security-Token": "token"},
+			ExpectErr:   true,
+			ErrorHeader: "X-Session-Token",
+		},
+		"custom header present": {
+			Header:   "X-Session-Token",
+			Expected: true,
+			Headers:  map[string]string{"X-Session-Token": "token"},
+		},
+		"not expected": {},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			for k, v := range c.Headers {
+				req.Header.Set(k, v)
+			}
+
+			ctx := WithSecurityTokenExpected(context.Background(), c.Expected)
+
+			var called bool
+			m := &RequireSecurityToken{Header: c.Header}
+			_, _, err := m.HandleFinalize(ctx, middleware.FinalizeInput{Request: req},
+				middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+					out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+				) {
+					called = true
+					return out, metadata, nil
+				}),
+			)
+
+			if !c.ExpectErr {
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				if !called {
+					t.Errorf("expect next handler to be called")
+				}
+				return
+			}
+
+			var tokenErr *MissingSecurityTokenError
+			if !errors.As(err, &tokenErr) {
+				t.Fatalf("expect %T error, got %v", tokenErr, err)
+			}
+			if e, a := c.ErrorHeader, tokenErr.Header; e != a {
+				t.Errorf("expect %v header, got %v", e, a)
+			}
+			if called {
+				t.Errorf("expect next handler not to be called")
+			}
+		})
+	}
+}
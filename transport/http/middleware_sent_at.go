@@ -0,0 +1,69 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+	smithytime "github.com/aws/smithy-go/time"
+)
+
+// DefaultSentAtHeader is the default header the SentAt middleware sets.
+const DefaultSentAtHeader = "X-Client-Sent-At"
+
+// SentAt provides a finalize middleware that sets a header with the time the
+// request is sent, for services to attribute the latency between the client
+// and server. The middleware should be the last Finalize middleware, so the
+// header value is the time immediately before the request is dispatched. The
+// header is updated for each attempt of the request.
+type SentAt struct {
+	// Header is the name of the header to set. Defaults to
+	// DefaultSentAtHeader if empty.
+	Header string
+
+	// Format returns the header value for the send time. Defaults to the
+	// ISO 8601 date-time format of smithytime.FormatDateTime if nil.
+	Format func(time.Time) string
+
+	// Now returns the current time. Defaults to time.Now if nil.
+	Now func() time.Time
+}
+
+// AddSentAtMiddleware adds the SentAt middleware to the end of the stack's
+// Finalize step.
+func AddSentAtMiddleware(stack *middleware.Stack, m *SentAt) error {
+	return stack.Finalize.Add(m, middleware.After)
+}
+
+// ID returns the identifier for the SentAt middleware.
+func (m *SentAt) ID() string { return "SentAt" }
+
+// HandleFinalize sets the header to the current time.
+func (m *SentAt) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	header := m.Header
+	if len(header) == 0 {
+		header = DefaultSentAtHeader
+	}
+	format := m.Format
+	if format == nil {
+		format = smithytime.FormatDateTime
+	}
+	now := m.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	req.Header.Set(header, format(now()))
+
+	return next.HandleFinalize(ctx, in)
+}
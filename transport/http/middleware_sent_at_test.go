@@ -0,0 +1,62 @@
+package http
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestSentAt(t *testing.T) {
+	clock := func() time.Time {
+		return time.Date(2023, 6, 1, 12, 30, 45, 123000000, time.FixedZone("", -7*60*60))
+	}
+
+	cases := map[string]struct {
+		Middleware   SentAt
+		ExpectHeader string
+		ExpectValue  string
+	}{
+		"default": {
+			Middleware:   SentAt{Now: clock},
+			ExpectHeader: "X-Client-Sent-At",
+			ExpectValue:  "2023-06-01T19:30:45.123Z",
+		},
+		"custom header and format": {
+			Middleware: SentAt{
+				Header: "X-Sent",
+				Format: func(t time.Time) string {
+					return strconv.FormatInt(t.Unix(), 10)
+				},
+				Now: clock,
+			},
+			ExpectHeader: "X-Sent",
+			ExpectValue:  "1685647845",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			req.Header.Set(c.ExpectHeader, "stale")
+
+			_, _, err := c.Middleware.HandleFinalize(context.Background(),
+				middleware.FinalizeInput{Request: req},
+				middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+					out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+				) {
+					r := in.Request.(*Request)
+					if e, a := c.ExpectValue, r.Header.Get(c.ExpectHeader); e != a {
+						t.Errorf("expect %q header value, got %q", e, a)
+					}
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	smithycontext "github.com/aws/smithy-go/context"
+	"github.com/aws/smithy-go/logging"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Defaults for the ShadowRequest middleware.
+const (
+	DefaultShadowRequestTimeout     = 10 * time.Second
+	DefaultShadowRequestMaxInFlight = 10
+)
+
+// shadowCredentialHeaders are the request headers removed from shadow
+// requests, so that credentials are not sent to the shadow endpoint.
+var shadowCredentialHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"X-Amz-Security-Token",
+}
+
+// ShadowRequest provides a finalize middleware that asynchronously sends a
+// copy of a sampled portion of requests to a shadow endpoint. The shadow
+// request's response, or error, is discarded and does not affect the primary
+// request. Credential headers, (e.g. Authorization), are removed from the
+// shadow request.
+//
+// Requests with a body that is not seekable are not shadowed, since the body
+// cannot be copied without consuming it. Requests are also not shadowed while
+// MaxInFlight shadow requests are already being sent.
+type ShadowRequest struct {
+	// Client is the HTTP client the shadow requests are sent with. Defaults to
+	// http.DefaultClient if nil.
+	Client ClientDo
+
+	// Timeout is the maximum duration of a shadow request. Defaults to
+	// DefaultShadowRequestTimeout if zero.
+	Timeout time.Duration
+
+	// MaxInFlight is the maximum number of shadow requests sent concurrently.
+	// Defaults to DefaultShadowRequestMaxInFlight if zero.
+	MaxInFlight int
+
+	endpoint   *url.URL
+	sampleRate float64
+
+	// random returns a value in the range [0.0,1.0) used for sampling.
+	random func() float64
+
+	mu       sync.Mutex
+	inFlight int
+}
+
+// NewShadowRequest returns an initialized ShadowRequest middleware that
+// shadows requests to the endpoint of shadowURL. The scheme and host of
+// shadowURL replace those of the request. sampleRate is the fraction of
+// requests, between 0.0 and 1.0, that will be shadowed.
+func NewShadowRequest(shadowURL string, sampleRate float64) (*ShadowRequest, error) {
+	endpoint, err := url.Parse(shadowURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shadow endpoint URL, %w", err)
+	}
+	if len(endpoint.Scheme) == 0 || len(endpoint.Host) == 0 {
+		return nil, fmt.Errorf("shadow endpoint URL requires scheme and host, %q", shadowURL)
+	}
+	if sampleRate < 0 || sampleRate > 1 {
+		return nil, fmt.Errorf("shadow request sample rate must be between 0.0 and 1.0, got %v", sampleRate)
+	}
+
+	return &ShadowRequest{
+		endpoint:   endpoint,
+		sampleRate: sampleRate,
+		random:     rand.Float64,
+	}, nil
+}
+
+// AddShadowRequestMiddleware adds the ShadowRequest middleware to the
+// stack's Finalize step before the "Signing" middleware, if present, so that
+// shadow requests are not signed. Otherwise the middleware is added to the end
+// of the step.
+func AddShadowRequestMiddleware(stack *middleware.Stack, shadowURL string, sampleRate float64) error {
+	m, err := NewShadowRequest(shadowURL, sampleRate)
+	if err != nil {
+		return err
+	}
+	if _, ok := stack.Finalize.Get("Signing"); ok {
+		return stack.Finalize.Insert(m, "Signing", middleware.Before)
+	}
+	return stack.Finalize.Add(m, middleware.After)
+}
+
+// ID returns the identifier for the ShadowRequest middleware.
+func (m *ShadowRequest) ID() string { return "ShadowRequest" }
+
+// HandleFinalize sends a copy of the request to the shadow endpoint if the
+// request is sampled, before continuing with the primary request.
+func (m *ShadowRequest) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	if m.sampleRate > 0 && m.random() < m.sampleRate {
+		shadow, err := m.newShadowRequest(req)
+		if err != nil {
+			middleware.GetLogger(ctx).Logf(logging.Debug, "failed to create shadow request, %v", err)
+		} else if shadow != nil {
+			if m.acquire() {
+				go m.send(smithycontext.WithSuppressCancel(ctx), shadow)
+			} else {
+				middleware.GetLogger(ctx).Logf(logging.Debug, "too many shadow requests in flight, not shadowing request")
+			}
+		}
+	}
+
+	return next.HandleFinalize(ctx, in)
+}
+
+// newShadowRequest returns a copy of the request targeting the shadow
+// endpoint, with its own copy of the request body. Returns nil if the request
+// cannot be shadowed.
+func (m *ShadowRequest) newShadowRequest(req *Request) (*Request, error) {
+	if req.GetStream() != nil && !req.IsStreamSeekable() {
+		return nil, nil
+	}
+
+	shadow := req.Clone()
+	shadow.URL.Scheme = m.endpoint.Scheme
+	shadow.URL.Host = m.endpoint.Host
+	shadow.Host = ""
+	for _, h := range shadowCredentialHeaders {
+		shadow.Header.Del(h)
+	}
+
+	if stream := req.GetStream(); stream != nil {
+		b, err := ioutil.ReadAll(stream)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy request stream, %w", err)
+		}
+		if err := req.RewindStream(); err != nil {
+			return nil, fmt.Errorf("failed to rewind request stream, %w", err)
+		}
+
+		shadow, err = shadow.SetStream(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return shadow, nil
+}
+
+// acquire reserves a shadow request in flight, returning false if
+// MaxInFlight shadow requests are already in flight.
+func (m *ShadowRequest) acquire() bool {
+	max := m.MaxInFlight
+	if max == 0 {
+		max = DefaultShadowRequestMaxInFlight
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.inFlight >= max {
+		return false
+	}
+	m.inFlight++
+	return true
+}
+
+func (m *ShadowRequest) release() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight--
+}
+
+func (m *ShadowRequest) send(ctx context.Context, req *Request) {
+	defer m.release()
+
+	client := m.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	timeout := m.Timeout
+	if timeout == 0 {
+		timeout = DefaultShadowRequestTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := client.Do(req.Build(ctx))
+	if err != nil {
+		middleware.GetLogger(ctx).Logf(logging.Debug, "shadow request failed, %v", err)
+		return
+	}
+	_ = DrainAndClose(resp.Body, DefaultMaxResponseBodyDrain)
+}
@@ -0,0 +1,216 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestShadowRequest(t *testing.T) {
+	type shadowed struct {
+		Method string
+		Path   string
+		Header string
+		Auth   string
+		Body   string
+	}
+	received := make(chan shadowed, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		received <- shadowed{
+			Method: r.Method,
+			Path:   r.URL.RequestURI(),
+			Header: r.Header.Get("X-Foo"),
+			Auth:   r.Header.Get("Authorization"),
+			Body:   string(b),
+		}
+		w.WriteHeader(500)
+	}))
+	defer server.Close()
+
+	m, err := NewShadowRequest(server.URL, 1.0)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	req := NewStackRequest().(*Request)
+	req.Method = "PUT"
+	req.URL, _ = url.Parse("https://primary.example.com/path?k=v")
+	req.Header.Set("X-Foo", "bar")
+	req.Header.Set("Authorization", "secret")
+	req, err = req.SetStream(strings.NewReader("hello world"))
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	out, _, err := m.HandleFinalize(context.Background(),
+		middleware.FinalizeInput{Request: req},
+		middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+			out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+		) {
+			r := in.Request.(*Request)
+			if e, a := "primary.example.com", r.URL.Host; e != a {
+				t.Errorf("expect primary request host %v, got %v", e, a)
+			}
+			b, err := ioutil.ReadAll(r.GetStream())
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := "hello world", string(b); e != a {
+				t.Errorf("expect primary request body %v, got %v", e, a)
+			}
+
+			out.Result = &Response{Response: &http.Response{StatusCode: 200}}
+			return out, metadata, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := 200, out.Result.(*Response).StatusCode; e != a {
+		t.Errorf("expect primary %v status code, got %v", e, a)
+	}
+
+	select {
+	case s := <-received:
+		expect := shadowed{
+			Method: "PUT",
+			Path:   "/path?k=v",
+			Header: "bar",
+			Body:   "hello world",
+		}
+		if e, a := expect, s; e != a {
+			t.Errorf("expect shadow request %v, got %v", e, a)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expect shadow request to be received")
+	}
+}
+
+func TestShadowRequest_Limits(t *testing.T) {
+	m, err := NewShadowRequest("https://shadow.example.com", 1.0)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	m.MaxInFlight = 1
+	m.Timeout = time.Minute
+
+	release := make(chan struct{})
+	sent := make(chan time.Duration, 2)
+	m.Client = ClientDoFunc(func(r *http.Request) (*http.Response, error) {
+		deadline, ok := r.Context().Deadline()
+		if !ok {
+			t.Errorf("expect shadow request deadline")
+		}
+		sent <- time.Until(deadline)
+		<-release
+		return nil, fmt.Errorf("shadow failed")
+	})
+
+	for i := 0; i < 2; i++ {
+		_, _, err = m.HandleFinalize(context.Background(),
+			middleware.FinalizeInput{Request: NewStackRequest()},
+			middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+				out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+			) {
+				return out, metadata, nil
+			}),
+		)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+	}
+
+	select {
+	case remaining := <-sent:
+		if remaining <= 0 || remaining > time.Minute {
+			t.Errorf("expect deadline within timeout, got %v", remaining)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expect shadow request to be sent")
+	}
+	close(release)
+
+	select {
+	case <-sent:
+		t.Errorf("expect only one shadow request in flight")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestAddShadowRequestMiddleware_BeforeSigning(t *testing.T) {
+	stack := middleware.NewStack("test", NewStackRequest)
+	stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("Signing",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+			out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+		) {
+			return next.HandleFinalize(ctx, in)
+		}), middleware.After)
+	stack.Finalize.Add(middleware.FinalizeMiddlewareFunc("other",
+		func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (
+			out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+		) {
+			return next.HandleFinalize(ctx, in)
+		}), middleware.After)
+
+	if err := AddShadowRequestMiddleware(stack, "https://shadow.example.com", 1.0); err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+
+	if e, a := "[ShadowRequest Signing other]", fmt.Sprint(stack.Finalize.List()); e != a {
+		t.Errorf("expect %v order, got %v", e, a)
+	}
+}
+
+func TestShadowRequest_NotSampled(t *testing.T) {
+	m, err := NewShadowRequest("https://shadow.example.com", 0.5)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	m.random = func() float64 { return 0.75 }
+	m.Client = ClientDoFunc(func(*http.Request) (*http.Response, error) {
+		t.Errorf("expect shadow request not to be sent")
+		return nil, nil
+	})
+
+	_, _, err = m.HandleFinalize(context.Background(),
+		middleware.FinalizeInput{Request: NewStackRequest()},
+		middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+			out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+		) {
+			return out, metadata, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+}
+
+func TestNewShadowRequest_Invalid(t *testing.T) {
+	cases := map[string]struct {
+		URL        string
+		SampleRate float64
+	}{
+		"no scheme":       {URL: "shadow.example.com", SampleRate: 1},
+		"rate too large":  {URL: "https://shadow.example.com", SampleRate: 1.5},
+		"negative rate":   {URL: "https://shadow.example.com", SampleRate: -1},
+		"unparsable host": {URL: "https://%zz", SampleRate: 1},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewShadowRequest(c.URL, c.SampleRate); err == nil {
+				t.Fatalf("expect error, got none")
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// StatusClass is the class of an HTTP response status code, for use as a
+// metric dimension.
+type StatusClass string
+
+// Enumeration of the status classes recorded by the RecordStatusClass
+// middleware.
+const (
+	StatusClassInformational StatusClass = "1xx"
+	StatusClassSuccess       StatusClass = "2xx"
+	StatusClassRedirect      StatusClass = "3xx"
+	StatusClassClientError   StatusClass = "4xx"
+	StatusClassServerError   StatusClass = "5xx"
+
+	// StatusClassUnknown is the class of status codes outside of the
+	// defined classes.
+	StatusClassUnknown StatusClass = "Unknown"
+
+	// StatusClassNoResponse is the class recorded when no response was
+	// received, (e.g. a network error).
+	StatusClassNoResponse StatusClass = "NoResponse"
+)
+
+// StatusClassOf returns the class of the HTTP status code.
+func StatusClassOf(statusCode int) StatusClass {
+	switch statusCode / 100 {
+	case 1:
+		return StatusClassInformational
+	case 2:
+		return StatusClassSuccess
+	case 3:
+		return StatusClassRedirect
+	case 4:
+		return StatusClassClientError
+	case 5:
+		return StatusClassServerError
+	default:
+		return StatusClassUnknown
+	}
+}
+
+type statusClassKey struct{}
+
+// GetStatusClass returns the status class of the operation's response,
+// recorded by the RecordStatusClass middleware. Returns false if the status
+// class was not recorded.
+func GetStatusClass(metadata middleware.Metadata) (StatusClass, bool) {
+	v, ok := metadata.Get(statusClassKey{}).(StatusClass)
+	return v, ok
+}
+
+// RecordStatusClass provides a deserialize middleware that records the class
+// of the response's status code, (e.g. 2xx), in the operation's metadata,
+// retrievable with GetStatusClass. If no response was received,
+// StatusClassNoResponse is recorded.
+type RecordStatusClass struct {
+	// Publish is invoked with the recorded status class of each attempt, if
+	// set, (e.g. to publish a metric with the class as a dimension).
+	Publish func(ctx context.Context, class StatusClass)
+}
+
+// AddRecordStatusClassMiddleware adds the RecordStatusClass middleware to the
+// end of the stack's Deserialize step, invoking publish with the recorded
+// status class. A nil publish only records the status class in the
+// operation's metadata.
+func AddRecordStatusClassMiddleware(stack *middleware.Stack, publish func(context.Context, StatusClass)) error {
+	return stack.Deserialize.Add(&RecordStatusClass{Publish: publish}, middleware.After)
+}
+
+// ID returns the identifier for the RecordStatusClass middleware.
+func (*RecordStatusClass) ID() string { return "RecordStatusClass" }
+
+// HandleDeserialize records the status class of the response returned by the
+// next handler.
+func (m *RecordStatusClass) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+
+	class := StatusClassNoResponse
+	if resp, ok := out.RawResponse.(*Response); ok && resp != nil && resp.Response != nil {
+		class = StatusClassOf(resp.StatusCode)
+	}
+
+	metadata.Set(statusClassKey{}, class)
+	if m.Publish != nil {
+		m.Publish(ctx, class)
+	}
+
+	return out, metadata, err
+}
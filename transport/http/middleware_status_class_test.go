@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestRecordStatusClass(t *testing.T) {
+	cases := map[string]struct {
+		StatusCode int
+		NoResponse bool
+		Err        error
+		Expect     StatusClass
+	}{
+		"ok": {
+			StatusCode: 200,
+			Expect:     StatusClassSuccess,
+		},
+		"no content": {
+			StatusCode: 204,
+			Expect:     StatusClassSuccess,
+		},
+		"not modified": {
+			StatusCode: 304,
+			Expect:     StatusClassRedirect,
+		},
+		"not found": {
+			StatusCode: 404,
+			Err:        fmt.Errorf("not found"),
+			Expect:     StatusClassClientError,
+		},
+		"throttled": {
+			StatusCode: 429,
+			Err:        fmt.Errorf("throttled"),
+			Expect:     StatusClassClientError,
+		},
+		"service unavailable": {
+			StatusCode: 503,
+			Err:        fmt.Errorf("unavailable"),
+			Expect:     StatusClassServerError,
+		},
+		"unknown status": {
+			StatusCode: 999,
+			Expect:     StatusClassUnknown,
+		},
+		"network error": {
+			NoResponse: true,
+			Err:        fmt.Errorf("connection reset"),
+			Expect:     StatusClassNoResponse,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			var published []StatusClass
+			m := &RecordStatusClass{
+				Publish: func(ctx context.Context, class StatusClass) {
+					published = append(published, class)
+				},
+			}
+
+			_, metadata, err := m.HandleDeserialize(context.Background(), middleware.DeserializeInput{},
+				middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					if !c.NoResponse {
+						out.RawResponse = &Response{Response: &http.Response{StatusCode: c.StatusCode}}
+					}
+					return out, metadata, c.Err
+				}),
+			)
+			if e, a := c.Err, err; e != a {
+				t.Errorf("expect %v error, got %v", e, a)
+			}
+
+			class, ok := GetStatusClass(metadata)
+			if !ok {
+				t.Fatalf("expect status class to be recorded")
+			}
+			if e, a := c.Expect, class; e != a {
+				t.Errorf("expect %v status class, got %v", e, a)
+			}
+			if e, a := []StatusClass{c.Expect}, published; len(a) != 1 || e[0] != a[0] {
+				t.Errorf("expect %v published, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestGetStatusClass_NotRecorded(t *testing.T) {
+	if _, ok := GetStatusClass(middleware.Metadata{}); ok {
+		t.Errorf("expect status class not to be recorded")
+	}
+}
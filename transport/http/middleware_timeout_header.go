@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// DefaultTimeoutHeader is the header the TimeoutHeader middleware writes the
+// remaining time to when no header is configured.
+const DefaultTimeoutHeader = "grpc-timeout"
+
+// maxTimeoutValue is the largest value of an encoded timeout, which is
+// limited to 8 digits.
+const maxTimeoutValue = 99999999
+
+var timeoutUnits = []struct {
+	unit     time.Duration
+	encoding string
+}{
+	{time.Nanosecond, "n"},
+	{time.Microsecond, "u"},
+	{time.Millisecond, "m"},
+	{time.Second, "S"},
+	{time.Minute, "M"},
+	{time.Hour, "H"},
+}
+
+// EncodeTimeout returns the timeout encoded in the gRPC-style timeout format,
+// a value of at most 8 digits followed by the unit, (e.g. 100m for 100
+// milliseconds). The most precise unit the value fits in is used. The value is
+// rounded up to the unit, so that the encoded timeout is never shorter than
+// the duration. Durations less than or equal to zero are encoded as 0n.
+func EncodeTimeout(d time.Duration) string {
+	if d <= 0 {
+		return "0n"
+	}
+
+	for _, u := range timeoutUnits {
+		v := (d + u.unit - 1) / u.unit
+		if v <= maxTimeoutValue {
+			return strconv.FormatInt(int64(v), 10) + u.encoding
+		}
+	}
+
+	return strconv.FormatInt(maxTimeoutValue, 10) + "H"
+}
+
+// TimeoutHeader provides a build middleware that writes the time remaining
+// until the Context's deadline to a header in the gRPC-style timeout format,
+// for gateways that propagate the client's deadline, see EncodeTimeout.
+// Requests whose Context has no deadline are not modified.
+type TimeoutHeader struct {
+	header string
+
+	// now returns the current time, used to compute the remaining time.
+	now func() time.Time
+}
+
+// NewTimeoutHeader returns an initialized TimeoutHeader middleware writing
+// the remaining time to header. If header is empty, DefaultTimeoutHeader is
+// used.
+func NewTimeoutHeader(header string) *TimeoutHeader {
+	if len(header) == 0 {
+		header = DefaultTimeoutHeader
+	}
+	return &TimeoutHeader{
+		header: header,
+		now:    time.Now,
+	}
+}
+
+// AddTimeoutHeaderMiddleware adds the TimeoutHeader middleware to the end of
+// the stack's Build step.
+func AddTimeoutHeaderMiddleware(stack *middleware.Stack, header string) error {
+	return stack.Build.Add(NewTimeoutHeader(header), middleware.After)
+}
+
+// ID returns the identifier for the TimeoutHeader middleware.
+func (*TimeoutHeader) ID() string { return "TimeoutHeader" }
+
+// HandleBuild writes the time remaining until the Context's deadline to the
+// request's header.
+func (m *TimeoutHeader) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		req.Header.Set(m.header, EncodeTimeout(deadline.Sub(m.now())))
+	}
+
+	return next.HandleBuild(ctx, in)
+}
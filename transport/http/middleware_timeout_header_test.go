@@ -0,0 +1,119 @@
+package http
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestEncodeTimeout(t *testing.T) {
+	cases := map[string]struct {
+		Duration time.Duration
+		Expect   string
+	}{
+		"nanoseconds": {
+			Duration: 500 * time.Nanosecond,
+			Expect:   "500n",
+		},
+		"largest nanoseconds": {
+			Duration: 99999999 * time.Nanosecond,
+			Expect:   "99999999n",
+		},
+		"microseconds": {
+			Duration: 100 * time.Millisecond,
+			Expect:   "100000u",
+		},
+		"microseconds rounded up": {
+			Duration: 100*time.Millisecond + 1,
+			Expect:   "100001u",
+		},
+		"milliseconds": {
+			Duration: 5 * time.Minute,
+			Expect:   "300000m",
+		},
+		"seconds": {
+			Duration: 48 * time.Hour,
+			Expect:   "172800S",
+		},
+		"minutes": {
+			Duration: 5 * 365 * 24 * time.Hour,
+			Expect:   "2628000M",
+		},
+		"zero": {
+			Expect: "0n",
+		},
+		"expired": {
+			Duration: -time.Second,
+			Expect:   "0n",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if e, a := c.Expect, EncodeTimeout(c.Duration); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestTimeoutHeader(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		Header       string
+		Deadline     time.Time
+		ExpectHeader string
+		Expect       string
+	}{
+		"default header": {
+			Deadline:     now.Add(250 * time.Millisecond),
+			ExpectHeader: DefaultTimeoutHeader,
+			Expect:       "250000u",
+		},
+		"custom header": {
+			Header:       "X-Request-Timeout",
+			Deadline:     now.Add(2 * time.Second),
+			ExpectHeader: "X-Request-Timeout",
+			Expect:       "2000000u",
+		},
+		"no deadline": {
+			ExpectHeader: DefaultTimeoutHeader,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if !c.Deadline.IsZero() {
+				var cancel func()
+				ctx, cancel = context.WithDeadline(ctx, c.Deadline)
+				defer cancel()
+			}
+
+			m := NewTimeoutHeader(c.Header)
+			m.now = func() time.Time { return now }
+
+			_, _, err := m.HandleBuild(ctx,
+				middleware.BuildInput{Request: NewStackRequest()},
+				middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+					out middleware.BuildOutput, metadata middleware.Metadata, err error,
+				) {
+					req := in.Request.(*Request)
+					if e, a := c.Expect, req.Header.Get(c.ExpectHeader); e != a {
+						t.Errorf("expect %q header, got %q", e, a)
+					}
+					if len(c.Expect) == 0 && len(req.Header) != 0 {
+						t.Errorf("expect no headers, got %v", req.Header)
+					}
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Defaults for the TraceSampling middleware headers.
+const (
+	DefaultTraceRequestIDHeader = "X-Request-Id"
+	DefaultTraceSampledHeader   = "X-Trace-Sampled"
+)
+
+type traceSamplingRatioKey struct{}
+
+// WithTraceSamplingRatio returns a copy of the Context with the ratio, between
+// 0.0 and 1.0, of requests the TraceSampling middleware will mark as sampled.
+func WithTraceSamplingRatio(ctx context.Context, ratio float64) context.Context {
+	return context.WithValue(ctx, traceSamplingRatioKey{}, ratio)
+}
+
+// GetTraceSamplingRatio returns the trace sampling ratio set on the Context,
+// and if the ratio was set.
+func GetTraceSamplingRatio(ctx context.Context) (float64, bool) {
+	v, ok := ctx.Value(traceSamplingRatioKey{}).(float64)
+	return v, ok
+}
+
+// TraceSampling provides a build middleware that makes a trace sampling
+// decision for the request, and sets the decision as a header of the request.
+// The sampling ratio is read from the Context, set with
+// WithTraceSamplingRatio. The decision is deterministic, based on the hash of
+// the request's ID header, so that all spans of a trace with the same request
+// ID agree on the decision.
+//
+// Requests without a sampling ratio on the Context, or without a request ID
+// header, are not modified. Returns an error if the sampling ratio is not
+// between 0.0 and 1.0.
+type TraceSampling struct {
+	// RequestIDHeader is the request header whose value the sampling decision
+	// is seeded by. Defaults to DefaultTraceRequestIDHeader if empty.
+	RequestIDHeader string
+
+	// SampledHeader is the header the decision is set as, "1" if sampled,
+	// otherwise "0". Defaults to DefaultTraceSampledHeader if empty.
+	SampledHeader string
+}
+
+// AddTraceSamplingMiddleware adds the TraceSampling middleware to the end of
+// the stack's Build step.
+func AddTraceSamplingMiddleware(stack *middleware.Stack, m *TraceSampling) error {
+	return stack.Build.Add(m, middleware.After)
+}
+
+// ID returns the identifier for the TraceSampling middleware.
+func (m *TraceSampling) ID() string { return "TraceSampling" }
+
+// HandleBuild sets the sampling decision header of the request.
+func (m *TraceSampling) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	ratio, ok := GetTraceSamplingRatio(ctx)
+	if !ok {
+		return next.HandleBuild(ctx, in)
+	}
+	if math.IsNaN(ratio) || ratio < 0 || ratio > 1 {
+		return out, metadata, fmt.Errorf("trace sampling ratio must be between 0.0 and 1.0, got %v", ratio)
+	}
+
+	requestIDHeader := m.RequestIDHeader
+	if len(requestIDHeader) == 0 {
+		requestIDHeader = DefaultTraceRequestIDHeader
+	}
+	requestID := req.Header.Get(requestIDHeader)
+	if len(requestID) == 0 {
+		return next.HandleBuild(ctx, in)
+	}
+
+	sampledHeader := m.SampledHeader
+	if len(sampledHeader) == 0 {
+		sampledHeader = DefaultTraceSampledHeader
+	}
+
+	v := "0"
+	if IsTraceSampled(requestID, ratio) {
+		v = "1"
+	}
+	req.Header.Set(sampledHeader, v)
+
+	return next.HandleBuild(ctx, in)
+}
+
+// IsTraceSampled returns the deterministic sampling decision for the request
+// ID and sampling ratio. The same request ID and ratio always return the same
+// decision.
+func IsTraceSampled(requestID string, ratio float64) bool {
+	if ratio <= 0 {
+		return false
+	}
+	if ratio >= 1 {
+		return true
+	}
+
+	sum := sha256.Sum256([]byte(requestID))
+	v := binary.BigEndian.Uint64(sum[:8])
+
+	return float64(v)/math.MaxUint64 < ratio
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+	"github.com/aws/smithy-go/ptr"
+)
+
+func TestTraceSampling(t *testing.T) {
+	cases := map[string]struct {
+		Ratio     *float64
+		RequestID string
+		Expect    string
+		ExpectErr bool
+	}{
+		"no ratio": {
+			RequestID: "abc123",
+		},
+		"no request id": {
+			Ratio: ptr.Float64(1),
+		},
+		"always sampled": {
+			Ratio:     ptr.Float64(1),
+			RequestID: "abc123",
+			Expect:    "1",
+		},
+		"never sampled": {
+			Ratio:     ptr.Float64(0),
+			RequestID: "abc123",
+			Expect:    "0",
+		},
+		"ratio too large": {
+			Ratio:     ptr.Float64(1.5),
+			RequestID: "abc123",
+			ExpectErr: true,
+		},
+		"negative ratio": {
+			Ratio:     ptr.Float64(-0.1),
+			RequestID: "abc123",
+			ExpectErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if c.Ratio != nil {
+				ctx = WithTraceSamplingRatio(ctx, *c.Ratio)
+			}
+
+			req := NewStackRequest().(*Request)
+			if len(c.RequestID) != 0 {
+				req.Header.Set(DefaultTraceRequestIDHeader, c.RequestID)
+			}
+
+			m := &TraceSampling{}
+			_, _, err := m.HandleBuild(ctx, middleware.BuildInput{Request: req},
+				middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+					out middleware.BuildOutput, metadata middleware.Metadata, err error,
+				) {
+					r := in.Request.(*Request)
+					if e, a := c.Expect, r.Header.Get(DefaultTraceSampledHeader); e != a {
+						t.Errorf("expect %q sampled header, got %q", e, a)
+					}
+					return out, metadata, nil
+				}),
+			)
+			if c.ExpectErr {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestIsTraceSampled(t *testing.T) {
+	const ratio = 0.25
+	const requests = 1000
+
+	var sampled int
+	for i := 0; i < requests; i++ {
+		requestID := fmt.Sprintf("request-%d", i)
+
+		decision := IsTraceSampled(requestID, ratio)
+		for j := 0; j < 3; j++ {
+			if e, a := decision, IsTraceSampled(requestID, ratio); e != a {
+				t.Fatalf("expect consistent decision %v for %v, got %v", e, requestID, a)
+			}
+		}
+		if decision {
+			sampled++
+		}
+	}
+
+	// The decisions should approximate the ratio.
+	if sampled < 150 || sampled > 350 {
+		t.Errorf("expect approximately %v sampled requests, got %v", ratio*requests, sampled)
+	}
+}
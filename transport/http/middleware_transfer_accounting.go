@@ -0,0 +1,153 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// TransferCounter accumulates the number of request and response body bytes
+// transferred by the operations using the TransferAccounting middleware. Safe
+// for concurrent use, and may be shared between operations and clients.
+type TransferCounter struct {
+	// accessed atomically, must be first for 64-bit alignment.
+	sent     int64
+	received int64
+}
+
+// BytesSent returns the number of request body bytes sent.
+func (c *TransferCounter) BytesSent() int64 {
+	return atomic.LoadInt64(&c.sent)
+}
+
+// BytesReceived returns the number of response body bytes received.
+func (c *TransferCounter) BytesReceived() int64 {
+	return atomic.LoadInt64(&c.received)
+}
+
+// Total returns the number of request and response body bytes transferred.
+func (c *TransferCounter) Total() int64 {
+	return c.BytesSent() + c.BytesReceived()
+}
+
+// TransferCapExceededError is returned when the cumulative number of bytes
+// transferred exceeds the TransferAccounting middleware's cap.
+type TransferCapExceededError struct {
+	Cap int64
+}
+
+func (e *TransferCapExceededError) Error() string {
+	return fmt.Sprintf("cumulative bytes transferred exceeded cap of %d bytes", e.Cap)
+}
+
+// RetryableError returns false, since retrying the request would only
+// transfer more bytes.
+func (e *TransferCapExceededError) RetryableError() bool { return false }
+
+// TransferAccounting provides a deserialize middleware that counts the
+// request and response body bytes transferred by each request attempt,
+// accumulating them into Counter.
+//
+// If Cap is greater than zero, the request fails with a
+// TransferCapExceededError once the Counter's total exceeds Cap. Requests are
+// not sent if the cap has already been exceeded.
+type TransferAccounting struct {
+	Counter *TransferCounter
+	Cap     int64
+}
+
+// AddTransferAccountingMiddleware adds the TransferAccounting middleware to
+// the end of the stack's Deserialize step, so that the bytes of the request
+// sent and response received are counted.
+func AddTransferAccountingMiddleware(stack *middleware.Stack, m *TransferAccounting) error {
+	if m.Counter == nil {
+		return fmt.Errorf("transfer accounting counter must be set")
+	}
+	return stack.Deserialize.Add(m, middleware.After)
+}
+
+// ID returns the identifier for the TransferAccounting middleware.
+func (*TransferAccounting) ID() string { return "TransferAccounting" }
+
+// HandleDeserialize wraps the request and response bodies to count the bytes
+// transferred.
+func (m *TransferAccounting) HandleDeserialize(
+	ctx context.Context, in middleware.DeserializeInput, next middleware.DeserializeHandler,
+) (
+	out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	if err := m.checkCap(); err != nil {
+		return out, metadata, err
+	}
+
+	if stream := req.GetStream(); stream != nil {
+		// The stream is only replaced on a copy of the request for this
+		// attempt, leaving the original stream rewindable.
+		req, err = req.SetStream(&countingReader{
+			Reader:     stream,
+			count:      &m.Counter.sent,
+			accounting: m,
+		})
+		if err != nil {
+			return out, metadata, err
+		}
+		in.Request = req
+	}
+
+	out, metadata, err = next.HandleDeserialize(ctx, in)
+
+	if resp, ok := out.RawResponse.(*Response); ok && resp != nil && resp.Body != nil {
+		resp.Body = &countingReadCloser{
+			countingReader: countingReader{
+				Reader:     resp.Body,
+				count:      &m.Counter.received,
+				accounting: m,
+			},
+			Closer: resp.Body,
+		}
+	}
+	if err != nil {
+		return out, metadata, err
+	}
+
+	return out, metadata, m.checkCap()
+}
+
+func (m *TransferAccounting) checkCap() error {
+	if m.Cap > 0 && m.Counter.Total() > m.Cap {
+		return &TransferCapExceededError{Cap: m.Cap}
+	}
+	return nil
+}
+
+// countingReader adds the number of bytes read to count, returning a
+// TransferCapExceededError once the accounting cap is exceeded.
+type countingReader struct {
+	io.Reader
+	count      *int64
+	accounting *TransferAccounting
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		atomic.AddInt64(r.count, int64(n))
+		if capErr := r.accounting.checkCap(); capErr != nil {
+			return n, capErr
+		}
+	}
+	return n, err
+}
+
+type countingReadCloser struct {
+	countingReader
+	io.Closer
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestTransferAccounting(t *testing.T) {
+	const (
+		requestBody  = "hello"
+		responseBody = "hello world!"
+	)
+
+	cases := map[string]struct {
+		Cap               int64
+		ExpectSent        int64
+		ExpectReceived    int64
+		ExpectRequestErr  bool
+		ExpectResponseErr bool
+	}{
+		"no cap": {
+			ExpectSent:     5,
+			ExpectReceived: 12,
+		},
+		"under cap": {
+			Cap:            17,
+			ExpectSent:     5,
+			ExpectReceived: 12,
+		},
+		"response exceeds cap": {
+			Cap:               10,
+			ExpectSent:        5,
+			ExpectReceived:    12,
+			ExpectResponseErr: true,
+		},
+		"request exceeds cap": {
+			Cap:              3,
+			ExpectSent:       5,
+			ExpectRequestErr: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := &TransferAccounting{Counter: &TransferCounter{}, Cap: c.Cap}
+
+			req := NewStackRequest().(*Request)
+			req, err := req.SetStream(strings.NewReader(requestBody))
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			out, _, err := m.HandleDeserialize(context.Background(),
+				middleware.DeserializeInput{Request: req},
+				middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+					out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+				) {
+					b, err := ioutil.ReadAll(in.Request.(*Request).GetStream())
+					if err != nil {
+						return out, metadata, err
+					}
+					if e, a := requestBody, string(b); e != a {
+						t.Errorf("expect request body %v, got %v", e, a)
+					}
+
+					out.RawResponse = &Response{Response: &http.Response{
+						StatusCode: 200,
+						Body:       ioutil.NopCloser(strings.NewReader(responseBody)),
+					}}
+					return out, metadata, nil
+				}),
+			)
+
+			var capErr *TransferCapExceededError
+			if c.ExpectRequestErr {
+				if !errors.As(err, &capErr) {
+					t.Fatalf("expect %T error, got %v", capErr, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+
+				body := out.RawResponse.(*Response).Body
+				b, err := ioutil.ReadAll(body)
+				body.Close()
+				if c.ExpectResponseErr {
+					if !errors.As(err, &capErr) {
+						t.Fatalf("expect %T error, got %v", capErr, err)
+					}
+				} else {
+					if err != nil {
+						t.Fatalf("expect no error, got %v", err)
+					}
+					if e, a := responseBody, string(b); e != a {
+						t.Errorf("expect response body %v, got %v", e, a)
+					}
+				}
+			}
+
+			if e, a := c.ExpectSent, m.Counter.BytesSent(); e != a {
+				t.Errorf("expect %v bytes sent, got %v", e, a)
+			}
+			if e, a := c.ExpectReceived, m.Counter.BytesReceived(); e != a {
+				t.Errorf("expect %v bytes received, got %v", e, a)
+			}
+			if e, a := c.ExpectSent+c.ExpectReceived, m.Counter.Total(); e != a {
+				t.Errorf("expect %v bytes total, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestTransferAccounting_CapAlreadyExceeded(t *testing.T) {
+	m := &TransferAccounting{Counter: &TransferCounter{received: 100}, Cap: 10}
+
+	_, _, err := m.HandleDeserialize(context.Background(),
+		middleware.DeserializeInput{Request: NewStackRequest()},
+		middleware.DeserializeHandlerFunc(func(ctx context.Context, in middleware.DeserializeInput) (
+			out middleware.DeserializeOutput, metadata middleware.Metadata, err error,
+		) {
+			t.Errorf("expect request not to be sent")
+			return out, metadata, nil
+		}),
+	)
+
+	var capErr *TransferCapExceededError
+	if !errors.As(err, &capErr) {
+		t.Fatalf("expect %T error, got %v", capErr, err)
+	}
+}
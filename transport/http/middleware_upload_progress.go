@@ -0,0 +1,127 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// ProgressFunc is called with the number of bytes transferred so far, and
+// the total number of bytes to be transferred, or -1 if the total is unknown.
+type ProgressFunc func(transferred, total int64)
+
+// UploadProgress provides a build middleware that reports the progress of
+// sending the request body, invoking the callback as bytes of the body are
+// read by the HTTP client. The request's Content-Length is used as the total,
+// or -1 if unknown.
+//
+// The request body remains seekable if it was seekable, so that the body can
+// be rewound for retries. Rewinding the body resets the progress reported.
+type UploadProgress struct {
+	callback ProgressFunc
+}
+
+// NewUploadProgress returns an initialized UploadProgress middleware invoking
+// cb with the bytes sent, and total bytes of the request body.
+func NewUploadProgress(cb func(bytesSent, total int64)) *UploadProgress {
+	return &UploadProgress{
+		callback: cb,
+	}
+}
+
+// AddUploadProgressMiddleware adds the UploadProgress middleware to the end
+// of the stack's Build step, so that the request's Content-Length is known.
+func AddUploadProgressMiddleware(stack *middleware.Stack, cb func(bytesSent, total int64)) error {
+	return stack.Build.Add(NewUploadProgress(cb), middleware.After)
+}
+
+// ID returns the identifier for the UploadProgress middleware.
+func (*UploadProgress) ID() string { return "UploadProgress" }
+
+// HandleBuild wraps the request body to report the upload progress.
+func (m *UploadProgress) HandleBuild(
+	ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+) (
+	out middleware.BuildOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	stream := req.GetStream()
+	if stream == nil || m.callback == nil {
+		return next.HandleBuild(ctx, in)
+	}
+
+	total := req.ContentLength
+	if total < 0 {
+		total = -1
+	}
+
+	var reader io.Reader = &progressReader{
+		Reader:   stream,
+		total:    total,
+		callback: m.callback,
+	}
+	if req.IsStreamSeekable() {
+		seeker := stream.(io.Seeker)
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return out, metadata, fmt.Errorf("failed to get request body position, %w", err)
+		}
+		reader = &progressReadSeeker{
+			progressReader: reader.(*progressReader),
+			seeker:         seeker,
+			start:          start,
+		}
+	}
+
+	contentLength := req.ContentLength
+	if req, err = req.SetStream(reader); err != nil {
+		return out, metadata, fmt.Errorf("failed to wrap request body for upload progress, %w", err)
+	}
+	req.ContentLength = contentLength
+	in.Request = req
+
+	return next.HandleBuild(ctx, in)
+}
+
+// progressReader reports the number of bytes read to the callback.
+type progressReader struct {
+	io.Reader
+	read     int64
+	total    int64
+	callback ProgressFunc
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if n > 0 {
+		r.read += int64(n)
+		r.callback(r.read, r.total)
+	}
+	return n, err
+}
+
+// progressReadSeeker is a progressReader of a seekable reader. Seeking resets
+// the bytes read to the reader's position relative to its starting position.
+type progressReadSeeker struct {
+	*progressReader
+	seeker io.Seeker
+	start  int64
+}
+
+func (r *progressReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	pos, err := r.seeker.Seek(offset, whence)
+	if err != nil {
+		return pos, err
+	}
+	r.read = pos - r.start
+	if r.read < 0 {
+		r.read = 0
+	}
+	return pos, nil
+}
@@ -0,0 +1,101 @@
+package http
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestUploadProgress(t *testing.T) {
+	const body = "hello world"
+
+	cases := map[string]struct {
+		Stream        io.Reader
+		ContentLength int64
+		ExpectTotal   int64
+		Rewind        bool
+	}{
+		"known length": {
+			Stream:        strings.NewReader(body),
+			ContentLength: int64(len(body)),
+			ExpectTotal:   int64(len(body)),
+		},
+		"unknown length": {
+			Stream:        iotest.HalfReader(strings.NewReader(body)),
+			ContentLength: -1,
+			ExpectTotal:   -1,
+		},
+		"rewound": {
+			Stream:        strings.NewReader(body),
+			ContentLength: int64(len(body)),
+			ExpectTotal:   int64(len(body)),
+			Rewind:        true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			req, err := req.SetStream(c.Stream)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			req.ContentLength = c.ContentLength
+
+			var sent []int64
+			m := NewUploadProgress(func(bytesSent, total int64) {
+				if e, a := c.ExpectTotal, total; e != a {
+					t.Errorf("expect %v total, got %v", e, a)
+				}
+				sent = append(sent, bytesSent)
+			})
+
+			_, _, err = m.HandleBuild(context.Background(), middleware.BuildInput{Request: req},
+				middleware.BuildHandlerFunc(func(ctx context.Context, in middleware.BuildInput) (
+					out middleware.BuildOutput, metadata middleware.Metadata, err error,
+				) {
+					r := in.Request.(*Request)
+					if e, a := c.ContentLength, r.ContentLength; e != a {
+						t.Errorf("expect %v content length, got %v", e, a)
+					}
+
+					if c.Rewind {
+						if _, err := r.GetStream().Read(make([]byte, 5)); err != nil {
+							t.Fatalf("expect no error, got %v", err)
+						}
+						if err := r.RewindStream(); err != nil {
+							t.Fatalf("expect no error, got %v", err)
+						}
+						sent = nil
+					}
+
+					b, err := ioutil.ReadAll(iotest.OneByteReader(r.GetStream()))
+					if err != nil {
+						t.Fatalf("expect no error, got %v", err)
+					}
+					if e, a := body, string(b); e != a {
+						t.Errorf("expect %v body, got %v", e, a)
+					}
+					return out, metadata, nil
+				}),
+			)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := len(body), len(sent); e != a {
+				t.Fatalf("expect %v progress callbacks, got %v", e, a)
+			}
+			for i, n := range sent {
+				if e, a := int64(i+1), n; e != a {
+					t.Errorf("expect callback %d to report %v bytes sent, got %v", i, e, a)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// InvalidURLSchemeError is returned by the URLScheme middleware when the
+// request URL's scheme is empty, or not supported.
+type InvalidURLSchemeError struct {
+	Scheme string
+}
+
+// Error returns the error message.
+func (e *InvalidURLSchemeError) Error() string {
+	if len(e.Scheme) == 0 {
+		return "request URL scheme is empty, endpoint must include a scheme, (e.g. https://)"
+	}
+	return fmt.Sprintf("request URL scheme %q is not supported", e.Scheme)
+}
+
+// URLScheme provides a finalize middleware that normalizes the request URL's
+// scheme to lower case, and validates the scheme is supported. Mistakenly
+// configured endpoints, (e.g. missing a scheme), cause the request to fail
+// with an InvalidURLSchemeError instead of an error from the HTTP client.
+type URLScheme struct {
+	// Schemes is the list of supported schemes. Defaults to http and https if
+	// empty.
+	Schemes []string
+}
+
+// AddURLSchemeMiddleware adds the URLScheme middleware to the end of the
+// stack's Finalize step.
+func AddURLSchemeMiddleware(stack *middleware.Stack, schemes ...string) error {
+	return stack.Finalize.Add(&URLScheme{Schemes: schemes}, middleware.After)
+}
+
+// ID returns the identifier for the URLScheme middleware.
+func (m *URLScheme) ID() string { return "URLScheme" }
+
+// HandleFinalize normalizes and validates the request URL's scheme.
+func (m *URLScheme) HandleFinalize(
+	ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler,
+) (
+	out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+) {
+	req, ok := in.Request.(*Request)
+	if !ok {
+		return out, metadata, fmt.Errorf("unknown request type %T", in.Request)
+	}
+
+	scheme := strings.ToLower(req.URL.Scheme)
+	if len(scheme) == 0 || !m.isSupported(scheme) {
+		return out, metadata, &InvalidURLSchemeError{Scheme: req.URL.Scheme}
+	}
+	req.URL.Scheme = scheme
+
+	return next.HandleFinalize(ctx, in)
+}
+
+func (m *URLScheme) isSupported(scheme string) bool {
+	schemes := m.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+
+	for _, s := range schemes {
+		if strings.EqualFold(s, scheme) {
+			return true
+		}
+	}
+	return false
+}
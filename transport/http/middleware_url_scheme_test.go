@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestURLScheme(t *testing.T) {
+	cases := map[string]struct {
+		URL       string
+		Schemes   []string
+		Expect    string
+		ExpectErr string
+	}{
+		"lowercase": {
+			URL:    "https://example.com",
+			Expect: "https",
+		},
+		"uppercase": {
+			URL:    "HTTP://example.com",
+			Expect: "http",
+		},
+		"mixed case": {
+			URL:    "HttPs://example.com",
+			Expect: "https",
+		},
+		"missing scheme": {
+			URL:       "example.com",
+			ExpectErr: "scheme is empty",
+		},
+		"unsupported scheme": {
+			URL:       "ftp://example.com",
+			ExpectErr: `"ftp" is not supported`,
+		},
+		"custom scheme": {
+			URL:     "WSS://example.com",
+			Schemes: []string{"wss"},
+			Expect:  "wss",
+		},
+		"custom schemes exclude default": {
+			URL:       "https://example.com",
+			Schemes:   []string{"wss"},
+			ExpectErr: `"https" is not supported`,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			req.URL, _ = url.Parse(c.URL)
+
+			var called bool
+			m := URLScheme{Schemes: c.Schemes}
+			_, _, err := m.HandleFinalize(context.Background(),
+				middleware.FinalizeInput{Request: req},
+				middleware.FinalizeHandlerFunc(func(ctx context.Context, in middleware.FinalizeInput) (
+					out middleware.FinalizeOutput, metadata middleware.Metadata, err error,
+				) {
+					called = true
+					if e, a := c.Expect, in.Request.(*Request).URL.Scheme; e != a {
+						t.Errorf("expect %q scheme, got %q", e, a)
+					}
+					return out, metadata, nil
+				}),
+			)
+
+			if len(c.ExpectErr) != 0 {
+				var schemeErr *InvalidURLSchemeError
+				if !errors.As(err, &schemeErr) {
+					t.Fatalf("expect %T error, got %v", schemeErr, err)
+				}
+				if e, a := c.ExpectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expect error to contain %q, got %q", e, a)
+				}
+				if called {
+					t.Errorf("expect request not to be sent")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+		})
+	}
+}
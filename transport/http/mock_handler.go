@@ -0,0 +1,47 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+// MockHandler provides a test double for the ClientHandler, returning fixed
+// responses for operations without sending the request over the network. The
+// response is selected by the operation name of the Context, see
+// middleware.GetOperationName.
+//
+// The same Response value is returned for every invocation of an operation.
+// The response's body will only be readable by the first invocation, unless
+// it is replaced between calls.
+type MockHandler struct {
+	responses map[string]*Response
+}
+
+// NewMockHandler returns an initialized MockHandler returning the responses
+// mapped by operation name.
+func NewMockHandler(responses map[string]*Response) MockHandler {
+	return MockHandler{
+		responses: responses,
+	}
+}
+
+// Handle implements the middleware Handler interface, returning the response
+// mapped to the Context's operation name. Requires the input to be a Smithy
+// *Request. Returns an error if no response is mapped for the operation.
+func (h MockHandler) Handle(ctx context.Context, input interface{}) (
+	out interface{}, metadata middleware.Metadata, err error,
+) {
+	if _, ok := input.(*Request); !ok {
+		return nil, metadata, fmt.Errorf("expect Smithy http.Request value as input, got unsupported type %T", input)
+	}
+
+	name := middleware.GetOperationName(ctx)
+	resp, ok := h.responses[name]
+	if !ok {
+		return nil, metadata, fmt.Errorf("no mock response for operation %q", name)
+	}
+
+	return resp, metadata, nil
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go/middleware"
+)
+
+func TestMockHandler(t *testing.T) {
+	handler := NewMockHandler(map[string]*Response{
+		"GetThing": {Response: &http.Response{
+			StatusCode: 200,
+			Body:       ioutil.NopCloser(strings.NewReader("thing")),
+		}},
+		"DeleteThing": {Response: &http.Response{
+			StatusCode: 204,
+			Body:       http.NoBody,
+		}},
+	})
+
+	cases := map[string]struct {
+		Operation    string
+		Input        interface{}
+		ExpectStatus int
+		ExpectBody   string
+		ExpectErr    string
+	}{
+		"mapped operation": {
+			Operation:    "GetThing",
+			Input:        NewStackRequest(),
+			ExpectStatus: 200,
+			ExpectBody:   "thing",
+		},
+		"mapped operation no body": {
+			Operation:    "DeleteThing",
+			Input:        NewStackRequest(),
+			ExpectStatus: 204,
+		},
+		"unmapped operation": {
+			Operation: "PutThing",
+			Input:     NewStackRequest(),
+			ExpectErr: `no mock response for operation "PutThing"`,
+		},
+		"unsupported input": {
+			Operation: "GetThing",
+			Input:     "not a request",
+			ExpectErr: "unsupported type",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx := middleware.WithOperationName(context.Background(), c.Operation)
+
+			out, _, err := handler.Handle(ctx, c.Input)
+			if len(c.ExpectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				if e, a := c.ExpectErr, err.Error(); !strings.Contains(a, e) {
+					t.Errorf("expect %q in error, got %q", e, a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			resp := out.(*Response)
+			if e, a := c.ExpectStatus, resp.StatusCode; e != a {
+				t.Errorf("expect %v status code, got %v", e, a)
+			}
+			b, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := c.ExpectBody, string(b); e != a {
+				t.Errorf("expect %v body, got %v", e, a)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// maxPooledBufferSize is the maximum capacity of a buffer that will be
+// returned to the pool. Larger buffers are left for the garbage collector, so
+// that occasional large responses do not keep large buffers alive.
+const maxPooledBufferSize = 64 * 1024
+
+// bufferPool provides the interface for the pool of buffers PooledBody reads
+// response bodies into.
+type bufferPool interface {
+	Get() *bytes.Buffer
+	Put(*bytes.Buffer)
+}
+
+type syncBufferPool struct {
+	pool sync.Pool
+}
+
+func (p *syncBufferPool) Get() *bytes.Buffer {
+	if b, ok := p.pool.Get().(*bytes.Buffer); ok {
+		return b
+	}
+	return new(bytes.Buffer)
+}
+
+func (p *syncBufferPool) Put(b *bytes.Buffer) {
+	p.pool.Put(b)
+}
+
+var responseBufferPool bufferPool = &syncBufferPool{}
+
+// errPooledBodyClosed is returned by reads of a PooledBody after it was
+// closed.
+var errPooledBodyClosed = fmt.Errorf("read on closed pooled response body")
+
+// PooledBody provides a response body that has been read into a buffer from a
+// shared pool, reducing allocations for small responses. Closing the body
+// returns the buffer to the pool. The body must not be used after it is
+// closed, reads after close return an error.
+type PooledBody struct {
+	mu  sync.Mutex
+	buf *bytes.Buffer
+}
+
+// NewPooledBody reads the body into a pooled buffer and closes it, returning
+// a PooledBody with the content. Returns an error if the body could not be
+// read, or its length exceeds maxSize bytes. The buffer is returned to the
+// pool if an error occurs.
+func NewPooledBody(body io.ReadCloser, maxSize int64) (*PooledBody, error) {
+	defer body.Close()
+
+	buf := responseBufferPool.Get()
+	buf.Reset()
+
+	n, err := buf.ReadFrom(io.LimitReader(body, maxSize+1))
+	if err != nil {
+		putResponseBuffer(buf)
+		return nil, fmt.Errorf("failed to read response body, %w", err)
+	}
+	if n > maxSize {
+		putResponseBuffer(buf)
+		return nil, fmt.Errorf("response body exceeds maximum pooled buffer size of %d bytes", maxSize)
+	}
+
+	return &PooledBody{buf: buf}, nil
+}
+
+// Read reads the next bytes of the body. Returns an error if the body has
+// been closed.
+func (b *PooledBody) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.buf == nil {
+		return 0, errPooledBodyClosed
+	}
+	return b.buf.Read(p)
+}
+
+// Len returns the number of unread bytes of the body, or zero if the body has
+// been closed.
+func (b *PooledBody) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.buf == nil {
+		return 0
+	}
+	return b.buf.Len()
+}
+
+// Close returns the body's buffer to the pool. Close may be called multiple
+// times.
+func (b *PooledBody) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.buf != nil {
+		putResponseBuffer(b.buf)
+		b.buf = nil
+	}
+	return nil
+}
+
+func putResponseBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	responseBufferPool.Put(buf)
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// recordingBufferPool is a bufferPool that records the buffers returned to
+// it, and reuses them in last in, first out order.
+type recordingBufferPool struct {
+	buffers []*bytes.Buffer
+	puts    int
+}
+
+func (p *recordingBufferPool) Get() *bytes.Buffer {
+	if n := len(p.buffers); n > 0 {
+		b := p.buffers[n-1]
+		p.buffers = p.buffers[:n-1]
+		return b
+	}
+	return new(bytes.Buffer)
+}
+
+func (p *recordingBufferPool) Put(b *bytes.Buffer) {
+	p.puts++
+	p.buffers = append(p.buffers, b)
+}
+
+func TestPooledBody(t *testing.T) {
+	pool := &recordingBufferPool{}
+	orig := responseBufferPool
+	responseBufferPool = pool
+	defer func() { responseBufferPool = orig }()
+
+	bodies := []string{"first response body", "second"}
+
+	var buffers []*bytes.Buffer
+	for _, content := range bodies {
+		body, err := NewPooledBody(ioutil.NopCloser(strings.NewReader(content)), 1024)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		buffers = append(buffers, body.buf)
+
+		b, err := ioutil.ReadAll(body)
+		if err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		if e, a := content, string(b); e != a {
+			t.Errorf("expect %q body, got %q", e, a)
+		}
+
+		if err := body.Close(); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+		// Close is idempotent, and must not return the buffer twice.
+		if err := body.Close(); err != nil {
+			t.Fatalf("expect no error, got %v", err)
+		}
+
+		if _, err := body.Read(make([]byte, 1)); err == nil {
+			t.Errorf("expect error reading closed body, got none")
+		}
+		if e, a := 0, body.Len(); e != a {
+			t.Errorf("expect %v length of closed body, got %v", e, a)
+		}
+	}
+
+	if e, a := buffers[0], buffers[1]; e != a {
+		t.Errorf("expect buffer to be reused across responses")
+	}
+	if e, a := 2, pool.puts; e != a {
+		t.Errorf("expect %v buffers returned to pool, got %v", e, a)
+	}
+}
+
+func TestPooledBody_TooLarge(t *testing.T) {
+	pool := &recordingBufferPool{}
+	orig := responseBufferPool
+	responseBufferPool = pool
+	defer func() { responseBufferPool = orig }()
+
+	_, err := NewPooledBody(ioutil.NopCloser(strings.NewReader("too large")), 4)
+	if err == nil {
+		t.Fatalf("expect error, got none")
+	}
+	if e, a := 1, pool.puts; e != a {
+		t.Errorf("expect %v buffers returned to pool, got %v", e, a)
+	}
+}
@@ -90,6 +90,31 @@ func streamLength(stream io.Reader, seekable bool, startPos int64) (size int64,
 	return endOffset - startPos, true, nil
 }
 
+// SetContentLength sets the request's content length to the known length of
+// the request's stream. If the stream's length can be determined, (e.g. the
+// stream is seekable) the provided length must match it. Setting an accurate
+// content length prevents the request from being sent with chunked transfer
+// encoding.
+//
+// Returns an error if the length is negative, or does not match the length of
+// the stream.
+func (r *Request) SetContentLength(n int64) error {
+	if n < 0 {
+		return fmt.Errorf("content length must not be negative, got %d", n)
+	}
+
+	size, ok, err := r.StreamLength()
+	if err != nil {
+		return fmt.Errorf("failed getting length of request stream, %w", err)
+	}
+	if ok && size != n {
+		return fmt.Errorf("content length %d does not match request stream length %d", n, size)
+	}
+
+	r.ContentLength = n
+	return nil
+}
+
 // RewindStream will rewind the io.Reader to the relative start position if it
 // is an io.Seeker.
 func (r *Request) RewindStream() error {
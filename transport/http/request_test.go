@@ -219,3 +219,71 @@ func TestRequestSetStream(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestSetContentLength(t *testing.T) {
+	cases := map[string]struct {
+		Stream    io.Reader
+		Length    int64
+		ExpectErr string
+	}{
+		"seekable stream": {
+			Stream: strings.NewReader("abc123"),
+			Length: 6,
+		},
+		"seekable stream mismatch": {
+			Stream:    strings.NewReader("abc123"),
+			Length:    10,
+			ExpectErr: "does not match request stream length 6",
+		},
+		"unknown length stream": {
+			Stream: &basicReader{buf: make([]byte, 10)},
+			Length: 10,
+		},
+		"nil stream": {
+			Length: 0,
+		},
+		"nil stream mismatch": {
+			Length:    5,
+			ExpectErr: "does not match request stream length 0",
+		},
+		"negative length": {
+			Stream:    strings.NewReader("abc123"),
+			Length:    -1,
+			ExpectErr: "must not be negative",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := NewStackRequest().(*Request)
+			req, err := req.SetStream(c.Stream)
+			if err != nil {
+				t.Fatalf("expect no error setting stream, %v", err)
+			}
+
+			err = req.SetContentLength(c.Length)
+			if len(c.ExpectErr) != 0 {
+				if err == nil {
+					t.Fatalf("expect error, got none")
+				}
+				if e, a := c.ExpectErr, err.Error(); !strings.Contains(a, e) {
+					t.Fatalf("expect error to contain %v, got %v", e, a)
+				}
+				if e, a := int64(-1), req.ContentLength; e != a {
+					t.Errorf("expect content length to be unchanged, got %v", a)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+
+			if e, a := c.Length, req.ContentLength; e != a {
+				t.Errorf("expect %v content length, got %v", e, a)
+			}
+			if e, a := c.Length, req.Build(context.Background()).ContentLength; e != a {
+				t.Errorf("expect %v built content length, got %v", e, a)
+			}
+		})
+	}
+}
@@ -11,6 +11,33 @@ type Response struct {
 	*http.Response
 }
 
+// NewResponseFromStdlib returns a Response wrapping the standard library HTTP
+// response, for responses received outside of the smithy ClientHandler to be
+// deserialized by smithy deserialize middleware. The response's status,
+// headers, and body are used directly, and are not copied.
+//
+// A nil Header is replaced with an empty Header, and a nil Body with
+// http.NoBody, matching the responses returned by the ClientHandler.
+func NewResponseFromStdlib(resp *http.Response) *Response {
+	if resp.Header == nil {
+		resp.Header = http.Header{}
+	}
+	if resp.Body == nil {
+		resp.Body = http.NoBody
+	}
+
+	return &Response{Response: resp}
+}
+
+// HTTPStatusCode returns the status code of the response, or 0 if the
+// response does not wrap an HTTP response.
+func (r *Response) HTTPStatusCode() int {
+	if r == nil || r.Response == nil {
+		return 0
+	}
+	return r.StatusCode
+}
+
 // ResponseError provides the HTTP centric error type wrapping the underlying
 // error with the HTTP response value.
 type ResponseError struct {
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"io"
+	"net/http"
+)
+
+// ResponseBodyWrapper wraps a response body, returning the body the response
+// will be deserialized from, (e.g. decrypting the body as it is read).
+type ResponseBodyWrapper func(body io.ReadCloser) io.ReadCloser
+
+// WithResponseBodyWrapper copies the BuildableClient and returns it with the
+// wrapper applied to the body of every response received by the client.
+// Wrappers are applied in the order they are added, with each wrapper
+// wrapping the body returned by the previous.
+//
+// Closing the wrapped body always closes the original response body, even if
+// the wrapper's Close does not. Wire observers observe the original response
+// body.
+func (b *BuildableClient) WithResponseBodyWrapper(wrapper ResponseBodyWrapper) *BuildableClient {
+	cpy := b.clone()
+	cpy.responseBodyWrappers = append(cpy.responseBodyWrappers, wrapper)
+	return cpy
+}
+
+// bodyWrappingRoundTripper wraps a RoundTripper applying the wrappers to each
+// response body.
+type bodyWrappingRoundTripper struct {
+	rt       http.RoundTripper
+	wrappers []ResponseBodyWrapper
+}
+
+func newBodyWrappingRoundTripper(rt http.RoundTripper, wrappers []ResponseBodyWrapper) http.RoundTripper {
+	if len(wrappers) == 0 {
+		return rt
+	}
+	return &bodyWrappingRoundTripper{
+		rt:       rt,
+		wrappers: wrappers,
+	}
+}
+
+func (t *bodyWrappingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil || resp == nil || resp.Body == nil {
+		return resp, err
+	}
+
+	body := resp.Body
+	for _, wrap := range t.wrappers {
+		body = wrap(body)
+	}
+	resp.Body = &wrappedBody{ReadCloser: body, original: resp.Body}
+
+	return resp, nil
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped
+// RoundTripper.
+func (t *bodyWrappingRoundTripper) CloseIdleConnections() {
+	closeIdleConnections(t.rt)
+}
+
+// wrappedBody reads from the wrapped body, closing both the wrapped and
+// original bodies when closed.
+type wrappedBody struct {
+	io.ReadCloser
+	original io.Closer
+}
+
+func (b *wrappedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if origErr := b.original.Close(); err == nil {
+		err = origErr
+	}
+	return err
+}
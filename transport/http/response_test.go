@@ -0,0 +1,60 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestNewResponseFromStdlib(t *testing.T) {
+	stdlib := &http.Response{
+		StatusCode:    404,
+		Status:        "404 Not Found",
+		Header:        http.Header{"X-Amz-Request-Id": []string{"abc123"}},
+		ContentLength: 5,
+		Body:          ioutil.NopCloser(strings.NewReader("hello")),
+	}
+
+	resp := NewResponseFromStdlib(stdlib)
+	if e, a := stdlib, resp.Response; e != a {
+		t.Errorf("expect %p response, got %p", e, a)
+	}
+	if e, a := 404, resp.StatusCode; e != a {
+		t.Errorf("expect %v status code, got %v", e, a)
+	}
+	if e, a := "abc123", resp.Header.Get("X-Amz-Request-Id"); e != a {
+		t.Errorf("expect %v request id header, got %v", e, a)
+	}
+	if e, a := int64(5), resp.ContentLength; e != a {
+		t.Errorf("expect %v content length, got %v", e, a)
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	if e, a := "hello", string(b); e != a {
+		t.Errorf("expect %q body, got %q", e, a)
+	}
+}
+
+func TestNewResponseFromStdlib_Empty(t *testing.T) {
+	resp := NewResponseFromStdlib(&http.Response{StatusCode: 204})
+
+	if resp.Header == nil {
+		t.Errorf("expect header to be set")
+	}
+	if e, a := http.NoBody, resp.Body; e != a {
+		t.Errorf("expect %v body, got %v", e, a)
+	}
+}
+
+func TestResponse_HTTPStatusCode(t *testing.T) {
+	if e, a := 503, (&Response{Response: &http.Response{StatusCode: 503}}).HTTPStatusCode(); e != a {
+		t.Errorf("expect %v status code, got %v", e, a)
+	}
+	if e, a := 0, (&Response{}).HTTPStatusCode(); e != a {
+		t.Errorf("expect %v status code, got %v", e, a)
+	}
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// spooledBodyReader is the storage of a SpooledBody's content, either in
+// memory, or in a temporary file.
+type spooledBodyReader interface {
+	io.ReadSeeker
+	io.ReaderAt
+}
+
+// SpooledBody provides a seekable body, for response bodies that need random
+// access, or request bodies that must be rewound. Bodies up to the in-memory
+// threshold are buffered in memory, larger bodies are spooled to a temporary
+// file. Closing the body removes the temporary file. The body must not be used
+// after it is closed.
+type SpooledBody struct {
+	mu     sync.Mutex
+	r      spooledBodyReader
+	file   *os.File
+	size   int64
+	closed bool
+}
+
+// NewSpooledBody reads the body into a SpooledBody and closes it. Up to
+// memThreshold bytes are buffered in memory. If the body is larger, it is
+// written to a temporary file in the directory returned by os.TempDir.
+// Returns an error if the body could not be read, or the temporary file could
+// not be written, removing any temporary file created.
+func NewSpooledBody(body io.ReadCloser, memThreshold int64) (*SpooledBody, error) {
+	defer body.Close()
+
+	var buf bytes.Buffer
+	n, err := io.CopyN(&buf, body, memThreshold+1)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read body, %w", err)
+	}
+	if n <= memThreshold {
+		return &SpooledBody{
+			r:    bytes.NewReader(buf.Bytes()),
+			size: n,
+		}, nil
+	}
+
+	file, err := ioutil.TempFile("", "smithy-body-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create body spool file, %w", err)
+	}
+
+	size, err := io.Copy(file, io.MultiReader(&buf, body))
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to spool body, %w", err)
+	}
+
+	return &SpooledBody{
+		r:    file,
+		file: file,
+		size: size,
+	}, nil
+}
+
+// Size returns the length of the body in bytes.
+func (b *SpooledBody) Size() int64 { return b.size }
+
+// Read reads from the body's content.
+func (b *SpooledBody) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return 0, errSpooledBodyClosed
+	}
+	return b.r.Read(p)
+}
+
+// Seek sets the offset of the next Read of the body, see io.Seeker.
+func (b *SpooledBody) Seek(offset int64, whence int) (int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return 0, errSpooledBodyClosed
+	}
+	return b.r.Seek(offset, whence)
+}
+
+// ReadAt reads from the body's content at the offset, see io.ReaderAt.
+func (b *SpooledBody) ReadAt(p []byte, off int64) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return 0, errSpooledBodyClosed
+	}
+	return b.r.ReadAt(p, off)
+}
+
+// Close releases the body's content, removing the temporary file if the body
+// was spooled to disk. Close is safe to call multiple times.
+func (b *SpooledBody) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	b.r = nil
+
+	if b.file == nil {
+		return nil
+	}
+	closeErr := b.file.Close()
+	if err := os.Remove(b.file.Name()); err != nil {
+		return fmt.Errorf("failed to remove body spool file, %w", err)
+	}
+	return closeErr
+}
+
+// errSpooledBodyClosed is returned by reads of a SpooledBody after it was
+// closed.
+var errSpooledBodyClosed = fmt.Errorf("read on closed spooled response body")
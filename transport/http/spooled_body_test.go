@@ -0,0 +1,126 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSpooledBody(t *testing.T) {
+	const content = "0123456789abcdefghij"
+
+	cases := map[string]struct {
+		Threshold  int64
+		ExpectFile bool
+	}{
+		"in memory": {
+			Threshold: 64,
+		},
+		"at threshold": {
+			Threshold: int64(len(content)),
+		},
+		"spooled to file": {
+			Threshold:  8,
+			ExpectFile: true,
+		},
+		"zero threshold": {
+			ExpectFile: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			source := &closeTrackingReader{Reader: strings.NewReader(content)}
+			body, err := NewSpooledBody(source, c.Threshold)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if !source.closed {
+				t.Errorf("expect source body to be closed")
+			}
+			if e, a := int64(len(content)), body.Size(); e != a {
+				t.Errorf("expect %v size, got %v", e, a)
+			}
+
+			var name string
+			if c.ExpectFile {
+				if body.file == nil {
+					t.Fatalf("expect body spooled to file")
+				}
+				name = body.file.Name()
+			} else if body.file != nil {
+				t.Fatalf("expect body in memory, got file %v", body.file.Name())
+			}
+
+			b, err := ioutil.ReadAll(body)
+			if err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := content, string(b); e != a {
+				t.Errorf("expect %v body, got %v", e, a)
+			}
+
+			if _, err := body.Seek(10, io.SeekStart); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			p := make([]byte, 4)
+			if _, err := io.ReadFull(body, p); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := "abcd", string(p); e != a {
+				t.Errorf("expect %v after seek, got %v", e, a)
+			}
+
+			if _, err := body.Seek(-2, io.SeekEnd); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if b, _ := ioutil.ReadAll(body); "ij" != string(b) {
+				t.Errorf("expect %v after seek from end, got %v", "ij", string(b))
+			}
+
+			if _, err := body.ReadAt(p, 2); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if e, a := "2345", string(p); e != a {
+				t.Errorf("expect %v read at offset, got %v", e, a)
+			}
+
+			if err := body.Close(); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if err := body.Close(); err != nil {
+				t.Errorf("expect no error closing twice, got %v", err)
+			}
+			if _, err := body.Read(p); err == nil {
+				t.Errorf("expect error reading closed body")
+			}
+
+			if c.ExpectFile {
+				if _, err := os.Stat(name); !os.IsNotExist(err) {
+					t.Errorf("expect spool file to be removed, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestSpooledBody_ReadError(t *testing.T) {
+	cases := map[string]struct {
+		Length int
+	}{
+		"in memory":       {Length: 4},
+		"spooled to file": {Length: 16},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			reader := &failingReader{n: c.Length, err: fmt.Errorf("read failed")}
+			if _, err := NewSpooledBody(ioutil.NopCloser(reader), 8); err == nil {
+				t.Fatalf("expect error, got none")
+			}
+		})
+	}
+}
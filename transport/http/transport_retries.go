@@ -0,0 +1,60 @@
+package http
+
+import (
+	"io"
+	"net/http"
+)
+
+// WithTransportRetriesDisabled copies the BuildableClient and returns it with
+// the requests it sends configured so that the HTTP transport will not
+// transparently retry them, (e.g. after a pooled connection is closed by the
+// server), giving the smithy retry middleware full control of retries.
+//
+// The request's GetBody is cleared, so the transport cannot rewind the request
+// body to resend it. Bodyless requests, which the transport would otherwise
+// resend if their method is idempotent, (e.g. GET), or they have an
+// Idempotency-Key header, are sent with an empty non-nil body. The transport
+// detects the body is empty, so no body is sent.
+func (b *BuildableClient) WithTransportRetriesDisabled() *BuildableClient {
+	cpy := b.clone()
+	cpy.transportRetriesDisabled = true
+	return cpy
+}
+
+// nonReplayableRoundTripper wraps a RoundTripper sending requests that the
+// HTTP transport considers not replayable.
+type nonReplayableRoundTripper struct {
+	rt http.RoundTripper
+}
+
+func newNonReplayableRoundTripper(rt http.RoundTripper, enabled bool) http.RoundTripper {
+	if !enabled {
+		return rt
+	}
+	return &nonReplayableRoundTripper{rt: rt}
+}
+
+func (t *nonReplayableRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := new(http.Request)
+	*r = *req
+	r.GetBody = nil
+
+	if r.Body == nil || r.Body == http.NoBody {
+		r.Body = emptyBody{}
+		r.ContentLength = 0
+	}
+
+	return t.rt.RoundTrip(r)
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped
+// RoundTripper.
+func (t *nonReplayableRoundTripper) CloseIdleConnections() {
+	closeIdleConnections(t.rt)
+}
+
+// emptyBody is a request body with no content that is not http.NoBody.
+type emptyBody struct{}
+
+func (emptyBody) Read([]byte) (int, error) { return 0, io.EOF }
+func (emptyBody) Close() error             { return nil }
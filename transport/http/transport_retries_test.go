@@ -0,0 +1,164 @@
+package http
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNonReplayableRoundTripper(t *testing.T) {
+	cases := map[string]struct {
+		Method          string
+		Header          http.Header
+		Body            []byte
+		ExpectEmptyBody bool
+	}{
+		"GET without body": {
+			Method:          "GET",
+			ExpectEmptyBody: true,
+		},
+		"TRACE without body": {
+			Method:          "TRACE",
+			ExpectEmptyBody: true,
+		},
+		"PUT with body": {
+			Method: "PUT",
+			Body:   []byte("hello"),
+		},
+		"POST without body": {
+			Method:          "POST",
+			ExpectEmptyBody: true,
+		},
+		"POST with idempotency key without body": {
+			Method:          "POST",
+			Header:          http.Header{"Idempotency-Key": []string{"abc123"}},
+			ExpectEmptyBody: true,
+		},
+		"POST with X-Idempotency-Key without body": {
+			Method:          "POST",
+			Header:          http.Header{"X-Idempotency-Key": []string{"abc123"}},
+			ExpectEmptyBody: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			req := newTransportRetryRequest(t, c.Method, "https://example.com", c.Header, c.Body)
+
+			rt := newNonReplayableRoundTripper(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				if r.GetBody != nil {
+					t.Errorf("expect GetBody to be nil")
+				}
+				if e, a := c.ExpectEmptyBody, r.Body == (emptyBody{}); e != a {
+					t.Errorf("expect empty body %v, got %v", e, a)
+				}
+				if e, a := int64(len(c.Body)), r.ContentLength; e != a {
+					t.Errorf("expect %v content length, got %v", e, a)
+				}
+				return &http.Response{StatusCode: 200}, nil
+			}), true)
+
+			if _, err := rt.RoundTrip(req); err != nil {
+				t.Fatalf("expect no error, got %v", err)
+			}
+			if c.Body != nil && req.GetBody == nil {
+				t.Errorf("expect original request GetBody not to be modified")
+			}
+		})
+	}
+}
+
+func TestBuildableClient_WithTransportRetriesDisabled(t *testing.T) {
+	cases := map[string]struct {
+		Method string
+		Header http.Header
+		Body   []byte
+	}{
+		"GET": {
+			Method: "GET",
+		},
+		"TRACE": {
+			Method: "TRACE",
+		},
+		"PUT with idempotency key": {
+			Method: "PUT",
+			Body:   []byte("hello"),
+		},
+		"POST with idempotency key without body": {
+			Method: "POST",
+			Header: http.Header{"Idempotency-Key": []string{"abc123"}},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			for _, disabled := range []bool{false, true} {
+				var closeAttempts int32
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					ioutil.ReadAll(r.Body)
+					if r.URL.Path != "/close" {
+						return
+					}
+
+					// close the pooled connection without responding.
+					atomic.AddInt32(&closeAttempts, 1)
+					conn, _, err := w.(http.Hijacker).Hijack()
+					if err != nil {
+						t.Errorf("expect no error, got %v", err)
+						return
+					}
+					conn.Close()
+				}))
+
+				client := NewBuildableClient()
+				if disabled {
+					client = client.WithTransportRetriesDisabled()
+				}
+
+				// prime a pooled connection for the next request to reuse.
+				resp, err := client.Do(newTransportRetryRequest(t, c.Method, server.URL+"/ok", c.Header, c.Body))
+				if err != nil {
+					t.Fatalf("expect no error, got %v", err)
+				}
+				ioutil.ReadAll(resp.Body)
+				resp.Body.Close()
+
+				if _, err := client.Do(newTransportRetryRequest(t, c.Method, server.URL+"/close", c.Header, c.Body)); err == nil {
+					t.Errorf("expect error, got none")
+				}
+				server.Close()
+
+				expect := int32(2)
+				if disabled {
+					expect = 1
+				}
+				if e, a := expect, atomic.LoadInt32(&closeAttempts); e != a {
+					t.Errorf("expect %v attempts with retries disabled %v, got %v", e, disabled, a)
+				}
+			}
+		})
+	}
+}
+
+func newTransportRetryRequest(t *testing.T, method, url string, header http.Header, body []byte) *http.Request {
+	t.Helper()
+
+	var req *http.Request
+	var err error
+	if body != nil {
+		req, err = http.NewRequest(method, url, bytes.NewReader(body))
+		req.Header.Set("Idempotency-Key", "abc123")
+	} else {
+		req, err = http.NewRequest(method, url, nil)
+	}
+	if err != nil {
+		t.Fatalf("expect no error, got %v", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	return req
+}
@@ -0,0 +1,244 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"sync"
+)
+
+// WireObservation is the record of a single HTTP round trip provided to a
+// WireObserver.
+//
+// The Request and Response are copies of the values sent and received by the
+// HTTP client, without their bodies. Modifying them does not affect the
+// request sent, or response returned. The request and response bodies are
+// captured up to the observer's maximum body capture size.
+type WireObservation struct {
+	Request     *http.Request
+	RequestBody []byte
+
+	// Response is nil if the round trip failed.
+	Response     *http.Response
+	ResponseBody []byte
+
+	// ResponseBodyErr is the error reading the response body, if any.
+	ResponseBodyErr error
+
+	// Err is the error returned by the round trip, if any.
+	Err error
+}
+
+// WireObserver provides the interface for observing the HTTP requests sent,
+// and responses received by a BuildableClient.
+type WireObserver interface {
+	ObserveWire(WireObservation)
+}
+
+// WireObserverFunc is a function that implements the WireObserver interface.
+type WireObserverFunc func(WireObservation)
+
+// ObserveWire invokes the wrapped function.
+func (fn WireObserverFunc) ObserveWire(o WireObservation) {
+	fn(o)
+}
+
+type wireObserverEntry struct {
+	observer       WireObserver
+	maxBodyCapture int64
+}
+
+// WithWireObserver copies the BuildableClient and returns it with the
+// observer registered to receive every request sent, and response received
+// by the client. Up to maxBodyCapture bytes of the request and response bodies
+// are captured for the observer.
+//
+// The response body is captured as it is read by the caller. The observer is
+// notified once the response body is read to EOF, fails to be read, or is
+// closed, or when the round trip completes if the response has no body. The
+// request body capture contains the bytes sent by the time the observer is
+// notified.
+func (b *BuildableClient) WithWireObserver(observer WireObserver, maxBodyCapture int64) *BuildableClient {
+	cpy := b.clone()
+	cpy.wireObservers = append(cpy.wireObservers, wireObserverEntry{
+		observer:       observer,
+		maxBodyCapture: maxBodyCapture,
+	})
+	return cpy
+}
+
+// observingRoundTripper wraps a RoundTripper notifying the registered wire
+// observers of each round trip.
+type observingRoundTripper struct {
+	rt        http.RoundTripper
+	observers []wireObserverEntry
+	maxBody   int64
+}
+
+func newObservingRoundTripper(rt http.RoundTripper, observers []wireObserverEntry) http.RoundTripper {
+	if len(observers) == 0 {
+		return rt
+	}
+
+	var maxBody int64
+	for _, o := range observers {
+		if o.maxBodyCapture > maxBody {
+			maxBody = o.maxBodyCapture
+		}
+	}
+
+	return &observingRoundTripper{
+		rt:        rt,
+		observers: observers,
+		maxBody:   maxBody,
+	}
+}
+
+func (t *observingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqCapture *captureReadCloser
+	sendReq := req
+	if req.Body != nil && req.Body != http.NoBody {
+		reqCapture = &captureReadCloser{ReadCloser: req.Body, max: t.maxBody}
+		sendReq = req.Clone(req.Context())
+		sendReq.Body = reqCapture
+	}
+
+	resp, err := t.rt.RoundTrip(sendReq)
+
+	observation := WireObservation{
+		Request: req.Clone(req.Context()),
+		Err:     err,
+	}
+	observation.Request.Body = http.NoBody
+
+	if resp != nil {
+		respCopy := *resp
+		respCopy.Header = resp.Header.Clone()
+		respCopy.Trailer = resp.Trailer.Clone()
+		respCopy.Body = http.NoBody
+		respCopy.Request = observation.Request
+		observation.Response = &respCopy
+	}
+
+	if resp == nil || resp.Body == nil || resp.Body == http.NoBody {
+		t.observe(observation, reqCapture)
+		return resp, err
+	}
+
+	// The observers are notified once the response body has been read to
+	// EOF, or closed, so that streamed responses are not delayed.
+	body := &observingBody{
+		capture: captureReadCloser{ReadCloser: resp.Body, max: t.maxBody},
+		done: func(capture *captureReadCloser, readErr error) {
+			v := observation
+			v.ResponseBody = capture.Bytes()
+			v.ResponseBodyErr = readErr
+			t.observe(v, reqCapture)
+		},
+	}
+	if w, ok := resp.Body.(io.Writer); ok {
+		resp.Body = &observingReadWriteBody{observingBody: body, w: w}
+	} else {
+		resp.Body = body
+	}
+
+	return resp, err
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped
+// RoundTripper.
+func (t *observingRoundTripper) CloseIdleConnections() {
+	closeIdleConnections(t.rt)
+}
+
+// observe notifies the observers of the round trip, truncating the captured
+// bodies to each observer's maximum.
+func (t *observingRoundTripper) observe(observation WireObservation, reqCapture *captureReadCloser) {
+	if reqCapture != nil {
+		observation.RequestBody = reqCapture.Bytes()
+	}
+
+	for _, o := range t.observers {
+		v := observation
+		v.RequestBody = truncateBytes(v.RequestBody, o.maxBodyCapture)
+		v.ResponseBody = truncateBytes(v.ResponseBody, o.maxBodyCapture)
+		o.observer.ObserveWire(v)
+	}
+}
+
+func truncateBytes(b []byte, max int64) []byte {
+	if int64(len(b)) > max {
+		return b[:max]
+	}
+	return b
+}
+
+// captureReadCloser captures up to max bytes read from the underlying
+// ReadCloser. Safe for concurrent use, since the HTTP transport may read the
+// request body in a separate goroutine.
+type captureReadCloser struct {
+	io.ReadCloser
+	max int64
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (c *captureReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+
+	c.mu.Lock()
+	if remain := c.max - int64(len(c.buf)); remain > 0 && n > 0 {
+		c.buf = append(c.buf, truncateBytes(p[:n], remain)...)
+	}
+	c.mu.Unlock()
+
+	return n, err
+}
+
+func (c *captureReadCloser) Bytes() []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]byte(nil), c.buf...)
+}
+
+// observingBody captures the bytes of the response body as they are read,
+// calling done once, when the body is read to EOF, fails to be read, or is
+// closed.
+type observingBody struct {
+	capture captureReadCloser
+	once    sync.Once
+	done    func(capture *captureReadCloser, readErr error)
+}
+
+func (b *observingBody) Read(p []byte) (int, error) {
+	n, err := b.capture.Read(p)
+	if err == io.EOF {
+		b.finish(nil)
+	} else if err != nil {
+		b.finish(err)
+	}
+	return n, err
+}
+
+func (b *observingBody) Close() error {
+	err := b.capture.Close()
+	b.finish(nil)
+	return err
+}
+
+func (b *observingBody) finish(readErr error) {
+	b.once.Do(func() { b.done(&b.capture, readErr) })
+}
+
+// observingReadWriteBody is an observingBody that retains the io.Writer of
+// the response body, (e.g. the connection of a 101 Switching Protocols
+// response).
+type observingReadWriteBody struct {
+	*observingBody
+	w io.Writer
+}
+
+func (b *observingReadWriteBody) Write(p []byte) (int, error) {
+	return b.w.Write(p)
+}
@@ -76,9 +76,107 @@ type InvalidParamError interface {
 	AddNestedContext(string)
 }
 
+// GetParamPath returns the structured path of the field the invalid parameter
+// error occurred on, including the base and nested contexts. Errors that
+// provide their path with a Path method, (e.g. ParamRequiredError), return
+// it, otherwise the path is parsed from the error's Field.
+func GetParamPath(err InvalidParamError) ParamPath {
+	if v, ok := err.(interface{ Path() ParamPath }); ok {
+		return v.Path()
+	}
+	return parseParamPath(err.Field())
+}
+
+// ParamPathSegment is a single element of an invalid parameter's path. A
+// segment is either a member name, or an index into a list or map.
+type ParamPathSegment struct {
+	// Name is the member name of the segment. Empty for index segments.
+	Name string
+
+	// Index is the list index, or map key of the segment. Only set if IsIndex
+	// is true.
+	Index string
+
+	// IsIndex is true if the segment is an index into a list or map.
+	IsIndex bool
+}
+
+// ParamPath provides the structured path to an invalid parameter.
+type ParamPath []ParamPathSegment
+
+// String returns the path formatted with '.' separated member names, and
+// bracketed indexes, (e.g. Foo.Bar[3].Baz).
+func (p ParamPath) String() string {
+	sb := &strings.Builder{}
+	for i, seg := range p {
+		if seg.IsIndex {
+			sb.WriteRune('[')
+			sb.WriteString(seg.Index)
+			sb.WriteRune(']')
+			continue
+		}
+		if i > 0 {
+			sb.WriteRune('.')
+		}
+		sb.WriteString(seg.Name)
+	}
+	return sb.String()
+}
+
+// parseParamPath splits a formatted path string, (e.g. Foo[3].Bar) into its
+// segments. A quoted map key index, (e.g. Foo["a]b"]), may contain any
+// character.
+func parseParamPath(v string) ParamPath {
+	var path ParamPath
+	for len(v) > 0 {
+		switch v[0] {
+		case '.':
+			v = v[1:]
+		case '[':
+			end := indexParamPathIndexEnd(v)
+			if end < 0 {
+				end = len(v)
+				v += "]"
+			}
+			path = append(path, ParamPathSegment{Index: v[1:end], IsIndex: true})
+			v = v[end+1:]
+		default:
+			end := strings.IndexAny(v, ".[")
+			if end < 0 {
+				end = len(v)
+			}
+			path = append(path, ParamPathSegment{Name: v[:end]})
+			v = v[end:]
+		}
+	}
+	return path
+}
+
+// indexParamPathIndexEnd returns the position of the ']' closing the index at
+// the start of v, skipping over a quoted map key. Returns -1 if the index is
+// not closed.
+func indexParamPathIndexEnd(v string) int {
+	i := 1
+	if i < len(v) && v[i] == '"' {
+		for i++; i < len(v) && v[i] != '"'; i++ {
+			if v[i] == '\\' {
+				i++
+			}
+		}
+		i++
+	}
+	if i > len(v) {
+		return -1
+	}
+	if end := strings.IndexByte(v[i:], ']'); end >= 0 {
+		return i + end
+	}
+	return -1
+}
+
 type invalidParamError struct {
-	context       string
-	nestedContext string
+	context       ParamPath
+	nestedContext ParamPath
 	field         string
 	reason        string
 }
@@ -90,38 +188,30 @@ func (e invalidParamError) Error() string {
 
 // Field Returns the field and context the error occurred.
 func (e invalidParamError) Field() string {
-	sb := &strings.Builder{}
-	sb.WriteString(e.context)
-	if sb.Len() > 0 {
-		if len(e.nestedContext) == 0 || (len(e.nestedContext) > 0 && e.nestedContext[:1] != "[") {
-			sb.WriteRune('.')
-		}
-	}
-	if len(e.nestedContext) > 0 {
-		sb.WriteString(e.nestedContext)
-		sb.WriteRune('.')
+	return e.Path().String()
+}
+
+// Path returns the structured path of the field, including the base and
+// nested contexts the error occurred within.
+func (e invalidParamError) Path() ParamPath {
+	path := make(ParamPath, 0, len(e.context)+len(e.nestedContext)+1)
+	path = append(path, e.context...)
+	path = append(path, e.nestedContext...)
+	if len(e.field) > 0 {
+		path = append(path, ParamPathSegment{Name: e.field})
 	}
-	sb.WriteString(e.field)
-	return sb.String()
+	return path
 }
 
 // SetContext updates the base context of the error.
 func (e *invalidParamError) SetContext(ctx string) {
-	e.context = ctx
+	e.context = parseParamPath(ctx)
 }
 
 // AddNestedContext prepends a context to the field's path.
 func (e *invalidParamError) AddNestedContext(ctx string) {
-	if len(e.nestedContext) == 0 {
-		e.nestedContext = ctx
-		return
-	}
-	// Check if our nested context is an index into a slice or map
-	if e.nestedContext[:1] != "[" {
-		e.nestedContext = fmt.Sprintf("%s.%s", ctx, e.nestedContext)
-		return
-	}
-	e.nestedContext = ctx + e.nestedContext
+	nested := parseParamPath(ctx)
+	e.nestedContext = append(nested, e.nestedContext...)
 }
 
 // An ParamRequiredError represents an required parameter error.
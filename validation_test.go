@@ -0,0 +1,137 @@
+package smithy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInvalidParamsError_NestedPath(t *testing.T) {
+	inner := InvalidParamsError{Context: "Baz"}
+	inner.Add(NewErrParamRequired("Qux"))
+
+	list := InvalidParamsError{Context: "Bar"}
+	list.AddNested("[3]", inner)
+
+	outer := InvalidParamsError{Context: "OperationInput"}
+	outer.AddNested("Foo.Bar", list)
+
+	if e, a := 1, outer.Len(); e != a {
+		t.Fatalf("expect %v errors, got %v", e, a)
+	}
+
+	err := outer.Errs()[0].(InvalidParamError)
+
+	if e, a := "OperationInput.Foo.Bar[3].Qux", err.Field(); e != a {
+		t.Errorf("expect %v field, got %v", e, a)
+	}
+
+	expectPath := ParamPath{
+		{Name: "OperationInput"},
+		{Name: "Foo"},
+		{Name: "Bar"},
+		{Index: "3", IsIndex: true},
+		{Name: "Qux"},
+	}
+	if e, a := expectPath, GetParamPath(err); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v path, got %v", e, a)
+	}
+
+	if e, a := "missing required field, OperationInput.Foo.Bar[3].Qux.", err.Error(); e != a {
+		t.Errorf("expect %q error, got %q", e, a)
+	}
+}
+
+func TestInvalidParamError_Field(t *testing.T) {
+	cases := map[string]struct {
+		Context string
+		Nested  []string
+		Expect  string
+	}{
+		"no context": {
+			Expect: "Field",
+		},
+		"base context": {
+			Context: "Input",
+			Expect:  "Input.Field",
+		},
+		"nested member": {
+			Context: "Input",
+			Nested:  []string{"Bar", "Foo"},
+			Expect:  "Input.Foo.Bar.Field",
+		},
+		"nested index": {
+			Context: "Input",
+			Nested:  []string{"[2]", "Foo"},
+			Expect:  "Input.Foo[2].Field",
+		},
+		"nested map key": {
+			Nested: []string{"[key]", "Map"},
+			Expect: "Map[key].Field",
+		},
+		"nested quoted map key with bracket": {
+			Nested: []string{`["a]b"]`, "Map"},
+			Expect: `Map["a]b"].Field`,
+		},
+		"nested quoted map key with escaped quote": {
+			Nested: []string{`["a\"]b"]`, "Map"},
+			Expect: `Map["a\"]b"].Field`,
+		},
+		"leading index": {
+			Nested: []string{"[0]"},
+			Expect: "[0].Field",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := NewErrParamRequired("Field")
+			err.SetContext(c.Context)
+			for _, n := range c.Nested {
+				err.AddNestedContext(n)
+			}
+
+			if e, a := c.Expect, err.Field(); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+			if e, a := c.Expect, err.Path().String(); e != a {
+				t.Errorf("expect %v, got %v", e, a)
+			}
+		})
+	}
+}
+
+func TestParseParamPath_QuotedMapKey(t *testing.T) {
+	expect := ParamPath{
+		{Name: "Map"},
+		{Index: `"a]b"`, IsIndex: true},
+		{Name: "Field"},
+	}
+	if e, a := expect, parseParamPath(`Map["a]b"].Field`); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v path, got %v", e, a)
+	}
+}
+
+type mockInvalidParamError struct {
+	field string
+}
+
+func (e *mockInvalidParamError) Error() string           { return "invalid " + e.field }
+func (e *mockInvalidParamError) Field() string           { return e.field }
+func (e *mockInvalidParamError) SetContext(string)       {}
+func (e *mockInvalidParamError) AddNestedContext(string) {}
+
+func TestGetParamPath_WithoutPath(t *testing.T) {
+	var params InvalidParamsError
+	params.Add(&mockInvalidParamError{field: "Foo[2].Bar"})
+
+	err := params.Errs()[0].(InvalidParamError)
+
+	expect := ParamPath{
+		{Name: "Foo"},
+		{Index: "2", IsIndex: true},
+		{Name: "Bar"},
+	}
+	if e, a := expect, GetParamPath(err); !reflect.DeepEqual(e, a) {
+		t.Errorf("expect %v path, got %v", e, a)
+	}
+}